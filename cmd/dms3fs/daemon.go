@@ -8,7 +8,9 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 
     utilmain "github.com/dms3-fs/go-dms3-fs/cmd/dms3fs/util"
@@ -48,6 +50,7 @@ const (
 	unencryptTransportKwd     = "disable-transport-encryption"
 	unrestrictedApiAccessKwd  = "unrestricted-api"
 	writableKwd               = "writable"
+	gatewayHostnamesKwd       = "gateway-hostnames"
 	enableFloodSubKwd         = "enable-pubsub-experiment"
 	enableDMS3NSPubSubKwd       = "enable-namesys-pubsub"
 	enableMultiplexKwd        = "enable-mplex-experiment"
@@ -153,6 +156,7 @@ Headers.
 		cmdkit.StringOption(routingOptionKwd, "Overrides the routing option").WithDefault(routingOptionDefaultKwd),
 		cmdkit.BoolOption(mountKwd, "Mounts DMS3FS to the filesystem"),
 		cmdkit.BoolOption(writableKwd, "Enable writing objects (with POST, PUT and DELETE)"),
+		cmdkit.StringOption(gatewayHostnamesKwd, "Comma separated hostnames to serve subdomain origins (<cid>.dms3fs.<hostname>) below"),
 		cmdkit.StringOption(dms3fsMountKwd, "Path to the mountpoint for DMS3FS (if using --mount). Defaults to config setting."),
 		cmdkit.StringOption(dms3nsMountKwd, "Path to the mountpoint for DMS3NS (if using --mount). Defaults to config setting."),
 		cmdkit.BoolOption(unrestrictedApiAccessKwd, "Allow API access to unlisted hashes"),
@@ -386,6 +390,9 @@ func daemonFunc(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment
 		return
 	}
 
+	// unpin expired pins
+	expiryErrc := runPinExpiry(req, node)
+
 	// construct http gateway - if it is set in the config
 	var gwErrc <-chan error
 	if len(cfg.Addresses.Gateway) > 0 {
@@ -403,7 +410,7 @@ func daemonFunc(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment
 	fmt.Printf("Daemon is ready\n")
 	// collect long-running errors and block for shutdown
 	// TODO(cryptix): our fuse currently doesnt follow this pattern for graceful shutdown
-	for err := range merge(apiErrc, gwErrc, gcErrc) {
+	for err := range merge(apiErrc, gwErrc, gcErrc, expiryErrc) {
 		if err != nil {
 			log.Error(err)
 			re.SetError(err, cmdkit.ErrNormal)
@@ -534,20 +541,47 @@ func serveHTTPGateway(req *cmds.Request, cctx *oldcmds.Context) (<-chan error, e
 	// we might have listened to /tcp/0 - lets see what we are listing on
 	gatewayMaddr = gwLis.Multiaddr()
 
+	gatewayOpt := corehttp.GatewayOption(false, "/dms3fs", "/dms3ns")
 	if writable {
-		fmt.Printf("Gateway (writable) server listening on %s\n", gatewayMaddr)
+		// the writes may be restricted by an ACL in the repo
+		acl, err := corehttp.LoadGatewayACL(filepath.Join(cctx.ConfigRoot, corehttp.GatewayACLFile))
+		switch {
+		case err == nil:
+			fmt.Printf("Gateway (writable, %d ACL rules) server listening on %s\n", len(acl.Rules), gatewayMaddr)
+		case os.IsNotExist(err):
+			fmt.Printf("Gateway (writable) server listening on %s\n", gatewayMaddr)
+		default:
+			return nil, fmt.Errorf("serveHTTPGateway: %s", err)
+		}
+		gatewayOpt = corehttp.WritableGatewayOption(acl, "/dms3fs", "/dms3ns")
 	} else {
 		fmt.Printf("Gateway (readonly) server listening on %s\n", gatewayMaddr)
 	}
 
 	var opts = []corehttp.ServeOption{
 		corehttp.MetricsCollectionOption("gateway"),
+	}
+
+	// the requests of each client may be limited by rate limits in the repo
+	limits, err := corehttp.LoadRateLimitConfig(filepath.Join(cctx.ConfigRoot, corehttp.GatewayRateLimitFile))
+	switch {
+	case err == nil:
+		opts = append(opts, corehttp.RateLimitOption(*limits))
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("serveHTTPGateway: %s", err)
+	}
+
+	opts = append(opts,
 		corehttp.CheckVersionOption(),
 		corehttp.CommandsROOption(*cctx),
 		corehttp.VersionOption(),
-		corehttp.DMS3NSHostnameOption(),
-		corehttp.GatewayOption(writable, "/dms3fs", "/dms3ns"),
+	)
+
+	// content below the gateway hostnames gets an origin of its own
+	if hostnames, _ := req.Options[gatewayHostnamesKwd].(string); hostnames != "" {
+		opts = append(opts, corehttp.SubdomainGatewayOption(strings.Split(hostnames, ",")...))
 	}
+	opts = append(opts, corehttp.DMS3NSHostnameOption(), gatewayOpt)
 
 	if len(cfg.Gateway.RootRedirect) > 0 {
 		opts = append(opts, corehttp.RedirectOption("", cfg.Gateway.RootRedirect))
@@ -611,6 +645,16 @@ func maybeRunGC(req *cmds.Request, node *core.Dms3FsNode) (<-chan error, error)
 	return errc, nil
 }
 
+// runPinExpiry periodically unpins the pins which expired
+func runPinExpiry(req *cmds.Request, node *core.Dms3FsNode) <-chan error {
+	errc := make(chan error)
+	go func() {
+		errc <- corerepo.PeriodicUnpinExpired(req.Context, node)
+		close(errc)
+	}()
+	return errc
+}
+
 // merge does fan-in of multiple read-only error channels
 // taken from http://blog.golang.org/pipelines
 func merge(cs ...<-chan error) <-chan error {
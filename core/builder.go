@@ -12,6 +12,7 @@ import (
 	bserv "github.com/dms3-fs/go-blockservice"
 	filestore "github.com/dms3-fs/go-dms3-fs/filestore"
 	pin "github.com/dms3-fs/go-dms3-fs/pin"
+	gc "github.com/dms3-fs/go-dms3-fs/pin/gc"
 	repo "github.com/dms3-fs/go-dms3-fs/repo"
 	cidv0v1 "github.com/dms3-fs/go-dms3-fs/thirdparty/cidv0v1"
 	"github.com/dms3-fs/go-dms3-fs/thirdparty/verifbs"
@@ -225,6 +226,9 @@ func setupNode(ctx context.Context, n *Dms3FsNode, cfg *BuildCfg) error {
 		n.Blockstore = &verifbs.VerifBSGC{GCBlockstore: n.Blockstore}
 	}
 
+	// let the garbage collection sweep the blockstore while blocks are added
+	n.Blockstore = gc.NewBarrierBlockstore(n.Blockstore)
+
 	rcfg, err := n.Repo.Config()
 	if err != nil {
 		return err
@@ -253,7 +257,7 @@ func setupNode(ctx context.Context, n *Dms3FsNode, cfg *BuildCfg) error {
 		n.Exchange = offline.Exchange(n.Blockstore)
 	}
 
-	n.Blocks = bserv.New(n.Blockstore, n.Exchange)
+	n.Blocks = bserv.New(n.Blockstore, &tracedExchange{n.Exchange})
 	n.DAG = dag.NewDAGService(n.Blocks)
 
 	internalDag := dag.NewDAGService(bserv.New(n.Blockstore, offline.Exchange(n.Blockstore)))
@@ -265,6 +269,9 @@ func setupNode(ctx context.Context, n *Dms3FsNode, cfg *BuildCfg) error {
 		// this is kinda sketchy and could cause data loss
 		n.Pinning = pin.NewPinner(n.Repo.Datastore(), n.DAG, internalDag)
 	}
+	// keep the reachability index of the garbage collection up to date, the
+	// walks of the pins are cancelled with the context of the node
+	n.Pinning = gc.NewIndexedPinner(ctx, n.Pinning, gc.NewIndex(n.Repo.Datastore()), internalDag)
 	n.Resolver = resolver.NewBasicResolver(n.DAG)
 
 	if cfg.Online {
@@ -96,6 +96,7 @@ func TestCommands(t *testing.T) {
 		"/config/profile/apply",
 		"/dag",
 		"/dag/get",
+		"/dag/merge",
 		"/dag/put",
 		"/dag/resolve",
 		"/dht",
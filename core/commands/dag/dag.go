@@ -9,7 +9,9 @@ import (
 
 	cmds "github.com/dms3-fs/go-dms3-fs/commands"
 	e "github.com/dms3-fs/go-dms3-fs/core/commands/e"
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
 	coredag "github.com/dms3-fs/go-dms3-fs/core/coredag"
+	"github.com/dms3-fs/go-dms3-fs/dagutils"
 	pin "github.com/dms3-fs/go-dms3-fs/pin"
 	path "github.com/dms3-fs/go-path"
 
@@ -34,6 +36,7 @@ to deprecate and replace the existing 'dms3fs object' command moving forward.
 		"put":     DagPutCmd,
 		"get":     DagGetCmd,
 		"resolve": DagResolveCmd,
+		"merge":   DagMergeCmd,
 	},
 }
 
@@ -289,6 +292,111 @@ var DagResolveCmd = &cmds.Command{
 	Type: ResolveOutput{},
 }
 
+// MergeOutput is the output type of 'dag merge' command. Either Cid or
+// Conflicts is set
+type MergeOutput struct {
+	Cid       *cid.Cid        `json:",omitempty"`
+	Conflicts []MergeConflict `json:",omitempty"`
+}
+
+// MergeConflict holds two conflicting changes of a 'dag merge'
+type MergeConflict struct {
+	A *dagutils.Change
+	B *dagutils.Change
+}
+
+// DagMergeCmd merges the changes made to two copies of a dag
+var DagMergeCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Three-way merge of dms3ld dags.",
+		ShortDescription: `
+'dms3fs dag merge' applies the changes from <base> to <a> and from <base> to
+<b> onto <base>, and prints the address of the merged dag. If the changes
+conflict, no dag is created and the conflicting changes are printed instead.
+Only protobuf nodes can be merged.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("base", true, false, "The common ancestor of the dags to merge."),
+		cmdkit.StringArg("a", true, false, "The first dag to merge."),
+		cmdkit.StringArg("b", true, false, "The second dag to merge."),
+	},
+	Run: func(req cmds.Request, res cmds.Response) {
+		api, err := req.InvocContext().GetApi()
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
+
+		paths := make([]coreiface.Path, len(req.Arguments()))
+		for i, arg := range req.Arguments() {
+			paths[i], err = coreiface.ParsePath(arg)
+			if err != nil {
+				res.SetError(err, cmdkit.ErrNormal)
+				return
+			}
+		}
+
+		merged, conflicts, err := api.Dag().Merge(req.Context(), paths[0], paths[1], paths[2])
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
+
+		if len(conflicts) > 0 {
+			out := &MergeOutput{Conflicts: make([]MergeConflict, len(conflicts))}
+			for i, c := range conflicts {
+				out.Conflicts[i] = MergeConflict{A: dagChange(c.A), B: dagChange(c.B)}
+			}
+			res.SetOutput(out)
+			return
+		}
+
+		res.SetOutput(&MergeOutput{Cid: merged.Cid()})
+	},
+	Marshalers: cmds.MarshalerMap{
+		cmds.Text: func(res cmds.Response) (io.Reader, error) {
+			v, err := unwrapOutput(res.Output())
+			if err != nil {
+				return nil, err
+			}
+
+			output, ok := v.(*MergeOutput)
+			if !ok {
+				return nil, e.TypeErr(output, v)
+			}
+
+			buf := new(bytes.Buffer)
+			if output.Cid != nil {
+				fmt.Fprintln(buf, output.Cid)
+			}
+			for _, c := range output.Conflicts {
+				fmt.Fprintf(buf, "conflict: %s; %s\n", c.A, c.B)
+			}
+
+			return buf, nil
+		},
+	},
+	Type: MergeOutput{},
+}
+
+func dagChange(c coreiface.ObjectChange) *dagutils.Change {
+	out := &dagutils.Change{
+		Type: c.Type,
+		Path: c.Path,
+	}
+
+	if c.Before != nil {
+		out.Before = c.Before.Cid()
+	}
+
+	if c.After != nil {
+		out.After = c.After.Cid()
+	}
+
+	return out
+}
+
 // copy+pasted from ../commands.go
 func unwrapOutput(i interface{}) (interface{}, error) {
 	var (
@@ -10,6 +10,8 @@ import (
 
 	cmds "github.com/dms3-fs/go-dms3-fs/commands"
 	e "github.com/dms3-fs/go-dms3-fs/core/commands/e"
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
 	dag "github.com/dms3-fs/go-merkledag"
 	path "github.com/dms3-fs/go-path"
 
@@ -153,26 +155,17 @@ var findProvidersDhtCmd = &cmds.Command{
 		cmdkit.IntOption("num-providers", "n", "The number of providers to find.").WithDefault(20),
 	},
 	Run: func(req cmds.Request, res cmds.Response) {
-		n, err := req.InvocContext().GetNode()
+		api, err := req.InvocContext().GetApi()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
 
-		if n.Routing == nil {
-			res.SetError(ErrNotOnline, cmdkit.ErrNormal)
-			return
-		}
-
 		numProviders, _, err := res.Request().Option("num-providers").Int()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
-		if numProviders < 1 {
-			res.SetError(fmt.Errorf("number of providers must be greater than 0"), cmdkit.ErrNormal)
-			return
-		}
 
 		events := make(chan *notif.QueryEvent)
 		ctx := notif.RegisterForQueryEvents(req.Context(), events)
@@ -183,10 +176,15 @@ var findProvidersDhtCmd = &cmds.Command{
 			return
 		}
 
+		pchan, err := api.Dht().FindProviders(ctx, coreiface.Dms3LdPath(c), options.Dht.NumProviders(numProviders))
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
+
 		outChan := make(chan interface{})
 		res.SetOutput((<-chan interface{})(outChan))
 
-		pchan := n.Routing.FindProvidersAsync(ctx, c, numProviders)
 		go func() {
 			defer close(outChan)
 			for e := range events {
@@ -417,6 +415,13 @@ var findPeerDhtCmd = &cmds.Command{
 		cmdkit.BoolOption("verbose", "v", "Print extra information."),
 	},
 	Run: func(req cmds.Request, res cmds.Response) {
+		api, err := req.InvocContext().GetApi()
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
+
+		// fail the command rather than report the error as a query event
 		n, err := req.InvocContext().GetNode()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
@@ -453,7 +458,7 @@ var findPeerDhtCmd = &cmds.Command{
 
 		go func() {
 			defer close(events)
-			pi, err := n.Routing.FindPeer(ctx, pid)
+			pi, err := api.Dht().FindPeer(ctx, pid)
 			if err != nil {
 				notif.PublishQueryEvent(ctx, &notif.QueryEvent{
 					Type:  notif.QueryError,
@@ -522,6 +527,13 @@ Different key types can specify other 'best' rules.
 		cmdkit.BoolOption("verbose", "v", "Print extra information."),
 	},
 	Run: func(req cmds.Request, res cmds.Response) {
+		api, err := req.InvocContext().GetApi()
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
+
+		// fail the command rather than report the error as a query event
 		n, err := req.InvocContext().GetNode()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
@@ -557,7 +569,7 @@ Different key types can specify other 'best' rules.
 
 		go func() {
 			defer close(events)
-			val, err := n.Routing.GetValue(ctx, dhtkey)
+			val, err := api.Dht().GetValue(ctx, dhtkey)
 			if err != nil {
 				notif.PublishQueryEvent(ctx, &notif.QueryEvent{
 					Type:  notif.QueryError,
@@ -636,6 +648,13 @@ NOTE: A value may not exceed 2048 bytes.
 		cmdkit.BoolOption("verbose", "v", "Print extra information."),
 	},
 	Run: func(req cmds.Request, res cmds.Response) {
+		api, err := req.InvocContext().GetApi()
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
+
+		// fail the command rather than report the error as a query event
 		n, err := req.InvocContext().GetNode()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
@@ -674,7 +693,7 @@ NOTE: A value may not exceed 2048 bytes.
 
 		go func() {
 			defer close(events)
-			err := n.Routing.PutValue(ctx, key, []byte(data))
+			err := api.Dht().PutValue(ctx, key, []byte(data))
 			if err != nil {
 				notif.PublishQueryEvent(ctx, &notif.QueryEvent{
 					Type:  notif.QueryError,
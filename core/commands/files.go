@@ -2,40 +2,25 @@ package commands
 
 import (
 	"bytes"
-	"context"
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	gopath "path"
 	"sort"
 	"strings"
 
-	bservice "github.com/dms3-fs/go-blockservice"
 	oldcmds "github.com/dms3-fs/go-dms3-fs/commands"
 	lgc "github.com/dms3-fs/go-dms3-fs/commands/legacy"
-	core "github.com/dms3-fs/go-dms3-fs/core"
 	cmdenv "github.com/dms3-fs/go-dms3-fs/core/commands/cmdenv"
 	e "github.com/dms3-fs/go-dms3-fs/core/commands/e"
-	dag "github.com/dms3-fs/go-merkledag"
-	path "github.com/dms3-fs/go-path"
-	resolver "github.com/dms3-fs/go-path/resolver"
-	ft "github.com/dms3-fs/go-unixfs"
-	uio "github.com/dms3-fs/go-unixfs/io"
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
 
-	humanize "github.com/dustin/go-humanize"
-	cid "github.com/dms3-fs/go-cid"
 	cmdkit "github.com/dms3-fs/go-fs-cmdkit"
 	cmds "github.com/dms3-fs/go-fs-cmds"
-	offline "github.com/dms3-fs/go-fs-exchange-offline"
-	dms3ld "github.com/dms3-fs/go-ld-format"
-	logging "github.com/dms3-fs/go-log"
 	mfs "github.com/dms3-fs/go-mfs"
 	mh "github.com/dms3-mft/go-multihash"
+	humanize "github.com/dustin/go-humanize"
 )
 
-var flog = logging.Logger("cmds/files")
-
 // FilesCmd is the 'dms3fs files' command
 var FilesCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
@@ -115,13 +100,7 @@ var filesStatCmd = &cmds.Command{
 			res.SetError(err, cmdkit.ErrClient)
 		}
 
-		node, err := cmdenv.GetNode(env)
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-
-		path, err := checkPath(req.Arguments[0])
+		api, err := cmdenv.GetApi(env)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
@@ -129,41 +108,22 @@ var filesStatCmd = &cmds.Command{
 
 		withLocal, _ := req.Options["with-local"].(bool)
 
-		var dagserv dms3ld.DAGService
-		if withLocal {
-			// an offline DAGService will not fetch from the network
-			dagserv = dag.NewDAGService(bservice.New(
-				node.Blockstore,
-				offline.Exchange(node.Blockstore),
-			))
-		} else {
-			dagserv = node.DAG
-		}
-
-		nd, err := getNodeFromPath(req.Context, node, dagserv, path)
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-
-		o, err := statNode(nd)
+		st, err := api.Files().Stat(req.Context, req.Arguments[0], options.Files.Stat.WithLocal(withLocal))
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
 
-		if !withLocal {
-			cmds.EmitOnce(res, o)
-			return
-		}
-
-		local, sizeLocal, err := walkBlock(req.Context, dagserv, nd)
-
-		o.WithLocality = true
-		o.Local = local
-		o.SizeLocal = sizeLocal
-
-		cmds.EmitOnce(res, o)
+		cmds.EmitOnce(res, &statOutput{
+			Hash:           st.Cid.String(),
+			Size:           st.Size,
+			CumulativeSize: st.CumulativeSize,
+			Blocks:         st.Blocks,
+			Type:           st.Type,
+			WithLocality:   st.WithLocality,
+			Local:          st.Local,
+			SizeLocal:      st.SizeLocal,
+		})
 	},
 	Encoders: cmds.EncoderMap{
 		cmds.Text: cmds.MakeEncoder(func(req *cmds.Request, w io.Writer, v interface{}) error {
@@ -218,83 +178,6 @@ func statGetFormatOptions(req *cmds.Request) (string, error) {
 	}
 }
 
-func statNode(nd dms3ld.Node) (*statOutput, error) {
-	c := nd.Cid()
-
-	cumulsize, err := nd.Size()
-	if err != nil {
-		return nil, err
-	}
-
-	switch n := nd.(type) {
-	case *dag.ProtoNode:
-		d, err := ft.FromBytes(n.Data())
-		if err != nil {
-			return nil, err
-		}
-
-		var ndtype string
-		switch d.GetType() {
-		case ft.TDirectory, ft.THAMTShard:
-			ndtype = "directory"
-		case ft.TFile, ft.TMetadata, ft.TRaw:
-			ndtype = "file"
-		default:
-			return nil, fmt.Errorf("unrecognized node type: %s", d.GetType())
-		}
-
-		return &statOutput{
-			Hash:           c.String(),
-			Blocks:         len(nd.Links()),
-			Size:           d.GetFilesize(),
-			CumulativeSize: cumulsize,
-			Type:           ndtype,
-		}, nil
-	case *dag.RawNode:
-		return &statOutput{
-			Hash:           c.String(),
-			Blocks:         0,
-			Size:           cumulsize,
-			CumulativeSize: cumulsize,
-			Type:           "file",
-		}, nil
-	default:
-		return nil, fmt.Errorf("not unixfs node (proto or raw)")
-	}
-}
-
-func walkBlock(ctx context.Context, dagserv dms3ld.DAGService, nd dms3ld.Node) (bool, uint64, error) {
-	// Start with the block data size
-	sizeLocal := uint64(len(nd.RawData()))
-
-	local := true
-
-	for _, link := range nd.Links() {
-		child, err := dagserv.Get(ctx, link.Cid)
-
-		if err == dms3ld.ErrNotFound {
-			local = false
-			continue
-		}
-
-		if err != nil {
-			return local, sizeLocal, err
-		}
-
-		childLocal, childLocalSize, err := walkBlock(ctx, dagserv, child)
-
-		if err != nil {
-			return local, sizeLocal, err
-		}
-
-		// Recursively add the child size
-		local = local && childLocal
-		sizeLocal += childLocalSize
-	}
-
-	return local, sizeLocal, nil
-}
-
 var filesCpCmd = &oldcmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Copy files into mfs.",
@@ -304,7 +187,7 @@ var filesCpCmd = &oldcmds.Command{
 		cmdkit.StringArg("dest", true, false, "Destination to copy object to."),
 	},
 	Run: func(req oldcmds.Request, res oldcmds.Response) {
-		node, err := req.InvocContext().GetNode()
+		api, err := req.InvocContext().GetApi()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
@@ -312,71 +195,16 @@ var filesCpCmd = &oldcmds.Command{
 
 		flush, _, _ := req.Option("flush").Bool()
 
-		src, err := checkPath(req.Arguments()[0])
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-		src = strings.TrimRight(src, "/")
-
-		dst, err := checkPath(req.Arguments()[1])
+		err = api.Files().Cp(req.Context(), req.Arguments()[0], req.Arguments()[1], options.Files.Cp.Flush(flush))
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
 
-		if dst[len(dst)-1] == '/' {
-			dst += gopath.Base(src)
-		}
-
-		nd, err := getNodeFromPath(req.Context(), node, node.DAG, src)
-		if err != nil {
-			res.SetError(fmt.Errorf("cp: cannot get node from path %s: %s", src, err), cmdkit.ErrNormal)
-			return
-		}
-
-		err = mfs.PutNode(node.FilesRoot, dst, nd)
-		if err != nil {
-			res.SetError(fmt.Errorf("cp: cannot put node in path %s: %s", dst, err), cmdkit.ErrNormal)
-			return
-		}
-
-		if flush {
-			err := mfs.FlushPath(node.FilesRoot, dst)
-			if err != nil {
-				res.SetError(err, cmdkit.ErrNormal)
-				return
-			}
-		}
-
 		res.SetOutput(nil)
 	},
 }
 
-func getNodeFromPath(ctx context.Context, node *core.Dms3FsNode, dagservice dms3ld.DAGService, p string) (dms3ld.Node, error) {
-	switch {
-	case strings.HasPrefix(p, "/dms3fs/"):
-		np, err := path.ParsePath(p)
-		if err != nil {
-			return nil, err
-		}
-
-		resolver := &resolver.Resolver{
-			DAG:         dagservice,
-			ResolveOnce: uio.ResolveUnixfsOnce,
-		}
-
-		return core.Resolve(ctx, node.Namesys, resolver, np)
-	default:
-		fsn, err := mfs.Lookup(node.FilesRoot, p)
-		if err != nil {
-			return nil, err
-		}
-
-		return fsn.GetNode()
-	}
-}
-
 type filesLsOutput struct {
 	Entries []mfs.NodeListing
 }
@@ -418,76 +246,31 @@ Examples:
 			arg = req.Arguments()[0]
 		}
 
-		path, err := checkPath(arg)
+		api, err := req.InvocContext().GetApi()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
 
-		nd, err := req.InvocContext().GetNode()
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		}
+		long, _, _ := req.Option("l").Bool()
 
-		fsn, err := mfs.Lookup(nd.FilesRoot, path)
+		entries, err := api.Files().Ls(req.Context(), arg, options.Files.Ls.Long(long))
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
 
-		long, _, _ := req.Option("l").Bool()
-
-		switch fsn := fsn.(type) {
-		case *mfs.Directory:
-			if !long {
-				var output []mfs.NodeListing
-				names, err := fsn.ListNames(req.Context())
-				if err != nil {
-					res.SetError(err, cmdkit.ErrNormal)
-					return
-				}
-
-				for _, name := range names {
-					output = append(output, mfs.NodeListing{
-						Name: name,
-					})
-				}
-				res.SetOutput(&filesLsOutput{output})
-			} else {
-				listing, err := fsn.List(req.Context())
-				if err != nil {
-					res.SetError(err, cmdkit.ErrNormal)
-					return
-				}
-				res.SetOutput(&filesLsOutput{listing})
-			}
-			return
-		case *mfs.File:
-			_, name := gopath.Split(path)
-			out := &filesLsOutput{[]mfs.NodeListing{mfs.NodeListing{Name: name}}}
-			if long {
-				out.Entries[0].Type = int(fsn.Type())
-
-				size, err := fsn.Size()
-				if err != nil {
-					res.SetError(err, cmdkit.ErrNormal)
-					return
-				}
-				out.Entries[0].Size = size
-
-				nd, err := fsn.GetNode()
-				if err != nil {
-					res.SetError(err, cmdkit.ErrNormal)
-					return
-				}
-				out.Entries[0].Hash = nd.Cid().String()
+		output := make([]mfs.NodeListing, len(entries))
+		for i, ent := range entries {
+			output[i] = mfs.NodeListing{
+				Name: ent.Name,
+				Type: ent.Type,
+				Size: ent.Size,
+				Hash: ent.Hash,
 			}
-			res.SetOutput(out)
-			return
-		default:
-			res.SetError(errors.New("unrecognized type"), cmdkit.ErrNormal)
 		}
+
+		res.SetOutput(&filesLsOutput{output})
 	},
 	Marshalers: oldcmds.MarshalerMap{
 		oldcmds.Text: func(res oldcmds.Response) (io.Reader, error) {
@@ -546,66 +329,22 @@ Examples:
 		cmdkit.IntOption("count", "n", "Maximum number of bytes to read."),
 	},
 	Run: func(req oldcmds.Request, res oldcmds.Response) {
-		n, err := req.InvocContext().GetNode()
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-
-		path, err := checkPath(req.Arguments()[0])
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-
-		fsn, err := mfs.Lookup(n.FilesRoot, path)
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-
-		fi, ok := fsn.(*mfs.File)
-		if !ok {
-			res.SetError(fmt.Errorf("%s was not a file", path), cmdkit.ErrNormal)
-			return
-		}
-
-		rfd, err := fi.Open(mfs.OpenReadOnly, false)
+		api, err := req.InvocContext().GetApi()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
 
-		defer rfd.Close()
-
 		offset, _, err := req.Option("offset").Int()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
-		if offset < 0 {
-			res.SetError(fmt.Errorf("cannot specify negative offset"), cmdkit.ErrNormal)
-			return
-		}
 
-		filen, err := rfd.Size()
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-
-		if int64(offset) > filen {
-			res.SetError(fmt.Errorf("offset was past end of file (%d > %d)", offset, filen), cmdkit.ErrNormal)
-			return
-		}
-
-		_, err = rfd.Seek(int64(offset), io.SeekStart)
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
+		opts := []options.FilesReadOption{
+			options.Files.Read.Offset(int64(offset)),
 		}
 
-		var r io.Reader = &contextReaderWrapper{R: rfd, ctx: req.Context()}
 		count, found, err := req.Option("count").Int()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
@@ -616,24 +355,19 @@ Examples:
 				res.SetError(fmt.Errorf("cannot specify negative 'count'"), cmdkit.ErrNormal)
 				return
 			}
-			r = io.LimitReader(r, int64(count))
+			opts = append(opts, options.Files.Read.Count(int64(count)))
 		}
 
-		res.SetOutput(r)
-	},
-}
+		r, err := api.Files().Read(req.Context(), req.Arguments()[0], opts...)
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
 
-type contextReader interface {
-	CtxReadFull(context.Context, []byte) (int, error)
-}
+		defer r.Close()
 
-type contextReaderWrapper struct {
-	R   contextReader
-	ctx context.Context
-}
-
-func (crw *contextReaderWrapper) Read(b []byte) (int, error) {
-	return crw.R.CtxReadFull(crw.ctx, b)
+		res.SetOutput(r)
+	},
 }
 
 var filesMvCmd = &oldcmds.Command{
@@ -654,24 +388,13 @@ Example:
 		cmdkit.StringArg("dest", true, false, "Destination path for file to be moved to."),
 	},
 	Run: func(req oldcmds.Request, res oldcmds.Response) {
-		n, err := req.InvocContext().GetNode()
+		api, err := req.InvocContext().GetApi()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
 
-		src, err := checkPath(req.Arguments()[0])
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-		dst, err := checkPath(req.Arguments()[1])
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-
-		err = mfs.Mv(n.FilesRoot, src, dst)
+		err = api.Files().Mv(req.Context(), req.Arguments()[0], req.Arguments()[1])
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
@@ -730,7 +453,7 @@ stat' on the file or any of its ancestors.
 		hashOption,
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) {
-		path, err := checkPath(req.Arguments[0])
+		api, err := cmdenv.GetApi(env)
 		if err != nil {
 			re.SetError(err, cmdkit.ErrNormal)
 			return
@@ -740,74 +463,40 @@ stat' on the file or any of its ancestors.
 		mkParents, _ := req.Options["parents"].(bool)
 		trunc, _ := req.Options["truncate"].(bool)
 		flush, _ := req.Options["flush"].(bool)
-		rawLeaves, rawLeavesDef := req.Options["raw-leaves"].(bool)
-
-		prefix, err := getPrefixNew(req)
-		if err != nil {
-			re.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-
-		nd, err := cmdenv.GetNode(env)
-		if err != nil {
-			re.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-
 		offset, _ := req.Options["offset"].(int)
-		if offset < 0 {
-			re.SetError(fmt.Errorf("cannot have negative write offset"), cmdkit.ErrNormal)
-			return
-		}
 
-		if mkParents {
-			err := ensureContainingDirectoryExists(nd.FilesRoot, path, prefix)
-			if err != nil {
-				re.SetError(err, cmdkit.ErrNormal)
-				return
-			}
+		opts := []options.FilesWriteOption{
+			options.Files.Write.Offset(int64(offset)),
+			options.Files.Write.Create(create),
+			options.Files.Write.Parents(mkParents),
+			options.Files.Write.Truncate(trunc),
+			options.Files.Write.Flush(flush),
 		}
 
-		fi, err := getFileHandle(nd.FilesRoot, path, create, prefix)
-		if err != nil {
-			re.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-		if rawLeavesDef {
-			fi.RawLeaves = rawLeaves
+		if rawLeaves, ok := req.Options["raw-leaves"].(bool); ok {
+			opts = append(opts, options.Files.Write.RawLeaves(rawLeaves))
 		}
 
-		wfd, err := fi.Open(mfs.OpenWriteOnly, flush)
-		if err != nil {
-			re.SetError(err, cmdkit.ErrNormal)
-			return
+		if cidVer, ok := req.Options["cid-version"].(int); ok {
+			opts = append(opts, options.Files.Write.CidVersion(cidVer))
 		}
 
-		defer func() {
-			err := wfd.Close()
+		if hashFunStr, ok := req.Options["hash"].(string); ok {
+			hashFunCode, err := getHashFunc(hashFunStr)
 			if err != nil {
 				re.SetError(err, cmdkit.ErrNormal)
-			}
-		}()
-
-		if trunc {
-			if err := wfd.Truncate(0); err != nil {
-				re.SetError(err, cmdkit.ErrNormal)
 				return
 			}
+			opts = append(opts, options.Files.Write.Hash(hashFunCode))
 		}
 
 		count, countfound := req.Options["count"].(int)
-		if countfound && count < 0 {
-			re.SetError(fmt.Errorf("cannot have negative byte count"), cmdkit.ErrNormal)
-			return
-		}
-
-		_, err = wfd.Seek(int64(offset), io.SeekStart)
-		if err != nil {
-			flog.Error("seekfail: ", err)
-			re.SetError(err, cmdkit.ErrNormal)
-			return
+		if countfound {
+			if count < 0 {
+				re.SetError(fmt.Errorf("cannot have negative byte count"), cmdkit.ErrNormal)
+				return
+			}
+			opts = append(opts, options.Files.Write.Count(int64(count)))
 		}
 
 		input, err := req.Files.NextFile()
@@ -816,12 +505,7 @@ stat' on the file or any of its ancestors.
 			return
 		}
 
-		var r io.Reader = input
-		if countfound {
-			r = io.LimitReader(r, int64(count))
-		}
-
-		_, err = io.Copy(wfd, r)
+		err = api.Files().Write(req.Context, req.Arguments[0], input, opts...)
 		if err != nil {
 			re.SetError(err, cmdkit.ErrNormal)
 			return
@@ -856,33 +540,36 @@ Examples:
 		hashOption,
 	},
 	Run: func(req oldcmds.Request, res oldcmds.Response) {
-		n, err := req.InvocContext().GetNode()
+		api, err := req.InvocContext().GetApi()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
 
 		dashp, _, _ := req.Option("parents").Bool()
-		dirtomake, err := checkPath(req.Arguments()[0])
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
+		flush, _, _ := req.Option("flush").Bool()
+
+		opts := []options.FilesMkdirOption{
+			options.Files.Mkdir.Parents(dashp),
+			options.Files.Mkdir.Flush(flush),
 		}
 
-		flush, _, _ := req.Option("flush").Bool()
+		cidVer, cidVerSet, _ := req.Option("cid-version").Int()
+		if cidVerSet {
+			opts = append(opts, options.Files.Mkdir.CidVersion(cidVer))
+		}
 
-		prefix, err := getPrefix(req)
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
+		hashFunStr, hashFunSet, _ := req.Option("hash").String()
+		if hashFunSet {
+			hashFunCode, err := getHashFunc(hashFunStr)
+			if err != nil {
+				res.SetError(err, cmdkit.ErrNormal)
+				return
+			}
+			opts = append(opts, options.Files.Mkdir.Hash(hashFunCode))
 		}
-		root := n.FilesRoot
 
-		err = mfs.Mkdir(root, dirtomake, mfs.MkdirOpts{
-			Mkparents:  dashp,
-			Flush:      flush,
-			CidBuilder: prefix,
-		})
+		err = api.Files().Mkdir(req.Context(), req.Arguments()[0], opts...)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
@@ -904,7 +591,7 @@ are run with the '--flush=false'.
 		cmdkit.StringArg("path", false, false, "Path to flush. Default: '/'."),
 	},
 	Run: func(req oldcmds.Request, res oldcmds.Response) {
-		nd, err := req.InvocContext().GetNode()
+		api, err := req.InvocContext().GetApi()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
@@ -915,7 +602,7 @@ are run with the '--flush=false'.
 			path = req.Arguments()[0]
 		}
 
-		err = mfs.FlushPath(nd.FilesRoot, path)
+		err = api.Files().Flush(req.Context(), path)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
@@ -940,7 +627,7 @@ Change the cid version or hash function of the root node of a given path.
 		hashOption,
 	},
 	Run: func(req oldcmds.Request, res oldcmds.Response) {
-		nd, err := req.InvocContext().GetNode()
+		api, err := req.InvocContext().GetApi()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
@@ -953,13 +640,26 @@ Change the cid version or hash function of the root node of a given path.
 
 		flush, _, _ := req.Option("flush").Bool()
 
-		prefix, err := getPrefix(req)
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
+		opts := []options.FilesChcidOption{
+			options.Files.Chcid.Flush(flush),
+		}
+
+		cidVer, cidVerSet, _ := req.Option("cid-version").Int()
+		if cidVerSet {
+			opts = append(opts, options.Files.Chcid.CidVersion(cidVer))
+		}
+
+		hashFunStr, hashFunSet, _ := req.Option("hash").String()
+		if hashFunSet {
+			hashFunCode, err := getHashFunc(hashFunStr)
+			if err != nil {
+				res.SetError(err, cmdkit.ErrNormal)
+				return
+			}
+			opts = append(opts, options.Files.Chcid.Hash(hashFunCode))
 		}
 
-		err = updatePath(nd.FilesRoot, path, prefix, flush)
+		err = api.Files().Chcid(req.Context(), path, opts...)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
@@ -969,30 +669,6 @@ Change the cid version or hash function of the root node of a given path.
 	},
 }
 
-func updatePath(rt *mfs.Root, pth string, builder cid.Builder, flush bool) error {
-	if builder == nil {
-		return nil
-	}
-
-	nd, err := mfs.Lookup(rt, pth)
-	if err != nil {
-		return err
-	}
-
-	switch n := nd.(type) {
-	case *mfs.Directory:
-		n.SetCidBuilder(builder)
-	default:
-		return fmt.Errorf("can only update directories")
-	}
-
-	if flush {
-		nd.Flush()
-	}
-
-	return nil
-}
-
 var filesRmCmd = &oldcmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Remove a file.",
@@ -1017,224 +693,26 @@ Remove files or directories.
 	Run: func(req oldcmds.Request, res oldcmds.Response) {
 		defer res.SetOutput(nil)
 
-		nd, err := req.InvocContext().GetNode()
+		api, err := req.InvocContext().GetApi()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
 
-		path, err := checkPath(req.Arguments()[0])
-		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-
-		if path == "/" {
-			res.SetError(fmt.Errorf("cannot delete root"), cmdkit.ErrNormal)
-			return
-		}
-
-		// 'rm a/b/c/' will fail unless we trim the slash at the end
-		if path[len(path)-1] == '/' {
-			path = path[:len(path)-1]
-		}
-
-		dir, name := gopath.Split(path)
-		parent, err := mfs.Lookup(nd.FilesRoot, dir)
-		if err != nil {
-			res.SetError(fmt.Errorf("parent lookup: %s", err), cmdkit.ErrNormal)
-			return
-		}
-
-		pdir, ok := parent.(*mfs.Directory)
-		if !ok {
-			res.SetError(fmt.Errorf("no such file or directory: %s", path), cmdkit.ErrNormal)
-			return
-		}
-
 		dashr, _, _ := req.Option("r").Bool()
 
-		var success bool
-		defer func() {
-			if success {
-				err := pdir.Flush()
-				if err != nil {
-					res.SetError(err, cmdkit.ErrNormal)
-					return
-				}
-			}
-		}()
-
-		// if '-r' specified, don't check file type (in bad scenarios, the block may not exist)
-		if dashr {
-			err := pdir.Unlink(name)
-			if err != nil {
-				res.SetError(err, cmdkit.ErrNormal)
-				return
-			}
-
-			success = true
-			return
-		}
-
-		childi, err := pdir.Child(name)
+		err = api.Files().Rm(req.Context(), req.Arguments()[0], options.Files.Rm.Recursive(dashr))
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
-
-		switch childi.(type) {
-		case *mfs.Directory:
-			res.SetError(fmt.Errorf("%s is a directory, use -r to remove directories", path), cmdkit.ErrNormal)
-			return
-		default:
-			err := pdir.Unlink(name)
-			if err != nil {
-				res.SetError(err, cmdkit.ErrNormal)
-				return
-			}
-
-			success = true
-		}
 	},
 }
 
-func getPrefixNew(req *cmds.Request) (cid.Builder, error) {
-	cidVer, cidVerSet := req.Options["cid-version"].(int)
-	hashFunStr, hashFunSet := req.Options["hash"].(string)
-
-	if !cidVerSet && !hashFunSet {
-		return nil, nil
-	}
-
-	if hashFunSet && cidVer == 0 {
-		cidVer = 1
-	}
-
-	prefix, err := dag.PrefixForCidVersion(cidVer)
-	if err != nil {
-		return nil, err
-	}
-
-	if hashFunSet {
-		hashFunCode, ok := mh.Names[strings.ToLower(hashFunStr)]
-		if !ok {
-			return nil, fmt.Errorf("unrecognized hash function: %s", strings.ToLower(hashFunStr))
-		}
-		prefix.MhType = hashFunCode
-		prefix.MhLength = -1
-	}
-
-	return &prefix, nil
-}
-
-func getPrefix(req oldcmds.Request) (cid.Builder, error) {
-	cidVer, cidVerSet, _ := req.Option("cid-version").Int()
-	hashFunStr, hashFunSet, _ := req.Option("hash").String()
-
-	if !cidVerSet && !hashFunSet {
-		return nil, nil
-	}
-
-	if hashFunSet && cidVer == 0 {
-		cidVer = 1
-	}
-
-	prefix, err := dag.PrefixForCidVersion(cidVer)
-	if err != nil {
-		return nil, err
-	}
-
-	if hashFunSet {
-		hashFunCode, ok := mh.Names[strings.ToLower(hashFunStr)]
-		if !ok {
-			return nil, fmt.Errorf("unrecognized hash function: %s", strings.ToLower(hashFunStr))
-		}
-		prefix.MhType = hashFunCode
-		prefix.MhLength = -1
-	}
-
-	return &prefix, nil
-}
-
-func ensureContainingDirectoryExists(r *mfs.Root, path string, builder cid.Builder) error {
-	dirtomake := gopath.Dir(path)
-
-	if dirtomake == "/" {
-		return nil
-	}
-
-	return mfs.Mkdir(r, dirtomake, mfs.MkdirOpts{
-		Mkparents:  true,
-		CidBuilder: builder,
-	})
-}
-
-func getFileHandle(r *mfs.Root, path string, create bool, builder cid.Builder) (*mfs.File, error) {
-	target, err := mfs.Lookup(r, path)
-	switch err {
-	case nil:
-		fi, ok := target.(*mfs.File)
-		if !ok {
-			return nil, fmt.Errorf("%s was not a file", path)
-		}
-		return fi, nil
-
-	case os.ErrNotExist:
-		if !create {
-			return nil, err
-		}
-
-		// if create is specified and the file doesnt exist, we create the file
-		dirname, fname := gopath.Split(path)
-		pdiri, err := mfs.Lookup(r, dirname)
-		if err != nil {
-			flog.Error("lookupfail ", dirname)
-			return nil, err
-		}
-		pdir, ok := pdiri.(*mfs.Directory)
-		if !ok {
-			return nil, fmt.Errorf("%s was not a directory", dirname)
-		}
-		if builder == nil {
-			builder = pdir.GetCidBuilder()
-		}
-
-		nd := dag.NodeWithData(ft.FilePBData(nil, 0))
-		nd.SetCidBuilder(builder)
-		err = pdir.AddChild(fname, nd)
-		if err != nil {
-			return nil, err
-		}
-
-		fsn, err := pdir.Child(fname)
-		if err != nil {
-			return nil, err
-		}
-
-		fi, ok := fsn.(*mfs.File)
-		if !ok {
-			return nil, errors.New("expected *mfs.File, didnt get it. This is likely a race condition")
-		}
-		return fi, nil
-
-	default:
-		return nil, err
-	}
-}
-
-func checkPath(p string) (string, error) {
-	if len(p) == 0 {
-		return "", fmt.Errorf("paths must not be empty")
-	}
-
-	if p[0] != '/' {
-		return "", fmt.Errorf("paths must start with a leading slash")
-	}
-
-	cleaned := gopath.Clean(p)
-	if p[len(p)-1] == '/' && p != "/" {
-		cleaned += "/"
+func getHashFunc(hashFunStr string) (uint64, error) {
+	hashFunCode, ok := mh.Names[strings.ToLower(hashFunStr)]
+	if !ok {
+		return 0, fmt.Errorf("unrecognized hash function: %s", strings.ToLower(hashFunStr))
 	}
-	return cleaned, nil
+	return hashFunCode, nil
 }
@@ -1,19 +1,15 @@
 package index
 
 import (
-	//"context"
-	"errors"
 	"fmt"
 	"io"
 
-	core "github.com/dms3-fs/go-dms3-fs/core"
 	cmdenv "github.com/dms3-fs/go-dms3-fs/core/commands/cmdenv"
 	e "github.com/dms3-fs/go-dms3-fs/core/commands/e"
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
 
 	cmds "github.com/dms3-fs/go-fs-cmds"
 	cmdkit "github.com/dms3-fs/go-fs-cmdkit"
-    dsquery "github.com/dms3-fs/go-datastore/query"
-	idxkvs "github.com/dms3-fs/go-dms3-fs/core/coreindex/kvs"
 	logging "github.com/dms3-fs/go-log"
 )
 
@@ -75,17 +71,34 @@ Use the '--length' flag to specify length of each result page.
 		log.Debugf("offset option %v", popt)
 		log.Debugf("length option %v", lopt)
 
-		n, err := cmdenv.GetNode(env)
+		api, err := cmdenv.GetApi(env)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
 
-		output, err := listRepo(n, kopt, nopt, mopt, dopt, popt, lopt)
+		list, err := api.Index().List(req.Context,
+			options.Index.List.Kind(kopt),
+			options.Index.List.Name(nopt),
+			options.Index.List.Metastore(mopt),
+			options.Index.List.Infostore(dopt),
+			options.Index.List.Offset(popt),
+			options.Index.List.Length(lopt),
+		)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
+
+		output := make(ReposetRefList, len(list))
+		for i, r := range list {
+			output[i] = ReposetRef{
+				Infoclass:   r.Class(),
+				Reposetkind: r.Kind(),
+				Reposetname: r.Name(),
+				Reposetpath: r.Path().Cid().String(),
+			}
+		}
 		cmds.EmitOnce(res, output)
 
 		log.Debugf("output %s", output)
@@ -109,74 +122,6 @@ Use the '--length' flag to specify length of each result page.
 	Type: ReposetRefList{},
 }
 
-func listRepo(n *core.Dms3FsNode, kind, reposetname string, wantmeta bool, wantdata bool, startpage, pagesize int) (ReposetRefList, error) {
-
-	// set the KV store to use
-	idxkvs.InitIndexKVStore(n.Repo.Datastore())
-	dstore := idxkvs.GetIndexKVStore()
-
-	nresult, readCount, readPage := 0, 0, 0
-	rlist := []ReposetRef{}
-
-    if res, err := dstore.Query(dsquery.Query{}); err != nil {
-		return rlist, errors.New(fmt.Sprintf("cannot issue Query request %v\n.", err))
-    } else {
-        defer res.Close()
-OuterLoop:
-        for {
-            select {
-            case result, ok := <-res.Next():
-                if !ok {
-                    // no more left
-                    break OuterLoop
-                }
-                if result.Error != nil {
-					return rlist, errors.New(fmt.Sprintf("Query returned internal error %v\n.", err))
-                }
-				// count entries, pages read
-				readCount += 1
-				if readCount > pagesize {
-					readCount = 0
-					readPage += 1
-				}
-				// if on correct page
-				if readPage >= startpage {
-					// read next key, value
-					//rtype, rkind, rname, err := idxkvs.DecomposeRepoSetKey(result.Key)
-					rtype, rkind, rname, err := idxkvs.DecomposeRepoSetKey(result.Key)
-					if err != nil {
-						return rlist, errors.New(fmt.Sprintf("Query returned invalid reposet key error %v\n.", err))
-					}
-					// if entry matches option filters
-					if ((wantmeta && rtype == "metastore") || (wantdata && rtype == "infostore")) &&
-						(kind == "" || rkind == "" || kind == rkind) && (reposetname == "" || reposetname == rname) {
-
-						// add entry to results
-						r := idxkvs.NewRps()
-		                if err := r.Unmarshal(result.Value); err != nil {
-							return rlist, errors.New(fmt.Sprintf("cannot unmarshal Query returned value."))
-		                }
-						rr := ReposetRef{
-							Infoclass: rtype,
-							Reposetkind: rkind,
-							Reposetname: rname,
-							Reposetpath: r.GetCid().String(),
-						}
-						rlist = append(rlist, rr)
-						// count results and honor requested limit
-						nresult += 1
-						if nresult == pagesize {
-							break OuterLoop
-						}
-					}
-				}
-            }
-        }
-    }
-
-	return rlist, nil
-}
-
 var NotyetIndexCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Not yet implemented.",
@@ -5,41 +5,16 @@ import (
 	"fmt"
 	"io"
 	"os"
-    "path"
-    "path/filepath"
-	"strconv"
-	"strings"
-	"time"
-
-	blockservice "github.com/dms3-fs/go-blockservice"
-	bstore "github.com/dms3-fs/go-fs-blockstore"
-	cid "github.com/dms3-fs/go-cid"
-	cidutil "github.com/dms3-fs/go-cidutil"
+
 	cmdenv "github.com/dms3-fs/go-dms3-fs/core/commands/cmdenv"
-	cmdkit "github.com/dms3-fs/go-fs-cmdkit"
-	cmds "github.com/dms3-fs/go-fs-cmds"
-	core "github.com/dms3-fs/go-dms3-fs/core"
-	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
-	coreunix "github.com/dms3-fs/go-dms3-fs/core/coreunix"
-	dag "github.com/dms3-fs/go-merkledag"
-	dagtest "github.com/dms3-fs/go-merkledag/test"
-    dms3ld "github.com/dms3-fs/go-ld-format"
-	ds "github.com/dms3-fs/go-datastore"
 	e "github.com/dms3-fs/go-dms3-fs/core/commands/e"
-	files "github.com/dms3-fs/go-fs-cmdkit/files"
-	filestore "github.com/dms3-fs/go-dms3-fs/filestore"
-	ft "github.com/dms3-fs/go-unixfs"
-	idxlfs "github.com/dms3-fs/go-dms3-fs/core/coreindex/lfs"
-	idxkvs "github.com/dms3-fs/go-dms3-fs/core/coreindex/kvs"
-	idxufs "github.com/dms3-fs/go-dms3-fs/core/coreindex/ufs"
-	mfs "github.com/dms3-fs/go-mfs"
-	mh "github.com/dms3-mft/go-multihash"
-	offline "github.com/dms3-fs/go-fs-exchange-offline"
-	dms3fspath "github.com/dms3-fs/go-path"
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
 	pb "github.com/cheggaaa/pb"
-	"github.com/dms3-fs/go-dms3-fs/pin"
-	resolver "github.com/dms3-fs/go-path/resolver"
-	uio "github.com/dms3-fs/go-unixfs/io"
+	cmds "github.com/dms3-fs/go-fs-cmds"
+	cmdkit "github.com/dms3-fs/go-fs-cmdkit"
+	files "github.com/dms3-fs/go-fs-cmdkit/files"
 )
 
 // ErrDepthLimitExceeded indicates that the max depth has been exceeded.
@@ -55,10 +30,6 @@ const (
 	dataOptionName		  = "data"
 	lengthOptionName	  = "length"
 	offsetOptionName	  = "offset"
-	// internal properties
-	// - hack to pass parameters from the command Run to PostRun function
-	infoClassName		  = "info-class"
-	createdAtName		  = "created-at"
 )
 
 const adderOutChanSize = 8
@@ -116,24 +87,18 @@ specified by the path.
 		cmdkit.BoolOption(progressOptionName, "p", "Stream progress data.").WithDefault(true),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) {
-
-		n, err := cmdenv.GetNode(env)
+		api, err := cmdenv.GetApi(env)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
 
-		//
-		// process and verify options and arguments
-		//
-
 		kopt, _ := req.Options[kindOptionName].(string)
 		if kopt == "" {
 			res.SetError(errors.New("kind of content key must be specified."), cmdkit.ErrNormal)
 			return
-		} else {
-			log.Debugf("kind option value %s", kopt)
 		}
+		log.Debugf("kind option value %s", kopt)
 
 		nopt, _ := req.Options[nameOptionName].(string)
 		if nopt == "" {
@@ -142,141 +107,39 @@ specified by the path.
 		}
 		log.Debugf("reposet name option value %s", nopt)
 
-        if len(req.Arguments) < 1 {
-			req.SetOption(infoClassName, "infostore")
-        } else {
-			req.SetOption(infoClassName, "metastore")
-			// metastore to infostore associatioins
-			paths := req.Arguments
-	        out := make([]*cid.Cid, len(paths))
-
-	        r := &resolver.Resolver{
-	                DAG:         n.DAG,
-	                ResolveOnce: uio.ResolveUnixfsOnce,
-	        }
-	        for i, fpath := range paths {
-				p, err := dms3fspath.ParsePath(fpath)
-				if err != nil {
-					res.SetError(errors.New(fmt.Sprintf("failed to parse path to infostore. error %s", err)), cmdkit.ErrNormal)
-					return
-				}
-
-				dagnode, err := core.Resolve(n.Context(), n.Namesys, r, p)
-				if err != nil {
-					res.SetError(errors.New(fmt.Sprintf("mkidx: %s", err)), cmdkit.ErrNormal)
-					return
-				}
-				out[i] = dagnode.Cid()
-				fmt.Printf("infostore[%v] cid %s\n", i, dagnode.Cid().String())
-
-				pn, ok := dagnode.(*dag.ProtoNode)
-			    if !ok {
-					res.SetError(errors.New(fmt.Sprintf("mkidx: invalid dag node %s", dagnode.Cid().String())), cmdkit.ErrNormal)
-					return
-			    }
-
-				// make index repository root with this root node
-				sr, err := idxufs.NewStoreRoot(n.Context(), n.DAG, pn)
-			    if err != nil {
-					res.SetError(errors.New(fmt.Sprintf("mkidx: failed to create NewStoreRoot with error %s", err)), cmdkit.ErrNormal)
-					return
-			    }
-
-				// make new reposetprops type
-				rps := idxufs.NewReposetProps()
+		progress, _ := req.Options[progressOptionName].(bool)
 
-				// create repoprops from store root
-			    ri, err := sr.GetProps("reposetprops", rps)
-			    if err != nil {
-					res.SetError(errors.New(fmt.Sprintf("mkidx: failed to get reposetprops with error %s", err)), cmdkit.ErrNormal)
-					return
-			    }
+		outChan := make(chan interface{}, adderOutChanSize)
 
-			    rps, ok = ri.(idxufs.ReposetProps)
-			    if !ok {
-					res.SetError(errors.New(fmt.Sprintf("mkidx: invalid reposetprops.", err)), cmdkit.ErrNormal)
-					return
-			    }
-	        }
-			//res.SetError(errors.New("test done"), cmdkit.ErrNormal)
-			//return
-        }
-		log.Debugf("infoclass is %s", req.Options[infoClassName].(string))
-
-
- 		// load the index configuration
-		icfg, err := n.Repo.IdxConfig()
-		if err != nil {
-			res.SetError(errors.New("could not load index config."), cmdkit.ErrNormal)
-			return
+		opts := []options.IndexMakeOption{
+			options.Index.Make.Events(outChan),
+			options.Index.Make.Progress(progress),
 		}
 
-		// check repo kind is configured
-		if found, err := idxlfs.IsKindConfigured(icfg, kopt); err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		} else {
-			if !found {
-				res.SetError(fmt.Sprintf("metadata not configured for repo kind %s\n", kopt), cmdkit.ErrNormal)
-				return
-			}
-		}
-
-		// check repo does not already exists on local filesystem
-		var rpath string
-
-		if found, p, err := idxlfs.ReposetExists(kopt, nopt); err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		} else {
-			if found {
-				res.SetError(fmt.Sprintf("named reposet path already exists %s\n", rpath), cmdkit.ErrNormal)
-				return
-			} else {
-				rpath = p
-			}
+		// metastore to infostore associations
+		if len(req.Arguments) > 0 {
+			opts = append(opts, options.Index.Make.Infostores(req.Arguments...))
 		}
 
-		// check repo does not already exists on in kvstore
-		var key ds.Key
-
-		iopt, _ := req.Options[infoClassName].(string)
-
-		// set the KV store to use
-		idxkvs.InitIndexKVStore(n.Repo.Datastore())
-		dstore := idxkvs.GetIndexKVStore()
-
-		if key, err = idxkvs.GetRepoSetKey(iopt, kopt, nopt); err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-        } else {
-			if _, err = dstore.Get(key); err == nil {
-				res.SetError(fmt.Sprintf("named reposet key already exists %s\n", key), cmdkit.ErrNormal)
-				return
-			}
-		}
-		log.Debugf("reposet key is %v\n", key)
+		errCh := make(chan error)
+		go func() {
+			var err error
+			defer func() { errCh <- err }()
+			defer close(outChan)
+			_, err = api.Index().MakeReposet(req.Context, kopt, nopt, opts...)
+		}()
 
-		// now we are ready to configure the reposet
+		defer res.Close()
 
-		// create the params file on local filesystem
-		var paramsfile, reponame string
-		if fn, rn, ct, err := idxlfs.MakeRepo(icfg, rpath, kopt); err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
+		err = res.Emit(outChan)
+		if err != nil {
+			log.Error(err)
 			return
-		} else {
-			paramsfile = fn
-			reponame = rn
-			req.SetOption(createdAtName, fmt.Sprintf("%s", ct.UTC().Format(time.RFC3339)))
-			log.Debugf("reposet create time %s", req.Options[createdAtName].(string))
 		}
-
-		// add params file into Dms3Fs
-		if err := addParamsFile(req, res, env, n, paramsfile, reponame); err != nil {
+		err = <-errCh
+		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
-			return
 		}
-
 	},
 	PostRun: cmds.PostRunMap{
 		cmds.CLI: func(req *cmds.Request, re cmds.ResponseEmitter) cmds.ResponseEmitter {
@@ -337,7 +200,7 @@ specified by the path.
 
 							break LOOP
 						}
-						output := out.(*coreunix.AddedObject)
+						output := out.(*coreiface.AddEvent)
 						if len(output.Hash) > 0 {
 							lastHash = output.Hash
 							if quieter {
@@ -422,354 +285,12 @@ specified by the path.
 			return reNext
 		},
 	},
-	Type: coreunix.AddedObject{},
+	Type: coreiface.AddEvent{},
 }
 
 
-func addParamsFile(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment, n *core.Dms3FsNode, fpath, reponame string) error {
-
-	// following logic is lifted from core/commands/add
-
-	cfg, err := n.Repo.Config()
-	if err != nil {
-		return err
-	}
-	// check if repo will exceed storage limit if added
-	// TODO: this doesn't handle the case if the hashed file is already in blocks (deduplicated)
-	// TODO: conditional GC is disabled due to it is somehow not possible to pass the size to the daemon
-	//if err := corerepo.ConditionalGC(req.Context(), n, uint64(size)); err != nil {
-	//	res.SetError(err, cmdkit.ErrNormal)
-	//	return
-	//}
-
-	progress, _ := req.Options[progressOptionName].(bool)
-	trickle := false
-	wrap := false
-	hash := false
-	hidden := false
-	silent := false
-	chunker := "size-262144"
-	dopin := true
-	rawblks, rbset := false, false
-	nocopy := false
-	fscache := false
-	cidVer, cidVerSet := 1, true
-	hashFunStr := "sha2-256"
-	inline := false
-	inlineLimit := 32
-
-	// The arguments are subject to the following constraints.
-	//
-	// nocopy -> filestoreEnabled
-	// nocopy -> rawblocks
-	// (hash != sha2-256) -> cidv1
-
-	// NOTE: 'rawblocks -> cidv1' is missing. Legacy reasons.
-
-	// nocopy -> filestoreEnabled
-	if nocopy && !cfg.Experimental.FilestoreEnabled {
-		return filestore.ErrFilestoreNotEnabled
-	}
-
-	// nocopy -> rawblocks
-	if nocopy && !rawblks {
-		// fixed?
-		if rbset {
-			return fmt.Errorf("nocopy option requires '--raw-leaves' to be enabled as well")
-		}
-		// No, satisfy mandatory constraint.
-		rawblks = true
-	}
-
-	// (hash != "sha2-256") -> CIDv1
-	if hashFunStr != "sha2-256" && cidVer == 0 {
-		if cidVerSet {
-			return errors.New("CIDv0 only supports sha2-256")
-		}
-		cidVer = 1
-	}
-
-	// cidV1 -> raw blocks (by default)
-	if cidVer > 0 && !rbset {
-		rawblks = true
-	}
-
-	prefix, err := dag.PrefixForCidVersion(cidVer)
-	if err != nil {
-		return err
-	}
-
-	hashFunCode, ok := mh.Names[strings.ToLower(hashFunStr)]
-	if !ok {
-		return fmt.Errorf("unrecognized hash function: %s", strings.ToLower(hashFunStr))
-	}
-
-	prefix.MhType = hashFunCode
-	prefix.MhLength = -1
-
-	if hash {
-		nilnode, err := core.NewNode(n.Context(), &core.BuildCfg{
-			//TODO: need this to be true or all files
-			// hashed will be stored in memory!
-			NilRepo: true,
-		})
-		if err != nil {
-			return err
-		}
-		n = nilnode
-	}
-
-	addblockstore := n.Blockstore
-	if !(fscache || nocopy) {
-		addblockstore = bstore.NewGCBlockstore(n.BaseBlocks, n.GCLocker)
-	}
-
-	exch := n.Exchange
-	local, _ := req.Options["local"].(bool)
-	if local {
-		exch = offline.Exchange(addblockstore)
-	}
-
-	bserv := blockservice.New(addblockstore, exch) // hash security 001
-	dserv := dag.NewDAGService(bserv)
-
-	outChan := make(chan interface{}, adderOutChanSize)
-
-	fileAdder, err := coreunix.NewAdder(req.Context, n.Pinning, n.Blockstore, dserv)
-	if err != nil {
-		return err
-	}
-
-	fileAdder.Out = outChan
-	fileAdder.Chunker = chunker
-	fileAdder.Progress = progress
-	fileAdder.Hidden = hidden
-	fileAdder.Trickle = trickle
-	fileAdder.Wrap = wrap
-	fileAdder.Pin = dopin
-	fileAdder.Silent = silent
-	fileAdder.RawLeaves = rawblks
-	fileAdder.NoCopy = nocopy
-	fileAdder.CidBuilder = prefix
-
-	if inline {
-		fileAdder.CidBuilder = cidutil.InlineBuilder{
-			Builder: fileAdder.CidBuilder,
-			Limit:   inlineLimit,
-		}
-	}
-
-	if hash {
-		md := dagtest.Mock()
-		emptyDirNode := ft.EmptyDirNode()
-		// Use the same prefix for the "empty" MFS root as for the file adder.
-		emptyDirNode.SetCidBuilder(fileAdder.CidBuilder)
-		mr, err := mfs.NewRoot(req.Context, md, emptyDirNode, nil)
-		if err != nil {
-			return err
-		}
-
-		fileAdder.SetMfsRoot(mr)
-	}
-
-	addAndPin := func(f files.File) error {
-
-		file := f
-
-		if err := fileAdder.AddFile(file); err != nil {
-			return err
-		}
-
-		// copy intermediary nodes from editor to our actual dagservice
-		ldnode, err := fileAdder.Finalize()
-		if err != nil {
-			return err
-		}
-
-		err = fileAdder.PinRoot()
-		if err != nil {
-			return err
-		}
-
-		api, err := cmdenv.GetApi(env)
-		if err != nil {
-			return err
-		}
-
-		// create new reposet DAG tree
-		err = createRepoNode(req, res, env, n, api, file.FullPath(), reponame, ldnode, fileAdder.Out)
-		if err != nil {
-			return err
-		}
-
-		return err
-	}
-
-	errCh := make(chan error)
-	go func() {
-		var err error
-		defer func() { errCh <- err }()
-		defer close(outChan)
-
-        fpath = filepath.ToSlash(filepath.Clean(fpath))
-
-        stat, err := os.Lstat(fpath)
-        if err != nil {
-                return
-        }
-
-        if stat.IsDir() {
-			err = fmt.Errorf("Invalid params file path '%s', path must not be a directory", fpath)
-            return
-        }
-
-        f, err := files.NewSerialFile(path.Base(fpath), fpath, false, stat)
-		if err != nil {
-                return
-        }
-
-		err = addAndPin(f)
-
-		// TODO: instantiate and serve the index repository
-
-	}()
-
-	defer res.Close()
-
-	err = res.Emit(outChan)
-	if err != nil {
-		log.Error(err)
-		return err
-	}
-	err = <-errCh
-	if err != nil {
-		return err
-	}
-	return err
-}
-
-func createRepoNode(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment, n *core.Dms3FsNode, api coreiface.CoreAPI, paramsfile, reponame string, paramscid dms3ld.Node, outchan chan interface{}) error {
-
-	ctx := req.Context
-
-	defer n.Blockstore.PinLock().Unlock()
-
-	ct, err := time.Parse(time.RFC3339, req.Options[createdAtName].(string))
-	if err != nil {
-        return err
-    }
-	createdAt := uint64(ct.Unix())
-
-	rp := idxufs.NewRepoProps()
-
-	_, pfname := path.Split(paramsfile)	// pfname is params file name
-
-    rp.SetType(req.Options[infoClassName].(string))
-    rp.SetKind(req.Options[kindOptionName].(string))
-    rp.SetName(reponame)	// includes area/cat/offset matching values below
-    rp.SetOffset(0)			// offset is zero at creation time
-    rp.SetArea(1)			// 0 implies N/A
-    rp.SetCat(1)			// 0 implies N/A
-    rp.SetPath(paramsfile)
-
-	sr, err := idxufs.NewStoreRoot(ctx, n.DAG, nil)
-    if err != nil {
-		return err
-    }
-
-    rpid, err := sr.AddProps(reponame, rp)
-    if err != nil {
-		return err
-    }
-
-	outchan <- &coreunix.AddedObject{
-		Hash: rpid.String(),
-    	Name: reponame,
-    	Size: strconv.FormatUint(0, 10),
-	}
-
-	rps := idxufs.NewReposetProps()
-
-    rps.SetType(req.Options[infoClassName].(string))
-    rps.SetKind(req.Options[kindOptionName].(string))
-    rps.SetName(req.Options[nameOptionName].(string))
-    rps.SetCreatedAt(createdAt)
-    rps.SetMaxAreas(64)			// TODO: sould be configurable, per indexer or kind
-    rps.SetMaxCats(64)			// TODO: sould be configurable, per indexer or kind
-    rps.SetMaxDocs(50000000)	// TODO: sould be configurable, per indexer or kind
-
-	reposetName := "reposetprops"
-    rpsid, err := sr.AddProps(reposetName, rps)
-    if err != nil {
-		return err
-    }
-
-	outchan <- &coreunix.AddedObject{
-		Hash: rpsid.String(),
-    	Name: reposetName,
-    	Size: strconv.FormatUint(0, 10),
-	}
-
-	rootdir := sr.GetDirectory()
-
-	err = rootdir.AddChild(pfname, paramscid)
-	if err != nil {
-		return nil
-	}
-
-	nd, err := rootdir.GetNode() // adds rootdir to dag
-	if err != nil {
-		return nil
-	}
-
-	// pin nodes
-	n.Pinning.PinWithMode(nd.Cid(), pin.Recursive)
-	err = n.Pinning.Flush()
-	if err != nil {
-		return err
-	}
-
-	outchan <- &coreunix.AddedObject{
-		Hash: nd.Cid().String(),
-    	Name: "reposetdir",
-    	Size: strconv.FormatUint(0, 10),
-	}
-
-	// set the KV store to use, where we track reposet cids.
-	// this enables repo lookup by type, kind, name, etc...
-	idxkvs.InitIndexKVStore(n.Repo.Datastore())
-	dstore := idxkvs.GetIndexKVStore()
-
-	var key ds.Key
-	var value []byte
-
-	iopt, _ := req.Options[infoClassName].(string)
-	kopt, _ := req.Options[kindOptionName].(string)
-	nopt, _ := req.Options[nameOptionName].(string)
-
-	if key, err = idxkvs.GetRepoSetKey(iopt, kopt, nopt); err != nil {
-		return errors.New(fmt.Sprintf("could not lookup reposet key. error: %s\n", err))
-	}
-
-	v := idxkvs.NewRps()
-	v.SetCid(nd.Cid())
-
-	if value, err = v.Marshal(); err != nil {
-		return errors.New(fmt.Sprintf("could not marshal reposet value. error: %s\n", err))
-	}
-	if err = dstore.Put(key, value); err != nil {
-		return errors.New(fmt.Sprintf("could not put reposet key value. error: %s\n", err))
-	}
-	log.Debugf("reposet key %v value %v\n", key, value)
-
-	return err
-
-}
-
-
-
 type RepoDoc struct{
-	content string
+	Content string
 }
 
 var MakeDocumentCmd = &cmds.Command{
@@ -797,7 +318,7 @@ with all the fields pre-generated.
 		cmdkit.StringOption("kind", "k", "keyword for kind of content, ex: \"blog\" ."),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) {
-		n, err := cmdenv.GetNode(env)
+		api, err := cmdenv.GetApi(env)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
@@ -810,25 +331,15 @@ with all the fields pre-generated.
 		}
 		log.Debugf("kind option value %s", kopt)
 
-        icfg, err := n.Repo.IdxConfig()
-		if err != nil {
-			res.SetError(errors.New("could not load index config."), cmdkit.ErrNormal)
-			return
-		}
-
-		var repodoc *RepoDoc
-
-		output, err := idxlfs.MakeDoc(*icfg, kopt)
+		output, err := api.Index().MakeDoc(req.Context, kopt)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
-		} else {
-			repodoc = &RepoDoc{
-				content: output,
-			}
 		}
 
-		cmds.EmitOnce(res, repodoc)
+		cmds.EmitOnce(res, &RepoDoc{
+			Content: output,
+		})
 
 	},
 	Encoders: cmds.EncoderMap{
@@ -838,7 +349,7 @@ with all the fields pre-generated.
 				return e.TypeErr(repoDoc, v)
 			}
 
-			_, err := fmt.Fprintf(w, "%v\n",repoDoc.content)
+			_, err := fmt.Fprintf(w, "%v\n",repoDoc.Content)
 			return err
 		}),
 	},
@@ -1,20 +1,24 @@
 package index
 
 import (
-//	"strings"
-	"context"
 	"errors"
 	"fmt"
 	"io"
-//	"time"
+	"time"
 
-	core "github.com/dms3-fs/go-dms3-fs/core"
 	cmdenv "github.com/dms3-fs/go-dms3-fs/core/commands/cmdenv"
 	e "github.com/dms3-fs/go-dms3-fs/core/commands/e"
+	ncmd "github.com/dms3-fs/go-dms3-fs/core/commands/name"
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
 
-	cmds "github.com/dms3-fs/go-fs-cmds"
 	cmdkit "github.com/dms3-fs/go-fs-cmdkit"
-	path "github.com/dms3-fs/go-path"
+	cmds "github.com/dms3-fs/go-fs-cmds"
+)
+
+const (
+	keyOptionName      = "key"
+	lifetimeOptionName = "lifetime"
 )
 
 var PublishIndexCmd = &cmds.Command{
@@ -24,7 +28,9 @@ var PublishIndexCmd = &cmds.Command{
 Publish index repository specified by path.
 `,
 		LongDescription: `
-Publish index repository specified by path.
+Publish index repository specified by path under a DMS3NS name. By
+default the node's own PeerID is used, use '--key' to publish under
+another name.
 `,
 	},
 
@@ -32,81 +38,62 @@ Publish index repository specified by path.
 		cmdkit.StringArg("dms3fs-path", true, false, "repository to publish."),
 	},
 	Options: []cmdkit.Option{
-		cmdkit.BoolOption("quiet", "q", "Write just hashes of created object."),
+		cmdkit.StringOption(keyOptionName, "k", "Name of the key to be used.").WithDefault("self"),
+		cmdkit.StringOption(lifetimeOptionName, "t", "Time duration that the record will be valid for.").WithDefault("24h"),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) {
-		var repo string
-        if len(req.Arguments) != 1 {
+		if len(req.Arguments) != 1 {
 			res.SetError(errors.New("path is required."), cmdkit.ErrNormal)
 			return
-        } else {
-			repo = req.Arguments[0]
-        }
+		}
+		repo := req.Arguments[0]
 
 		log.Debugf("repo path is %s", repo)
 
-		n, err := cmdenv.GetNode(env)
+		api, err := cmdenv.GetApi(env)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
 
-		//log.Error("Running index ls : ", err)
-		log.Debugf("Running command request path %s", req.Path)
-
-		pubopts := new(publishOpts)
-
-		q, _ := req.Options["quiet"].(bool)
-		if q {
-			pubopts.q = true
-		} else {
-			pubopts.q = false
+		p, err := coreiface.ParsePath(repo)
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
 		}
-		log.Debugf("quiet option value %t", q)
-
-		ctx := req.Context
 
-/*
-		p, err := path.ParsePath(p)
+		kname, _ := req.Options[keyOptionName].(string)
+		validTimeOpt, _ := req.Options[lifetimeOptionName].(string)
+		validTime, err := time.ParseDuration(validTimeOpt)
 		if err != nil {
-			res.SetError(err, cmdkit.ErrNormal)
+			res.SetError(fmt.Errorf("error parsing lifetime option: %s", err), cmdkit.ErrNormal)
 			return
 		}
-*/
-		var p path.Path
 
-		output, err := pubRepo(ctx, n, p, pubopts)
+		entry, err := api.Index().Publish(req.Context, p,
+			options.Index.Publish.Key(kname),
+			options.Index.Publish.ValidTime(validTime),
+		)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
-		cmds.EmitOnce(res, output)
-
-		log.Debugf("output %s", output)
 
+		cmds.EmitOnce(res, &ncmd.Dms3NsEntry{
+			Name:  entry.Name(),
+			Value: entry.Value().String(),
+		})
 	},
 	Encoders: cmds.EncoderMap{
 		cmds.Text: cmds.MakeEncoder(func(req *cmds.Request, w io.Writer, v interface{}) error {
-			repoPath, ok := v.(*RepoPath)
+			entry, ok := v.(*ncmd.Dms3NsEntry)
 			if !ok {
-				return e.TypeErr(repoPath, v)
+				return e.TypeErr(entry, v)
 			}
 
-			_, err := fmt.Fprintf(w, "path %s \n", repoPath.path)
+			_, err := fmt.Fprintf(w, "Published to %s: %s\n", entry.Name, entry.Value)
 			return err
 		}),
 	},
-	Type: RepoPath{},
-}
-
-type publishOpts struct {
-	q bool
-}
-
-func pubRepo(ctx context.Context, n *core.Dms3FsNode, ref path.Path, opts *publishOpts) (*RepoPath, error) {
-
-	return &RepoPath{
-		path: "publish test worked.",
-	}, nil
-
+	Type: ncmd.Dms3NsEntry{},
 }
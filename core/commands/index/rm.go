@@ -1,20 +1,16 @@
 package index
 
 import (
-//	"strings"
-	"context"
 	"errors"
 	"fmt"
 	"io"
-//	"time"
 
-	core "github.com/dms3-fs/go-dms3-fs/core"
 	cmdenv "github.com/dms3-fs/go-dms3-fs/core/commands/cmdenv"
 	e "github.com/dms3-fs/go-dms3-fs/core/commands/e"
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
 
-	cmds "github.com/dms3-fs/go-fs-cmds"
 	cmdkit "github.com/dms3-fs/go-fs-cmdkit"
-	path "github.com/dms3-fs/go-path"
+	cmds "github.com/dms3-fs/go-fs-cmds"
 )
 
 var RemoveDocumentCmd = &cmds.Command{
@@ -25,6 +21,7 @@ Remove document from an index repository specified by path.
 `,
 		LongDescription: `
 Remove document specified by cid from index repository specified by path.
+The document itself is not unpinned.
 `,
 	},
 
@@ -33,59 +30,44 @@ Remove document specified by cid from index repository specified by path.
 		cmdkit.StringArg("dms3fs-path", true, false, "repository to remove from."),
 	},
 	Options: []cmdkit.Option{
-		cmdkit.BoolOption("quiet", "q", "Write just hashes of created object."),
+		cmdkit.BoolOption(quietOptionName, "q", "Write just hashes of removed object."),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) {
-		var cid, repo string
-        if len(req.Arguments) != 2 {
+		if len(req.Arguments) != 2 {
 			res.SetError(errors.New("cid and path are both required."), cmdkit.ErrNormal)
 			return
-        } else {
-			cid = req.Arguments[0]
-			repo = req.Arguments[1]
-        }
+		}
+		doc, repo := req.Arguments[0], req.Arguments[1]
 
-		log.Debugf("cid value is %s, repo path is %s", cid, repo)
+		log.Debugf("cid value is %s, repo path is %s", doc, repo)
 
-		n, err := cmdenv.GetNode(env)
+		api, err := cmdenv.GetApi(env)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
 
-		//log.Error("Running index ls : ", err)
-		log.Debugf("Running command request path %s", req.Path)
-
-		rmopts := new(rmdocOpts)
-
-		q, _ := req.Options["quiet"].(bool)
-		if q {
-			rmopts.q = true
-		} else {
-			rmopts.q = false
+		dp, err := coreiface.ParsePath(doc)
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
 		}
-		log.Debugf("quiet option value %t", q)
-
-		ctx := req.Context
 
-/*
-		p, err := path.ParsePath(p)
+		p, err := coreiface.ParsePath(repo)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
-*/
-		var p path.Path
 
-		output, err := rmDoc(ctx, n, p, rmopts)
+		err = api.Index().Remove(req.Context, p, dp)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
-		cmds.EmitOnce(res, output)
-
-		log.Debugf("output %s", output)
 
+		cmds.EmitOnce(res, &RepoPath{
+			Path: doc,
+		})
 	},
 	Encoders: cmds.EncoderMap{
 		cmds.Text: cmds.MakeEncoder(func(req *cmds.Request, w io.Writer, v interface{}) error {
@@ -94,21 +76,14 @@ Remove document specified by cid from index repository specified by path.
 				return e.TypeErr(repoPath, v)
 			}
 
-			_, err := fmt.Fprintf(w, "path %s \n", repoPath.path)
+			if quiet, _ := req.Options[quietOptionName].(bool); quiet {
+				_, err := fmt.Fprintf(w, "%s\n", repoPath.Path)
+				return err
+			}
+
+			_, err := fmt.Fprintf(w, "removed %s\n", repoPath.Path)
 			return err
 		}),
 	},
 	Type: RepoPath{},
 }
-
-type rmdocOpts struct {
-	q bool
-}
-
-func rmDoc(ctx context.Context, n *core.Dms3FsNode, ref path.Path, opts *rmdocOpts) (*RepoPath, error) {
-
-	return &RepoPath{
-		path: "rmdoc test worked.",
-	}, nil
-
-}
@@ -0,0 +1,93 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	cmdenv "github.com/dms3-fs/go-dms3-fs/core/commands/cmdenv"
+	e "github.com/dms3-fs/go-dms3-fs/core/commands/e"
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+
+	cmdkit "github.com/dms3-fs/go-fs-cmdkit"
+	cmds "github.com/dms3-fs/go-fs-cmds"
+)
+
+type ReposetStat struct {
+	Hash      string
+	Infoclass string
+	Kind      string
+	Name      string
+	CreatedAt string
+	MaxAreas  uint8
+	MaxCats   uint8
+	MaxDocs   uint64
+	Docs      uint64
+}
+
+var StatIndexCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show index repository properties.",
+		ShortDescription: `
+Show the properties of the index repository specified by path.
+`,
+		LongDescription: `
+Show the properties of the index repository specified by path, along
+with the number of documents added to it.
+`,
+	},
+
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("dms3fs-path", true, false, "repository to show."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) {
+		if len(req.Arguments) != 1 {
+			res.SetError(errors.New("path is required."), cmdkit.ErrNormal)
+			return
+		}
+
+		api, err := cmdenv.GetApi(env)
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
+
+		p, err := coreiface.ParsePath(req.Arguments[0])
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
+
+		st, err := api.Index().Stat(req.Context, p)
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
+
+		cmds.EmitOnce(res, &ReposetStat{
+			Hash:      st.Cid.String(),
+			Infoclass: st.Class,
+			Kind:      st.Kind,
+			Name:      st.Name,
+			CreatedAt: st.CreatedAt.Format(time.RFC3339),
+			MaxAreas:  st.MaxAreas,
+			MaxCats:   st.MaxCats,
+			MaxDocs:   st.MaxDocs,
+			Docs:      st.Docs,
+		})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeEncoder(func(req *cmds.Request, w io.Writer, v interface{}) error {
+			st, ok := v.(*ReposetStat)
+			if !ok {
+				return e.TypeErr(st, v)
+			}
+
+			_, err := fmt.Fprintf(w, "%s\nClass: %s\nKind: %s\nName: %s\nCreatedAt: %s\nMaxAreas: %d\nMaxCats: %d\nMaxDocs: %d\nDocs: %d\n",
+				st.Hash, st.Infoclass, st.Kind, st.Name, st.CreatedAt, st.MaxAreas, st.MaxCats, st.MaxDocs, st.Docs)
+			return err
+		}),
+	},
+	Type: ReposetStat{},
+}
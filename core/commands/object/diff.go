@@ -8,6 +8,7 @@ import (
 	cmds "github.com/dms3-fs/go-dms3-fs/commands"
 	e "github.com/dms3-fs/go-dms3-fs/core/commands/e"
 	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
 	"github.com/dms3-fs/go-dms3-fs/dagutils"
 
 	cmdkit "github.com/dms3-fs/go-fs-cmdkit"
@@ -42,6 +43,9 @@ Example:
    > OBJ_B=QmcmRptkSPWhptCttgHg27QNDmnV33wAJyUkCnAvqD3eCD
    > dms3fs object diff -v $OBJ_A $OBJ_B
    Changed "bar" from QmNgd5cz2jNftnAHBhcRUGdtiaMzb5Rhjqd4etondHHST8 to QmRfFVsjSXkhFxrfWnLpMae2M4GBVsry6VAuYYcji5MiZb.
+
+Changes are reported at the full path of the changed node, in objects of any
+format. Use '--depth' to stop descending into modified links at a given depth.
 `,
 	},
 	Arguments: []cmdkit.Argument{
@@ -50,6 +54,7 @@ Example:
 	},
 	Options: []cmdkit.Option{
 		cmdkit.BoolOption("verbose", "v", "Print extra information."),
+		cmdkit.IntOption("depth", "d", "Maximum depth of the reported changes, -1 for no limit.").WithDefault(-1),
 	},
 	Run: func(req cmds.Request, res cmds.Response) {
 		api, err := req.InvocContext().GetApi()
@@ -73,7 +78,17 @@ Example:
 			return
 		}
 
-		changes, err := api.Object().Diff(req.Context(), pa, pb)
+		depth, _, err := req.Option("depth").Int()
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
+
+		changes, err := api.Object().Diff(req.Context(), pa, pb, options.Object.DiffDepth(depth))
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
 
 		out := make([]*dagutils.Change, len(changes))
 		for i, change := range changes {
@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 	"time"
 
 	cmds "github.com/dms3-fs/go-dms3-fs/commands"
@@ -59,6 +61,10 @@ var addPinCmd = &cmds.Command{
 	Options: []cmdkit.Option{
 		cmdkit.BoolOption("recursive", "r", "Recursively pin the object linked to by the specified object(s).").WithDefault(true),
 		cmdkit.BoolOption("progress", "Show progress"),
+		cmdkit.StringOption("name", "n", "Name to give to the pin(s)."),
+		cmdkit.StringOption("meta", "m", "Metadata to attach to the pin(s), as comma-separated key=value pairs."),
+		cmdkit.StringOption("expire-in", "Duration after which the pin(s) expire and are removed by the daemon, e.g. \"72h\". With --owner, only the pin of the owner expires."),
+		cmdkit.StringOption("owner", "o", "Pin on behalf of this owner. The objects stay pinned until all their owners, and the pin without an owner if any, remove their pins."),
 	},
 	Type: AddPinOutput{},
 	Run: func(req cmds.Request, res cmds.Response) {
@@ -78,8 +84,39 @@ var addPinCmd = &cmds.Command{
 		}
 		showProgress, _, _ := req.Option("progress").Bool()
 
+		info, err := pinInfoOptions(req)
+		if err != nil {
+			res.SetError(err, cmdkit.ErrClient)
+			return
+		}
+
+		owner, _, err := req.Option("owner").String()
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
+		if owner != "" {
+			info.Owners = []string{owner}
+		}
+
+		expireIn, found, err := req.Option("expire-in").String()
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
+		if found {
+			d, err := time.ParseDuration(expireIn)
+			if err != nil || d < 0 {
+				res.SetError(fmt.Errorf("invalid expiry duration '%s'", expireIn), cmdkit.ErrClient)
+				return
+			}
+			if d > 0 {
+				info.Expires = time.Now().Add(d)
+			}
+		}
+
 		if !showProgress {
-			added, err := corerepo.Pin(n, req.Context(), req.Arguments(), recursive)
+			added, err := corerepo.PinWithInfo(n, req.Context(), req.Arguments(), recursive, info)
 			if err != nil {
 				res.SetError(err, cmdkit.ErrNormal)
 				return
@@ -99,7 +136,7 @@ var addPinCmd = &cmds.Command{
 		}
 		ch := make(chan pinResult, 1)
 		go func() {
-			added, err := corerepo.Pin(n, ctx, req.Arguments(), recursive)
+			added, err := corerepo.PinWithInfo(n, ctx, req.Arguments(), recursive, info)
 			ch <- pinResult{pins: added, err: err}
 		}()
 
@@ -176,6 +213,10 @@ var rmPinCmd = &cmds.Command{
 		ShortDescription: `
 Removes the pin from the given object allowing it to be garbage
 collected if needed. (By default, recursively. Use -r=false for direct pins.)
+
+With --owner, only the pin of the given owner is removed, and the object stays
+pinned as long as other owners pinned it, or it was also pinned without an
+owner. Without --owner, the object is unpinned for all its owners.
 `,
 	},
 
@@ -184,6 +225,7 @@ collected if needed. (By default, recursively. Use -r=false for direct pins.)
 	},
 	Options: []cmdkit.Option{
 		cmdkit.BoolOption("recursive", "r", "Recursively unpin the object linked to by the specified object(s).").WithDefault(true),
+		cmdkit.StringOption("owner", "o", "Only remove the pin of this owner."),
 	},
 	Type: PinOutput{},
 	Run: func(req cmds.Request, res cmds.Response) {
@@ -200,7 +242,13 @@ collected if needed. (By default, recursively. Use -r=false for direct pins.)
 			return
 		}
 
-		removed, err := corerepo.Unpin(n, req.Context(), req.Arguments(), recursive)
+		owner, _, err := req.Option("owner").String()
+		if err != nil {
+			res.SetError(err, cmdkit.ErrNormal)
+			return
+		}
+
+		removed, err := corerepo.UnpinOwner(n, req.Context(), req.Arguments(), recursive, owner)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
@@ -244,6 +292,9 @@ By default, all pinned objects are returned, but the '--type' flag or
 arguments can restrict that to a specific pin type or to some specific objects
 respectively.
 
+Use --name=<name> and --meta=<key>=<value>,... to only list the direct and
+recursive pins with the given name and metadata.
+
 Use --type=<type> to specify the type of pinned keys to list.
 Valid values are:
     * "direct": pin that specific object.
@@ -270,6 +321,27 @@ Example:
 	QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN direct
 	$ dms3fs pin ls QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN
 	QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN direct
+	# name the pin and attach metadata to it
+	$ dms3fs pin add --name=hello --meta=lang=en QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN
+	pinned QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN recursively
+	$ dms3fs pin ls --meta=lang=en
+	QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN recursive "hello" lang=en
+	# make the pin expire in three days
+	$ dms3fs pin add --expire-in=72h QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN
+	pinned QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN recursively
+	$ dms3fs pin ls
+	QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN recursive expires=2018-09-03T12:00:00Z
+	# pin it on behalf of two owners, it stays pinned until both unpin it,
+	# and it no longer expires since the owners pinned it without an expiry
+	$ dms3fs pin add --owner=app1 QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN
+	pinned QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN recursively
+	$ dms3fs pin add --owner=app2 QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN
+	pinned QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN recursively
+	$ dms3fs pin ls
+	QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN recursive owners=app1,app2
+	$ dms3fs pin rm --owner=app1 QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN
+	$ dms3fs pin ls
+	QmZULkCELmmk5XNfCgTnCyFgAVxBRBXyDHGGMVoLFLiXEN recursive owners=app2
 `,
 	},
 
@@ -279,6 +351,8 @@ Example:
 	Options: []cmdkit.Option{
 		cmdkit.StringOption("type", "t", "The type of pinned keys to list. Can be \"direct\", \"indirect\", \"recursive\", or \"all\".").WithDefault("all"),
 		cmdkit.BoolOption("quiet", "q", "Write just hashes of objects."),
+		cmdkit.StringOption("name", "n", "Only list the pins with this name."),
+		cmdkit.StringOption("meta", "m", "Only list the pins with this metadata, as comma-separated key=value pairs."),
 	},
 	Run: func(req cmds.Request, res cmds.Response) {
 		n, err := req.InvocContext().GetNode()
@@ -301,6 +375,12 @@ Example:
 			return
 		}
 
+		filter, err := pinInfoOptions(req)
+		if err != nil {
+			res.SetError(err, cmdkit.ErrClient)
+			return
+		}
+
 		var keys map[string]RefKeyObject
 
 		if len(req.Arguments()) > 0 {
@@ -311,9 +391,18 @@ Example:
 
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
-		} else {
-			res.SetOutput(&RefKeyList{Keys: keys})
+			return
 		}
+
+		if filter.Name != "" || len(filter.Meta) > 0 {
+			for k, v := range keys {
+				info := pin.Info{Name: v.Name, Meta: v.Meta}
+				if (v.Type != "direct" && v.Type != "recursive") || !info.Matches(filter.Name, filter.Meta) {
+					delete(keys, k)
+				}
+			}
+		}
+		res.SetOutput(&RefKeyList{Keys: keys})
 	},
 	Type: RefKeyList{},
 	Marshalers: cmds.MarshalerMap{
@@ -336,9 +425,28 @@ Example:
 			for k, v := range keys.Keys {
 				if quiet {
 					fmt.Fprintf(out, "%s\n", k)
-				} else {
-					fmt.Fprintf(out, "%s %s\n", k, v.Type)
+					continue
+				}
+
+				fmt.Fprintf(out, "%s %s", k, v.Type)
+				if v.Name != "" {
+					fmt.Fprintf(out, " %q", v.Name)
 				}
+				metaKeys := make([]string, 0, len(v.Meta))
+				for mk := range v.Meta {
+					metaKeys = append(metaKeys, mk)
+				}
+				sort.Strings(metaKeys)
+				for _, mk := range metaKeys {
+					fmt.Fprintf(out, " %s=%s", mk, v.Meta[mk])
+				}
+				if v.Expires != "" {
+					fmt.Fprintf(out, " expires=%s", v.Expires)
+				}
+				if len(v.Owners) > 0 {
+					fmt.Fprintf(out, " owners=%s", strings.Join(v.Owners, ","))
+				}
+				fmt.Fprintln(out)
 			}
 			return out, nil
 		},
@@ -494,7 +602,69 @@ var verifyPinCmd = &cmds.Command{
 }
 
 type RefKeyObject struct {
-	Type string
+	Type    string
+	Name    string            `json:",omitempty"`
+	Meta    map[string]string `json:",omitempty"`
+	Created string            `json:",omitempty"`
+	Expires string            `json:",omitempty"`
+	Owners  []string          `json:",omitempty"`
+}
+
+// refKeyObject returns the RefKeyObject of a pin, with the info of the pin
+// if it is direct or recursive and has one
+func refKeyObject(n *core.Dms3FsNode, c *cid.Cid, pinType string) RefKeyObject {
+	obj := RefKeyObject{Type: pinType}
+	if pinType != "direct" && pinType != "recursive" {
+		return obj
+	}
+
+	if info, ok := n.Pinning.Info(c); ok {
+		obj.Name = info.Name
+		obj.Meta = info.Meta
+		obj.Created = info.Created.Format(time.RFC3339)
+		if expiry := info.Expiry(); !expiry.IsZero() {
+			obj.Expires = expiry.Format(time.RFC3339)
+		}
+		obj.Owners = info.Owners
+	}
+	return obj
+}
+
+// pinInfoOptions returns the pin info given by the --name and --meta options
+func pinInfoOptions(req cmds.Request) (pin.Info, error) {
+	name, _, err := req.Option("name").String()
+	if err != nil {
+		return pin.Info{}, err
+	}
+
+	metaStr, _, err := req.Option("meta").String()
+	if err != nil {
+		return pin.Info{}, err
+	}
+
+	meta, err := parsePinMeta(metaStr)
+	if err != nil {
+		return pin.Info{}, err
+	}
+
+	return pin.Info{Name: name, Meta: meta}, nil
+}
+
+// parsePinMeta parses comma-separated key=value pairs
+func parsePinMeta(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	meta := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid pin metadata '%s', must be key=value", kv)
+		}
+		meta[parts[0]] = parts[1]
+	}
+	return meta, nil
 }
 
 type RefKeyList struct {
@@ -540,9 +710,7 @@ func pinLsKeys(ctx context.Context, args []string, typeStr string, n *core.Dms3F
 		default:
 			pinType = "indirect through " + pinType
 		}
-		keys[c.String()] = RefKeyObject{
-			Type: pinType,
-		}
+		keys[c.String()] = refKeyObject(n, c, pinType)
 	}
 
 	return keys, nil
@@ -554,9 +722,7 @@ func pinLsAll(ctx context.Context, typeStr string, n *core.Dms3FsNode) (map[stri
 
 	AddToResultKeys := func(keyList []*cid.Cid, typeStr string) {
 		for _, c := range keyList {
-			keys[c.String()] = RefKeyObject{
-				Type: typeStr,
-			}
+			keys[c.String()] = refKeyObject(n, c, typeStr)
 		}
 	}
 
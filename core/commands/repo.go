@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"strings"
 	"text/tabwriter"
 
@@ -17,10 +16,8 @@ import (
 	fsrepo "github.com/dms3-fs/go-dms3-fs/repo/fsrepo"
 
 	cid "github.com/dms3-fs/go-cid"
-	bstore "github.com/dms3-fs/go-fs-blockstore"
 	cmdkit "github.com/dms3-fs/go-fs-cmdkit"
 	cmds "github.com/dms3-fs/go-fs-cmds"
-	config "github.com/dms3-fs/go-fs-config"
 )
 
 type RepoVersion struct {
@@ -243,38 +240,12 @@ daemons are running.
 `,
 	},
 	Run: func(req oldcmds.Request, res oldcmds.Response) {
-		configRoot := req.InvocContext().ConfigRoot
-
-		dsPath, err := config.DataStorePath(configRoot)
+		err := corerepo.Fsck(req.InvocContext().ConfigRoot)
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
 		}
 
-		dsLockFile := filepath.Join(dsPath, "LOCK") // TODO: get this lockfile programmatically
-		repoLockFile := filepath.Join(configRoot, fsrepo.LockFile)
-		apiFile := filepath.Join(configRoot, "api") // TODO: get this programmatically
-
-		log.Infof("Removing repo lockfile: %s", repoLockFile)
-		log.Infof("Removing datastore lockfile: %s", dsLockFile)
-		log.Infof("Removing api file: %s", apiFile)
-
-		err = os.Remove(repoLockFile)
-		if err != nil && !os.IsNotExist(err) {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-		err = os.Remove(dsLockFile)
-		if err != nil && !os.IsNotExist(err) {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-		err = os.Remove(apiFile)
-		if err != nil && !os.IsNotExist(err) {
-			res.SetError(err, cmdkit.ErrNormal)
-			return
-		}
-
 		res.SetOutput(&MessageOutput{"Lockfiles have been removed.\n"})
 	},
 	Type: MessageOutput{},
@@ -286,6 +257,7 @@ daemons are running.
 type VerifyProgress struct {
 	Msg      string
 	Progress int
+	Cid      *cid.Cid `json:",omitempty"`
 }
 
 var repoVerifyCmd = &oldcmds.Command{
@@ -293,7 +265,7 @@ var repoVerifyCmd = &oldcmds.Command{
 		Tagline: "Verify all blocks in repo are not corrupted.",
 	},
 	Run: func(req oldcmds.Request, res oldcmds.Response) {
-		nd, err := req.InvocContext().GetNode()
+		api, err := req.InvocContext().GetApi()
 		if err != nil {
 			res.SetError(err, cmdkit.ErrNormal)
 			return
@@ -303,10 +275,7 @@ var repoVerifyCmd = &oldcmds.Command{
 		res.SetOutput((<-chan interface{})(out))
 		defer close(out)
 
-		bs := bstore.NewBlockstore(nd.Repo.Datastore())
-		bs.HashOnRead(true)
-
-		keys, err := bs.AllKeysChan(req.Context())
+		results, err := api.Repo().Verify(req.Context())
 		if err != nil {
 			log.Error(err)
 			return
@@ -314,12 +283,16 @@ var repoVerifyCmd = &oldcmds.Command{
 
 		var fails int
 		var i int
-		for k := range keys {
-			_, err := bs.Get(k)
-			if err != nil {
+		for r := range results {
+			if r.Err != nil {
+				res.SetError(r.Err, cmdkit.ErrNormal)
+				return
+			}
+			if r.Corrupt != nil {
 				select {
 				case out <- &VerifyProgress{
-					Msg: fmt.Sprintf("block %s was corrupt (%s)", k, err),
+					Msg: fmt.Sprintf("block %s was corrupt (%s)", r.Cid, r.Corrupt),
+					Cid: r.Cid,
 				}:
 				case <-req.Context().Done():
 					return
@@ -328,7 +301,7 @@ var repoVerifyCmd = &oldcmds.Command{
 			}
 			i++
 			select {
-			case out <- &VerifyProgress{Progress: i}:
+			case out <- &VerifyProgress{Progress: i, Cid: r.Cid}:
 			case <-req.Context().Done():
 				return
 			}
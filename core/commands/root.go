@@ -135,7 +135,7 @@ var rootSubcommands = map[string]*cmds.Command{
 			"publish": idx.PublishIndexCmd,
 
 			"ls": idx.ListIndexCmd,
-			"stat": idx.NotyetIndexCmd,
+			"stat": idx.StatIndexCmd,
 			"show": idx.NotyetIndexCmd,
 			"start": idx.NotyetIndexCmd,
 			"stop": idx.NotyetIndexCmd,
@@ -193,7 +193,7 @@ var rootROSubcommands = map[string]*cmds.Command{
 	"index": &cmds.Command{
 		Subcommands: map[string]*cmds.Command{
 			"ls": idx.ListIndexCmd,
-			"stat": idx.NotyetIndexCmd,
+			"stat": idx.StatIndexCmd,
 			"show": idx.NotyetIndexCmd,
 		},
 	},
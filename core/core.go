@@ -501,6 +501,7 @@ func (n *Dms3FsNode) startOnlineServicesWithHost(ctx context.Context, host p2pho
 	//    that requires a fair amount of work).
 	if dht, ok := r.(*dht.Dms3FsDHT); ok {
 		n.DHT = dht
+		n.Routing = &instrumentedRouting{dht}
 	}
 
 	if dms3nsps {
@@ -0,0 +1,131 @@
+package coreapi
+
+import (
+	"context"
+	"fmt"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	bitswap "github.com/dms3-fs/go-bitswap"
+	cid "github.com/dms3-fs/go-cid"
+	peer "github.com/dms3-p2p/go-p2p-peer"
+)
+
+type BitswapAPI CoreAPI
+
+// Wantlist returns the blocks currently on the wantlist of the local peer, or
+// of the peer set with the Peer option.
+func (api *BitswapAPI) Wantlist(ctx context.Context, opts ...caopts.BitswapWantlistOption) ([]*cid.Cid, error) {
+	settings, err := caopts.BitswapWantlistOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	bs, err := api.bitswap()
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.Peer == "" || settings.Peer == api.node.Identity {
+		return bs.GetWantlist(), nil
+	}
+
+	return bs.WantlistForPeer(settings.Peer), nil
+}
+
+// Unwant removes the objects referenced by the given paths from the wantlist.
+func (api *BitswapAPI) Unwant(ctx context.Context, paths ...coreiface.Path) error {
+	bs, err := api.bitswap()
+	if err != nil {
+		return err
+	}
+
+	ks := make([]*cid.Cid, 0, len(paths))
+	for _, p := range paths {
+		rp, err := api.core().ResolvePath(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		ks = append(ks, rp.Cid())
+	}
+
+	bs.CancelWants(ks, 0)
+	return nil
+}
+
+// Ledger returns the ledger the decision engine keeps for peer `p`.
+func (api *BitswapAPI) Ledger(ctx context.Context, p peer.ID) (*coreiface.BitswapLedger, error) {
+	bs, err := api.bitswap()
+	if err != nil {
+		return nil, err
+	}
+
+	r := bs.LedgerForPeer(p)
+	if r == nil {
+		return nil, fmt.Errorf("no ledger for peer %s", p.Pretty())
+	}
+
+	return &coreiface.BitswapLedger{
+		Peer:      r.Peer,
+		Value:     r.Value,
+		Sent:      r.Sent,
+		Recv:      r.Recv,
+		Exchanged: r.Exchanged,
+	}, nil
+}
+
+// Stat returns diagnostic information about the bitswap agent.
+func (api *BitswapAPI) Stat(ctx context.Context) (*coreiface.BitswapStat, error) {
+	bs, err := api.bitswap()
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := bs.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return &coreiface.BitswapStat{
+		ProvideBufLen:   st.ProvideBufLen,
+		Wantlist:        st.Wantlist,
+		Peers:           st.Peers,
+		BlocksReceived:  st.BlocksReceived,
+		DataReceived:    st.DataReceived,
+		BlocksSent:      st.BlocksSent,
+		DataSent:        st.DataSent,
+		DupBlksReceived: st.DupBlksReceived,
+		DupDataReceived: st.DupDataReceived,
+	}, nil
+}
+
+// Reprovide triggers the reprovider to announce the local data to the
+// network, and waits for it to be done.
+func (api *BitswapAPI) Reprovide(ctx context.Context) error {
+	if !api.node.OnlineMode() || api.node.Reprovider == nil {
+		return coreiface.ErrOffline
+	}
+
+	return api.node.Reprovider.Trigger(ctx)
+}
+
+// bitswap returns the bitswap exchange of the node, or ErrOffline when the
+// node is not online.
+func (api *BitswapAPI) bitswap() (*bitswap.Bitswap, error) {
+	if !api.node.OnlineMode() {
+		return nil, coreiface.ErrOffline
+	}
+
+	bs, ok := api.node.Exchange.(*bitswap.Bitswap)
+	if !ok {
+		return nil, fmt.Errorf("expected exchange to be bitswap, got %T", api.node.Exchange)
+	}
+
+	return bs, nil
+}
+
+func (api *BitswapAPI) core() coreiface.CoreAPI {
+	return (*CoreAPI)(api)
+}
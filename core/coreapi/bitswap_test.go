@@ -0,0 +1,68 @@
+package coreapi_test
+
+import (
+	"context"
+	"testing"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+)
+
+func TestBitswapOffline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = api.Bitswap().Stat(ctx)
+	if err != coreiface.ErrOffline {
+		t.Fatalf("expected ErrOffline, got: %v", err)
+	}
+}
+
+func TestBitswapStat(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nds, apis, err := makeAPISwarm(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := apis[0].Unixfs().Add(ctx, strFile("bitswap test")())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = apis[1].Block().Get(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := apis[1].Bitswap().Stat(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.BlocksReceived != 1 {
+		t.Errorf("expected 1 block received, got %d", st.BlocksReceived)
+	}
+
+	wl, err := apis[0].Bitswap().Wantlist(ctx, options.Bitswap.Peer(nds[1].Identity))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wl) != 0 {
+		t.Errorf("unexpected wantlist: %v", wl)
+	}
+
+	l, err := apis[0].Bitswap().Ledger(ctx, nds[1].Identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Sent == 0 {
+		t.Error("expected data to be sent to the peer")
+	}
+}
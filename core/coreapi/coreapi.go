@@ -67,3 +67,33 @@ func (api *CoreAPI) Object() coreiface.ObjectAPI {
 func (api *CoreAPI) Pin() coreiface.PinAPI {
 	return (*PinAPI)(api)
 }
+
+// Swarm returns the SwarmAPI interface implementation backed by the go-dms3fs node
+func (api *CoreAPI) Swarm() coreiface.SwarmAPI {
+	return (*SwarmAPI)(api)
+}
+
+// PubSub returns the PubSubAPI interface implementation backed by the go-dms3fs node
+func (api *CoreAPI) PubSub() coreiface.PubSubAPI {
+	return (*PubSubAPI)(api)
+}
+
+// Dht returns the DhtAPI interface implementation backed by the go-dms3fs node
+func (api *CoreAPI) Dht() coreiface.DhtAPI {
+	return (*DhtAPI)(api)
+}
+
+// Files returns the FilesAPI interface implementation backed by the go-dms3fs node
+func (api *CoreAPI) Files() coreiface.FilesAPI {
+	return (*FilesAPI)(api)
+}
+
+// Bitswap returns the BitswapAPI interface implementation backed by the go-dms3fs node
+func (api *CoreAPI) Bitswap() coreiface.BitswapAPI {
+	return (*BitswapAPI)(api)
+}
+
+// Repo returns the RepoAPI interface implementation backed by the go-dms3fs node
+func (api *CoreAPI) Repo() coreiface.RepoAPI {
+	return (*RepoAPI)(api)
+}
@@ -11,9 +11,11 @@ import (
 	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
 	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
 	coredag "github.com/dms3-fs/go-dms3-fs/core/coredag"
+	"github.com/dms3-fs/go-dms3-fs/dagutils"
 
 	cid "github.com/dms3-fs/go-cid"
 	dms3ld "github.com/dms3-fs/go-ld-format"
+	dag "github.com/dms3-fs/go-merkledag"
 )
 
 type DagAPI CoreAPI
@@ -46,6 +48,34 @@ func (api *DagAPI) Get(ctx context.Context, path coreiface.Path) (dms3ld.Node, e
 
 // Tree returns list of paths within a node specified by the path `p`.
 func (api *DagAPI) Tree(ctx context.Context, p coreiface.Path, opts ...caopts.DagTreeOption) ([]coreiface.Path, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	paths, err := api.TreeAsync(ctx, p, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []coreiface.Path{}
+	for r := range paths {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		out = append(out, r.Path)
+	}
+
+	// the channel is closed without an error when the context is cancelled
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// TreeAsync streams the paths within a node specified by the path `p`. The
+// paths are all listed by the node before the first is sent, which only costs
+// memory proportional to the size of its block.
+func (api *DagAPI) TreeAsync(ctx context.Context, p coreiface.Path, opts ...caopts.DagTreeOption) (<-chan coreiface.DagTreeResult, error) {
 	settings, err := caopts.DagTreeOptions(opts...)
 	if err != nil {
 		return nil, err
@@ -55,18 +85,125 @@ func (api *DagAPI) Tree(ctx context.Context, p coreiface.Path, opts ...caopts.Da
 	if err != nil {
 		return nil, err
 	}
-	paths := n.Tree("", settings.Depth)
-	out := make([]coreiface.Path, len(paths))
-	for n, p2 := range paths {
-		out[n], err = coreiface.ParsePath(gopath.Join(p.String(), p2))
-		if err != nil {
-			return nil, err
+
+	out := make(chan coreiface.DagTreeResult)
+	go func() {
+		defer close(out)
+
+		// only the conversion of the paths to Path is done lazily
+		for _, p2 := range n.Tree("", settings.Depth) {
+			var r coreiface.DagTreeResult
+			r.Path, r.Err = coreiface.ParsePath(gopath.Join(p.String(), p2))
+
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return
+			}
+
+			if r.Err != nil {
+				return
+			}
 		}
+	}()
+
+	return out, nil
+}
+
+// Diff returns the changes needed to transform the node at path `before` into
+// the node at path `after`.
+func (api *DagAPI) Diff(ctx context.Context, before coreiface.Path, after coreiface.Path, opts ...caopts.DagDiffOption) ([]coreiface.ObjectChange, error) {
+	settings, err := caopts.DagDiffOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeNd, err := api.core().ResolveNode(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterNd, err := api.core().ResolveNode(ctx, after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := dagutils.DiffDepth(ctx, api.node.DAG, beforeNd, afterNd, settings.Depth)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]coreiface.ObjectChange, len(changes))
+	for i, change := range changes {
+		out[i] = objectChange(change, coreiface.Dms3LdPath)
 	}
 
 	return out, nil
 }
 
+// Merge diffs the nodes at paths `a` and `b` against the node at path `base`,
+// and applies both sets of changes onto `base` unless they conflict.
+func (api *DagAPI) Merge(ctx context.Context, base coreiface.Path, a coreiface.Path, b coreiface.Path) (coreiface.ResolvedPath, []coreiface.DagConflict, error) {
+	baseNd, err := api.core().ResolveNode(ctx, base)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aNd, err := api.core().ResolveNode(ctx, a)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bNd, err := api.core().ResolveNode(ctx, b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	da, err := dagutils.Diff(ctx, api.node.DAG, baseNd, aNd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := dagutils.Diff(ctx, api.node.DAG, baseNd, bNd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changes, conflicts := dagutils.MergeDiffs(da, db)
+	if len(conflicts) > 0 {
+		out := make([]coreiface.DagConflict, len(conflicts))
+		for i, c := range conflicts {
+			out[i] = coreiface.DagConflict{
+				A: objectChange(c.A, coreiface.Dms3LdPath),
+				B: objectChange(c.B, coreiface.Dms3LdPath),
+			}
+		}
+		return nil, out, nil
+	}
+
+	if len(changes) == 0 {
+		return coreiface.Dms3LdPath(baseNd.Cid()), nil, nil
+	}
+
+	// a change of the base node itself conflicts with any other change, so
+	// it is the only one
+	if changes[0].Path == "" {
+		return coreiface.Dms3LdPath(changes[0].After), nil, nil
+	}
+
+	basepb, ok := baseNd.(*dag.ProtoNode)
+	if !ok {
+		return nil, nil, dag.ErrNotProtobuf
+	}
+
+	nd, err := dagutils.ApplyChange(ctx, api.node.DAG, basepb, changes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return coreiface.Dms3LdPath(nd.Cid()), nil, nil
+}
+
 // Batch creates new DagBatch
 func (api *DagAPI) Batch(ctx context.Context) coreiface.DagBatch {
 	return &dagBatch{api: api}
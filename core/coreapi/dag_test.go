@@ -6,8 +6,8 @@ import (
 	"strings"
 	"testing"
 
-	coreiface "github.com/dms3fs/go-dms3fs/core/coreapi/interface"
-	opt "github.com/dms3fs/go-dms3fs/core/coreapi/interface/options"
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	opt "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
 
 	mh "github.com/dms3-mft/go-multihash"
 )
@@ -149,3 +149,127 @@ func TestBatch(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestDagDiff(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	c1, err := api.Dag().Put(ctx, strings.NewReader(`{"x": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c2, err := api.Dag().Put(ctx, strings.NewReader(`{"x": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p1, err := api.Dag().Put(ctx, strings.NewReader(`{"k": 1, "c": {"/": "`+c1.Cid().String()+`"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p2, err := api.Dag().Put(ctx, strings.NewReader(`{"k": 1, "c": {"/": "`+c2.Cid().String()+`"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := api.Dag().Diff(ctx, p1, p2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 1 {
+		t.Fatalf("unexpected changes len: %d", len(changes))
+	}
+
+	if changes[0].Type != coreiface.DiffMod || changes[0].Path != "c" {
+		t.Fatalf("unexpected change: %+v", changes[0])
+	}
+
+	if changes[0].Before.Cid().String() != c1.Cid().String() || changes[0].After.Cid().String() != c2.Cid().String() {
+		t.Fatal("unexpected change paths")
+	}
+
+	changes, err = api.Dag().Diff(ctx, p1, p2, opt.Dag.DiffDepth(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 1 || changes[0].Path != "" {
+		t.Fatalf("unexpected changes at depth 0: %+v", changes)
+	}
+}
+
+func TestDagMerge(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	base, err := api.Object().New(ctx, opt.Object.Type("unixfs-dir"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	basep := coreiface.Dms3FsPath(base.Cid())
+
+	foo, err := api.Unixfs().Add(ctx, strFile("foo")())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bar, err := api.Unixfs().Add(ctx, strFile("bar")())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := api.Object().AddLink(ctx, basep, "a", foo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := api.Object().AddLink(ctx, basep, "b", bar)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged, conflicts, err := api.Dag().Merge(ctx, basep, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %+v", conflicts)
+	}
+
+	links, err := api.Object().Links(ctx, merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(links) != 2 {
+		t.Fatalf("unexpected links len: %d", len(links))
+	}
+
+	c, err := api.Object().AddLink(ctx, basep, "a", bar)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged, conflicts, err = api.Dag().Merge(ctx, basep, a, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if merged != nil || len(conflicts) != 1 {
+		t.Fatalf("expected a conflict, got %v and %+v", merged, conflicts)
+	}
+
+	if conflicts[0].A.Path != "a" || conflicts[0].B.Path != "a" {
+		t.Fatalf("unexpected conflict: %+v", conflicts[0])
+	}
+}
@@ -0,0 +1,159 @@
+package coreapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+	dag "github.com/dms3-fs/go-merkledag"
+
+	cid "github.com/dms3-fs/go-cid"
+	dms3ld "github.com/dms3-fs/go-ld-format"
+	peer "github.com/dms3-p2p/go-p2p-peer"
+	pstore "github.com/dms3-p2p/go-p2p-peerstore"
+	routing "github.com/dms3-p2p/go-p2p-routing"
+)
+
+type DhtAPI CoreAPI
+
+// FindPeer queries the routing system for the addresses of peer `p`.
+func (api *DhtAPI) FindPeer(ctx context.Context, p peer.ID) (pstore.PeerInfo, error) {
+	if err := api.checkOnline(); err != nil {
+		return pstore.PeerInfo{}, err
+	}
+
+	return api.node.Routing.FindPeer(ctx, p)
+}
+
+// FindProviders searches the routing system for peers providing the object
+// referenced by path `p`. The returned channel is closed once the search is
+// done or `ctx` is cancelled.
+func (api *DhtAPI) FindProviders(ctx context.Context, p coreiface.Path, opts ...caopts.DhtFindProvidersOption) (<-chan pstore.PeerInfo, error) {
+	settings, err := caopts.DhtFindProvidersOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := api.checkOnline(); err != nil {
+		return nil, err
+	}
+
+	rp, err := api.core().ResolvePath(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	numProviders := settings.NumProviders
+	if numProviders < 1 {
+		return nil, fmt.Errorf("number of providers must be greater than 0")
+	}
+
+	return api.node.Routing.FindProvidersAsync(ctx, rp.Cid(), numProviders), nil
+}
+
+// Provide announces to the network that this node can provide the object
+// referenced by path `p`. With the Recursive option, the whole graph under
+// the object is announced.
+func (api *DhtAPI) Provide(ctx context.Context, p coreiface.Path, opts ...caopts.DhtProvideOption) error {
+	settings, err := caopts.DhtProvideOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	if err := api.checkOnline(); err != nil {
+		return err
+	}
+
+	if len(api.node.PeerHost.Network().Conns()) == 0 {
+		return errors.New("cannot provide, no connected peers")
+	}
+
+	rp, err := api.core().ResolvePath(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	c := rp.Cid()
+
+	has, err := api.node.Blockstore.Has(c)
+	if err != nil {
+		return err
+	}
+
+	if !has {
+		return fmt.Errorf("block %s not found locally, cannot provide", c)
+	}
+
+	if settings.Recursive {
+		return provideKeysRec(ctx, api.node.Routing, api.node.DAG, []*cid.Cid{c})
+	}
+	return provideKeys(ctx, api.node.Routing, []*cid.Cid{c})
+}
+
+// GetValue searches the routing system for the value stored under `key`.
+func (api *DhtAPI) GetValue(ctx context.Context, key string) ([]byte, error) {
+	if err := api.checkOnline(); err != nil {
+		return nil, err
+	}
+
+	return api.node.Routing.GetValue(ctx, key)
+}
+
+// PutValue stores `value` under `key` in the routing system.
+func (api *DhtAPI) PutValue(ctx context.Context, key string, value []byte) error {
+	if err := api.checkOnline(); err != nil {
+		return err
+	}
+
+	return api.node.Routing.PutValue(ctx, key, value)
+}
+
+func provideKeys(ctx context.Context, r routing.Dms3FsRouting, cids []*cid.Cid) error {
+	for _, c := range cids {
+		err := r.Provide(ctx, c, true)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func provideKeysRec(ctx context.Context, r routing.Dms3FsRouting, dserv dms3ld.DAGService, cids []*cid.Cid) error {
+	provided := cid.NewSet()
+	for _, c := range cids {
+		kset := cid.NewSet()
+
+		err := dag.EnumerateChildrenAsync(ctx, dag.GetLinksDirect(dserv), c, kset.Visit)
+		if err != nil {
+			return err
+		}
+
+		for _, k := range kset.Keys() {
+			if provided.Has(k) {
+				continue
+			}
+
+			err = r.Provide(ctx, k, true)
+			if err != nil {
+				return err
+			}
+			provided.Add(k)
+		}
+	}
+
+	return nil
+}
+
+func (api *DhtAPI) checkOnline() error {
+	if !api.node.OnlineMode() || api.node.Routing == nil {
+		return coreiface.ErrOffline
+	}
+
+	return nil
+}
+
+func (api *DhtAPI) core() coreiface.CoreAPI {
+	return (*CoreAPI)(api)
+}
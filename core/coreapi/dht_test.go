@@ -0,0 +1,137 @@
+package coreapi_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	blocks "github.com/dms3-fs/go-block-format"
+)
+
+func TestDhtFindPeer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nds, apis, err := makeAPISwarm(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pi, err := apis[2].Dht().FindPeer(ctx, nds[0].Identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pi.ID != nds[0].Identity {
+		t.Errorf("expected peer %s, got %s", nds[0].Identity.Pretty(), pi.ID.Pretty())
+	}
+
+	if len(pi.Addrs) == 0 {
+		t.Error("expected peer to have addresses")
+	}
+}
+
+func TestDhtFindProviders(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nds, apis, err := makeAPISwarm(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := apis[0].Unixfs().Add(ctx, strFile("dht test")())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = apis[0].Dht().Provide(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := apis[2].Dht().FindProviders(ctx, p, options.Dht.NumProviders(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := <-out
+
+	if provider.ID != nds[0].Identity {
+		t.Errorf("expected provider %s, got %s", nds[0].Identity.Pretty(), provider.ID.Pretty())
+	}
+
+	_, err = apis[2].Dht().FindProviders(ctx, p, options.Dht.NumProviders(0))
+	if err == nil {
+		t.Error("expected error for zero providers")
+	}
+}
+
+func TestDhtProvideNotLocal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, apis, err := makeAPISwarm(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blk := blocks.NewBlock([]byte("not stored locally"))
+
+	err = apis[0].Dht().Provide(ctx, coreiface.Dms3LdPath(blk.Cid()), options.Dht.Recursive(true))
+	if err == nil {
+		t.Fatal("expected error providing a block which isn't stored locally")
+	}
+
+	if !strings.Contains(err.Error(), "not found locally") {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestDhtPutGetValue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nds, apis, err := makeAPISwarm(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkb, err := nds[0].PrivateKey.GetPublic().Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "/pk/" + string(nds[0].Identity)
+
+	err = apis[0].Dht().PutValue(ctx, key, pkb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	val, err := apis[3].Dht().GetValue(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(val, pkb) {
+		t.Error("got unexpected value")
+	}
+}
+
+func TestDhtOffline(t *testing.T) {
+	ctx := context.Background()
+	n, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = api.Dht().FindPeer(ctx, n.Identity)
+	if err != coreiface.ErrOffline {
+		t.Errorf("expected ErrOffline, got %v", err)
+	}
+}
@@ -0,0 +1,669 @@
+package coreapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	gopath "path"
+	"strings"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	bservice "github.com/dms3-fs/go-blockservice"
+	cid "github.com/dms3-fs/go-cid"
+	offline "github.com/dms3-fs/go-fs-exchange-offline"
+	dms3ld "github.com/dms3-fs/go-ld-format"
+	dag "github.com/dms3-fs/go-merkledag"
+	mfs "github.com/dms3-fs/go-mfs"
+	ft "github.com/dms3-fs/go-unixfs"
+)
+
+type FilesAPI CoreAPI
+
+type filesReader struct {
+	io.Reader
+	io.Closer
+}
+
+type contextReader interface {
+	CtxReadFull(context.Context, []byte) (int, error)
+}
+
+type contextReaderWrapper struct {
+	R   contextReader
+	ctx context.Context
+}
+
+func (crw *contextReaderWrapper) Read(b []byte) (int, error) {
+	return crw.R.CtxReadFull(crw.ctx, b)
+}
+
+// Read opens the file at path `p` for reading. Reads are bound to `ctx`.
+func (api *FilesAPI) Read(ctx context.Context, p string, opts ...caopts.FilesReadOption) (io.ReadCloser, error) {
+	settings, err := caopts.FilesReadOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := checkPath(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.Offset < 0 {
+		return nil, fmt.Errorf("cannot specify negative offset")
+	}
+
+	fsn, err := mfs.Lookup(api.node.FilesRoot, path)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, ok := fsn.(*mfs.File)
+	if !ok {
+		return nil, fmt.Errorf("%s was not a file", path)
+	}
+
+	rfd, err := fi.Open(mfs.OpenReadOnly, false)
+	if err != nil {
+		return nil, err
+	}
+
+	filen, err := rfd.Size()
+	if err != nil {
+		rfd.Close()
+		return nil, err
+	}
+
+	if settings.Offset > filen {
+		rfd.Close()
+		return nil, fmt.Errorf("offset was past end of file (%d > %d)", settings.Offset, filen)
+	}
+
+	_, err = rfd.Seek(settings.Offset, io.SeekStart)
+	if err != nil {
+		rfd.Close()
+		return nil, err
+	}
+
+	var r io.Reader = &contextReaderWrapper{R: rfd, ctx: ctx}
+	if settings.Count >= 0 {
+		r = io.LimitReader(r, settings.Count)
+	}
+
+	return &filesReader{Reader: r, Closer: rfd}, nil
+}
+
+// Write writes the data from `r` to the file at path `p`.
+func (api *FilesAPI) Write(ctx context.Context, p string, r io.Reader, opts ...caopts.FilesWriteOption) (err error) {
+	settings, err := caopts.FilesWriteOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	path, err := checkPath(p)
+	if err != nil {
+		return err
+	}
+
+	if settings.Offset < 0 {
+		return fmt.Errorf("cannot have negative write offset")
+	}
+
+	prefix, err := cidBuilder(settings.CidVersion, settings.MhType)
+	if err != nil {
+		return err
+	}
+
+	if settings.Parents {
+		err := ensureContainingDirectoryExists(api.node.FilesRoot, path, prefix)
+		if err != nil {
+			return err
+		}
+	}
+
+	fi, err := getFileHandle(api.node.FilesRoot, path, settings.Create, prefix)
+	if err != nil {
+		return err
+	}
+	if settings.RawLeavesSet {
+		fi.RawLeaves = settings.RawLeaves
+	}
+
+	wfd, err := fi.Open(mfs.OpenWriteOnly, settings.Flush)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		cerr := wfd.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	if settings.Truncate {
+		if err := wfd.Truncate(0); err != nil {
+			return err
+		}
+	}
+
+	_, err = wfd.Seek(settings.Offset, io.SeekStart)
+	if err != nil {
+		log.Error("seekfail: ", err)
+		return err
+	}
+
+	if settings.Count >= 0 {
+		r = io.LimitReader(r, settings.Count)
+	}
+
+	_, err = io.Copy(wfd, r)
+	return err
+}
+
+// Mkdir creates the directory at path `p`.
+func (api *FilesAPI) Mkdir(ctx context.Context, p string, opts ...caopts.FilesMkdirOption) error {
+	settings, err := caopts.FilesMkdirOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	dirtomake, err := checkPath(p)
+	if err != nil {
+		return err
+	}
+
+	prefix, err := cidBuilder(settings.CidVersion, settings.MhType)
+	if err != nil {
+		return err
+	}
+
+	return mfs.Mkdir(api.node.FilesRoot, dirtomake, mfs.MkdirOpts{
+		Mkparents:  settings.Parents,
+		Flush:      settings.Flush,
+		CidBuilder: prefix,
+	})
+}
+
+// Mv moves the file or directory at `src` to `dst`.
+func (api *FilesAPI) Mv(ctx context.Context, src string, dst string) error {
+	src, err := checkPath(src)
+	if err != nil {
+		return err
+	}
+
+	dst, err = checkPath(dst)
+	if err != nil {
+		return err
+	}
+
+	return mfs.Mv(api.node.FilesRoot, src, dst)
+}
+
+// Cp copies the object at `src`, which is either an MFS path or an /dms3fs/
+// path, to the MFS path `dst`.
+func (api *FilesAPI) Cp(ctx context.Context, src string, dst string, opts ...caopts.FilesCpOption) error {
+	settings, err := caopts.FilesCpOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	src, err = checkPath(src)
+	if err != nil {
+		return err
+	}
+	src = strings.TrimRight(src, "/")
+
+	dst, err = checkPath(dst)
+	if err != nil {
+		return err
+	}
+
+	if dst[len(dst)-1] == '/' {
+		dst += gopath.Base(src)
+	}
+
+	nd, err := api.getNodeFromPath(ctx, api.node.DAG, src)
+	if err != nil {
+		return fmt.Errorf("cp: cannot get node from path %s: %s", src, err)
+	}
+
+	err = mfs.PutNode(api.node.FilesRoot, dst, nd)
+	if err != nil {
+		return fmt.Errorf("cp: cannot put node in path %s: %s", dst, err)
+	}
+
+	if settings.Flush {
+		return flushPath(api.node.FilesRoot, dst)
+	}
+
+	return nil
+}
+
+// Ls lists the directory at path `p`. If `p` points to a file, the listing
+// only contains the file itself.
+func (api *FilesAPI) Ls(ctx context.Context, p string, opts ...caopts.FilesLsOption) ([]coreiface.FilesEntry, error) {
+	settings, err := caopts.FilesLsOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := checkPath(p)
+	if err != nil {
+		return nil, err
+	}
+
+	fsn, err := mfs.Lookup(api.node.FilesRoot, path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch fsn := fsn.(type) {
+	case *mfs.Directory:
+		if !settings.Long {
+			names, err := fsn.ListNames(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			out := make([]coreiface.FilesEntry, len(names))
+			for i, name := range names {
+				out[i] = coreiface.FilesEntry{Name: name}
+			}
+			return out, nil
+		}
+
+		listing, err := fsn.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		out := make([]coreiface.FilesEntry, len(listing))
+		for i, l := range listing {
+			out[i] = coreiface.FilesEntry{
+				Name: l.Name,
+				Type: l.Type,
+				Size: l.Size,
+				Hash: l.Hash,
+			}
+		}
+		return out, nil
+	case *mfs.File:
+		_, name := gopath.Split(path)
+		out := []coreiface.FilesEntry{{Name: name}}
+		if settings.Long {
+			out[0].Type = int(fsn.Type())
+
+			size, err := fsn.Size()
+			if err != nil {
+				return nil, err
+			}
+			out[0].Size = size
+
+			nd, err := fsn.GetNode()
+			if err != nil {
+				return nil, err
+			}
+			out[0].Hash = nd.Cid().String()
+		}
+		return out, nil
+	default:
+		return nil, errors.New("unrecognized type")
+	}
+}
+
+// Stat returns information about the node at path `p`, which is either an
+// MFS path or an /dms3fs/ path.
+func (api *FilesAPI) Stat(ctx context.Context, p string, opts ...caopts.FilesStatOption) (*coreiface.FilesStat, error) {
+	settings, err := caopts.FilesStatOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := checkPath(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var dagserv dms3ld.DAGService
+	if settings.WithLocal {
+		// an offline DAGService will not fetch from the network
+		dagserv = dag.NewDAGService(bservice.New(
+			api.node.Blockstore,
+			offline.Exchange(api.node.Blockstore),
+		))
+	} else {
+		dagserv = api.node.DAG
+	}
+
+	nd, err := api.getNodeFromPath(ctx, dagserv, path)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := statNode(nd)
+	if err != nil {
+		return nil, err
+	}
+
+	if !settings.WithLocal {
+		return o, nil
+	}
+
+	local, sizeLocal, err := walkBlock(ctx, dagserv, nd)
+	if err != nil {
+		return nil, err
+	}
+
+	o.WithLocality = true
+	o.Local = local
+	o.SizeLocal = sizeLocal
+
+	return o, nil
+}
+
+// Rm removes the file at path `p`. Directories are only removed with the
+// Recursive option.
+func (api *FilesAPI) Rm(ctx context.Context, p string, opts ...caopts.FilesRmOption) error {
+	settings, err := caopts.FilesRmOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	path, err := checkPath(p)
+	if err != nil {
+		return err
+	}
+
+	if path == "/" {
+		return fmt.Errorf("cannot delete root")
+	}
+
+	// 'rm a/b/c/' will fail unless we trim the slash at the end
+	if path[len(path)-1] == '/' {
+		path = path[:len(path)-1]
+	}
+
+	dir, name := gopath.Split(path)
+	parent, err := mfs.Lookup(api.node.FilesRoot, dir)
+	if err != nil {
+		return fmt.Errorf("parent lookup: %s", err)
+	}
+
+	pdir, ok := parent.(*mfs.Directory)
+	if !ok {
+		return fmt.Errorf("no such file or directory: %s", path)
+	}
+
+	// if recursive, don't check file type (in bad scenarios, the block may not exist)
+	if !settings.Recursive {
+		childi, err := pdir.Child(name)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := childi.(*mfs.Directory); ok {
+			return fmt.Errorf("%s is a directory, use -r to remove directories", path)
+		}
+	}
+
+	err = pdir.Unlink(name)
+	if err != nil {
+		return err
+	}
+
+	return pdir.Flush()
+}
+
+// Flush flushes the data under path `p` to disk.
+func (api *FilesAPI) Flush(ctx context.Context, p string) error {
+	return flushPath(api.node.FilesRoot, p)
+}
+
+// Chcid changes the CID version or hash function of the directory at path `p`.
+func (api *FilesAPI) Chcid(ctx context.Context, p string, opts ...caopts.FilesChcidOption) error {
+	settings, err := caopts.FilesChcidOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	prefix, err := cidBuilder(settings.CidVersion, settings.MhType)
+	if err != nil {
+		return err
+	}
+
+	return updatePath(api.node.FilesRoot, p, prefix, settings.Flush)
+}
+
+func (api *FilesAPI) getNodeFromPath(ctx context.Context, dagservice dms3ld.DAGService, p string) (dms3ld.Node, error) {
+	switch {
+	case strings.HasPrefix(p, "/dms3fs/"):
+		np, err := coreiface.ParsePath(p)
+		if err != nil {
+			return nil, err
+		}
+
+		return resolveNode(ctx, dagservice, api.node.Namesys, np)
+	default:
+		fsn, err := mfs.Lookup(api.node.FilesRoot, p)
+		if err != nil {
+			return nil, err
+		}
+
+		return fsn.GetNode()
+	}
+}
+
+func statNode(nd dms3ld.Node) (*coreiface.FilesStat, error) {
+	c := nd.Cid()
+
+	cumulsize, err := nd.Size()
+	if err != nil {
+		return nil, err
+	}
+
+	switch n := nd.(type) {
+	case *dag.ProtoNode:
+		d, err := ft.FromBytes(n.Data())
+		if err != nil {
+			return nil, err
+		}
+
+		var ndtype string
+		switch d.GetType() {
+		case ft.TDirectory, ft.THAMTShard:
+			ndtype = "directory"
+		case ft.TFile, ft.TMetadata, ft.TRaw:
+			ndtype = "file"
+		default:
+			return nil, fmt.Errorf("unrecognized node type: %s", d.GetType())
+		}
+
+		return &coreiface.FilesStat{
+			Cid:            c,
+			Blocks:         len(nd.Links()),
+			Size:           d.GetFilesize(),
+			CumulativeSize: cumulsize,
+			Type:           ndtype,
+		}, nil
+	case *dag.RawNode:
+		return &coreiface.FilesStat{
+			Cid:            c,
+			Blocks:         0,
+			Size:           cumulsize,
+			CumulativeSize: cumulsize,
+			Type:           "file",
+		}, nil
+	default:
+		return nil, fmt.Errorf("not unixfs node (proto or raw)")
+	}
+}
+
+func walkBlock(ctx context.Context, dagserv dms3ld.DAGService, nd dms3ld.Node) (bool, uint64, error) {
+	// Start with the block data size
+	sizeLocal := uint64(len(nd.RawData()))
+
+	local := true
+
+	for _, link := range nd.Links() {
+		child, err := dagserv.Get(ctx, link.Cid)
+
+		if err == dms3ld.ErrNotFound {
+			local = false
+			continue
+		}
+
+		if err != nil {
+			return local, sizeLocal, err
+		}
+
+		childLocal, childLocalSize, err := walkBlock(ctx, dagserv, child)
+
+		if err != nil {
+			return local, sizeLocal, err
+		}
+
+		// Recursively add the child size
+		local = local && childLocal
+		sizeLocal += childLocalSize
+	}
+
+	return local, sizeLocal, nil
+}
+
+// cidBuilder returns the builder for the given CID version and multihash
+// type, or nil if neither is set. Setting the hash function implies CIDv1.
+func cidBuilder(cidVer int, mhType uint64) (cid.Builder, error) {
+	hashFunSet := mhType != math.MaxUint64
+
+	if cidVer < 0 && !hashFunSet {
+		return nil, nil
+	}
+
+	if hashFunSet && cidVer <= 0 {
+		cidVer = 1
+	}
+
+	prefix, err := dag.PrefixForCidVersion(cidVer)
+	if err != nil {
+		return nil, err
+	}
+
+	if hashFunSet {
+		prefix.MhType = mhType
+		prefix.MhLength = -1
+	}
+
+	return &prefix, nil
+}
+
+func updatePath(rt *mfs.Root, pth string, builder cid.Builder, flush bool) error {
+	if builder == nil {
+		return nil
+	}
+
+	nd, err := mfs.Lookup(rt, pth)
+	if err != nil {
+		return err
+	}
+
+	switch n := nd.(type) {
+	case *mfs.Directory:
+		n.SetCidBuilder(builder)
+	default:
+		return fmt.Errorf("can only update directories")
+	}
+
+	if flush {
+		nd.Flush()
+	}
+
+	return nil
+}
+
+func ensureContainingDirectoryExists(r *mfs.Root, path string, builder cid.Builder) error {
+	dirtomake := gopath.Dir(path)
+
+	if dirtomake == "/" {
+		return nil
+	}
+
+	return mfs.Mkdir(r, dirtomake, mfs.MkdirOpts{
+		Mkparents:  true,
+		CidBuilder: builder,
+	})
+}
+
+func getFileHandle(r *mfs.Root, path string, create bool, builder cid.Builder) (*mfs.File, error) {
+	target, err := mfs.Lookup(r, path)
+	switch err {
+	case nil:
+		fi, ok := target.(*mfs.File)
+		if !ok {
+			return nil, fmt.Errorf("%s was not a file", path)
+		}
+		return fi, nil
+
+	case os.ErrNotExist:
+		if !create {
+			return nil, err
+		}
+
+		// if create is specified and the file doesnt exist, we create the file
+		dirname, fname := gopath.Split(path)
+		pdiri, err := mfs.Lookup(r, dirname)
+		if err != nil {
+			log.Error("lookupfail ", dirname)
+			return nil, err
+		}
+		pdir, ok := pdiri.(*mfs.Directory)
+		if !ok {
+			return nil, fmt.Errorf("%s was not a directory", dirname)
+		}
+		if builder == nil {
+			builder = pdir.GetCidBuilder()
+		}
+
+		nd := dag.NodeWithData(ft.FilePBData(nil, 0))
+		nd.SetCidBuilder(builder)
+		err = pdir.AddChild(fname, nd)
+		if err != nil {
+			return nil, err
+		}
+
+		fsn, err := pdir.Child(fname)
+		if err != nil {
+			return nil, err
+		}
+
+		fi, ok := fsn.(*mfs.File)
+		if !ok {
+			return nil, errors.New("expected *mfs.File, didnt get it. This is likely a race condition")
+		}
+		return fi, nil
+
+	default:
+		return nil, err
+	}
+}
+
+func checkPath(p string) (string, error) {
+	if len(p) == 0 {
+		return "", fmt.Errorf("paths must not be empty")
+	}
+
+	if p[0] != '/' {
+		return "", fmt.Errorf("paths must start with a leading slash")
+	}
+
+	cleaned := gopath.Clean(p)
+	if p[len(p)-1] == '/' && p != "/" {
+		cleaned += "/"
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,228 @@
+package coreapi_test
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	mh "github.com/dms3-mft/go-multihash"
+)
+
+func TestFilesMkdirWriteRead(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Files().Mkdir(ctx, "/a/b/c")
+	if err == nil {
+		t.Fatal("expected mkdir without parents to fail")
+	}
+
+	err = api.Files().Mkdir(ctx, "/a/b/c", options.Files.Mkdir.Parents(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Files().Write(ctx, "/a/b/c/foo", strings.NewReader("hello world"))
+	if err == nil {
+		t.Fatal("expected write to a missing file without create to fail")
+	}
+
+	err = api.Files().Write(ctx, "/a/b/c/foo", strings.NewReader("hello world"), options.Files.Write.Create(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := api.Files().Read(ctx, "/a/b/c/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "hello world" {
+		t.Errorf("expected 'hello world', got '%s'", string(data))
+	}
+
+	r, err = api.Files().Read(ctx, "/a/b/c/foo", options.Files.Read.Offset(6), options.Files.Read.Count(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	data, err = ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "wor" {
+		t.Errorf("expected 'wor', got '%s'", string(data))
+	}
+
+	_, err = api.Files().Read(ctx, "/a/b/c/foo", options.Files.Read.Offset(100))
+	if err == nil {
+		t.Error("expected reading past the end of the file to fail")
+	}
+}
+
+func TestFilesLsStat(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Files().Write(ctx, "/dir/foo", strings.NewReader("hello"), options.Files.Write.Create(true), options.Files.Write.Parents(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := api.Files().Ls(ctx, "/dir", options.Files.Ls.Long(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+
+	if entries[0].Name != "foo" {
+		t.Errorf("expected entry 'foo', got '%s'", entries[0].Name)
+	}
+
+	if entries[0].Size != 5 {
+		t.Errorf("expected size 5, got %d", entries[0].Size)
+	}
+
+	st, err := api.Files().Stat(ctx, "/dir/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if st.Type != "file" {
+		t.Errorf("expected type 'file', got '%s'", st.Type)
+	}
+
+	if st.Size != 5 {
+		t.Errorf("expected size 5, got %d", st.Size)
+	}
+
+	if st.Cid.String() != entries[0].Hash {
+		t.Errorf("expected cid %s, got %s", entries[0].Hash, st.Cid.String())
+	}
+
+	st, err = api.Files().Stat(ctx, "/dir", options.Files.Stat.WithLocal(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if st.Type != "directory" {
+		t.Errorf("expected type 'directory', got '%s'", st.Type)
+	}
+
+	if !st.WithLocality || !st.Local {
+		t.Error("expected directory to be fully local")
+	}
+}
+
+func TestFilesMvCpRm(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := api.Unixfs().Add(ctx, strFile("hello")())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Files().Mkdir(ctx, "/dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Files().Cp(ctx, p.String(), "/dir/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Files().Mv(ctx, "/dir/"+p.Cid().String(), "/dir/bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := api.Files().Stat(ctx, "/dir/bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if st.Cid.String() != p.Cid().String() {
+		t.Errorf("expected cid %s, got %s", p.Cid().String(), st.Cid.String())
+	}
+
+	err = api.Files().Rm(ctx, "/dir")
+	if err == nil {
+		t.Fatal("expected removing a directory without recursive to fail")
+	}
+
+	err = api.Files().Rm(ctx, "/dir", options.Files.Rm.Recursive(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := api.Files().Ls(ctx, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 0 {
+		t.Errorf("expected empty root, got %d entries", len(entries))
+	}
+
+	err = api.Files().Rm(ctx, "/", options.Files.Rm.Recursive(true))
+	if err == nil {
+		t.Error("expected removing root to fail")
+	}
+}
+
+func TestFilesChcid(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Files().Mkdir(ctx, "/dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Files().Chcid(ctx, "/dir", options.Files.Chcid.Hash(mh.SHA2_512))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := api.Files().Stat(ctx, "/dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pref := st.Cid.Prefix()
+	if pref.Version != 1 {
+		t.Errorf("expected cid version 1, got %d", pref.Version)
+	}
+
+	if pref.MhType != mh.SHA2_512 {
+		t.Errorf("expected hash %d, got %d", mh.SHA2_512, pref.MhType)
+	}
+}
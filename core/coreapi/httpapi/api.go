@@ -0,0 +1,130 @@
+// Package httpapi implements the CoreAPI interfaces on top of the HTTP API of
+// a running daemon, as served by corehttp.CommandsOption. It allows the same
+// code to run against an embedded node or a remote one.
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+
+	logging "github.com/dms3-fs/go-log"
+	ma "github.com/dms3-mft/go-multiaddr"
+	manet "github.com/dms3-mft/go-multiaddr-net"
+)
+
+var log = logging.Logger("coreapi/httpapi")
+
+// apiPath is the path at which the daemon mounts the API
+const apiPath = "/api/v0"
+
+// HttpApi implements coreiface.CoreAPI using the HTTP API of a daemon
+type HttpApi struct {
+	url     string
+	httpcli *http.Client
+}
+
+// NewApi constructs new HttpApi talking to the API listening on the given
+// multiaddr
+func NewApi(a ma.Multiaddr) (*HttpApi, error) {
+	_, host, err := manet.DialArgs(a)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewURLApiWithClient(host, http.DefaultClient), nil
+}
+
+// NewURLApiWithClient constructs new HttpApi talking to the API at the given
+// address, ex: "127.0.0.1:5001" or "http://127.0.0.1:5001", using the given
+// http client
+func NewURLApiWithClient(url string, c *http.Client) *HttpApi {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = "http://" + url
+	}
+
+	return &HttpApi{
+		url:     strings.TrimSuffix(url, "/") + apiPath,
+		httpcli: c,
+	}
+}
+
+// Unixfs returns the UnixfsAPI interface implementation backed by the daemon
+func (api *HttpApi) Unixfs() coreiface.UnixfsAPI {
+	return (*UnixfsAPI)(api)
+}
+
+// Index returns the IndexAPI interface implementation backed by the daemon
+func (api *HttpApi) Index() coreiface.IndexAPI {
+	return (*IndexAPI)(api)
+}
+
+// Block returns the BlockAPI interface implementation backed by the daemon
+func (api *HttpApi) Block() coreiface.BlockAPI {
+	return (*BlockAPI)(api)
+}
+
+// Dag returns the DagAPI interface implementation backed by the daemon
+func (api *HttpApi) Dag() coreiface.DagAPI {
+	return (*DagAPI)(api)
+}
+
+// Name returns the NameAPI interface implementation backed by the daemon
+func (api *HttpApi) Name() coreiface.NameAPI {
+	return (*NameAPI)(api)
+}
+
+// Key returns the KeyAPI interface implementation backed by the daemon
+func (api *HttpApi) Key() coreiface.KeyAPI {
+	return (*KeyAPI)(api)
+}
+
+// Pin returns the PinAPI interface implementation backed by the daemon
+func (api *HttpApi) Pin() coreiface.PinAPI {
+	return (*PinAPI)(api)
+}
+
+// Object returns the ObjectAPI interface implementation backed by the daemon
+func (api *HttpApi) Object() coreiface.ObjectAPI {
+	return (*ObjectAPI)(api)
+}
+
+// Swarm returns the SwarmAPI interface implementation backed by the daemon
+func (api *HttpApi) Swarm() coreiface.SwarmAPI {
+	return (*SwarmAPI)(api)
+}
+
+// PubSub returns the PubSubAPI interface implementation backed by the daemon
+func (api *HttpApi) PubSub() coreiface.PubSubAPI {
+	return (*PubSubAPI)(api)
+}
+
+// Dht returns the DhtAPI interface implementation backed by the daemon
+func (api *HttpApi) Dht() coreiface.DhtAPI {
+	return (*DhtAPI)(api)
+}
+
+// Files returns the FilesAPI interface implementation backed by the daemon
+func (api *HttpApi) Files() coreiface.FilesAPI {
+	return (*FilesAPI)(api)
+}
+
+// Bitswap returns the BitswapAPI interface implementation backed by the daemon
+func (api *HttpApi) Bitswap() coreiface.BitswapAPI {
+	return (*BitswapAPI)(api)
+}
+
+// Repo returns the RepoAPI interface implementation backed by the daemon
+func (api *HttpApi) Repo() coreiface.RepoAPI {
+	return (*RepoAPI)(api)
+}
+
+// request starts building a request to the given API command
+func (api *HttpApi) request(command string, args ...string) *requestBuilder {
+	return &requestBuilder{
+		command: command,
+		args:    args,
+		api:     api,
+	}
+}
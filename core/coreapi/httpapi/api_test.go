@@ -0,0 +1,348 @@
+package httpapi_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+
+	oldcmds "github.com/dms3-fs/go-dms3-fs/commands"
+	core "github.com/dms3-fs/go-dms3-fs/core"
+	coreapi "github.com/dms3-fs/go-dms3-fs/core/coreapi"
+	httpapi "github.com/dms3-fs/go-dms3-fs/core/coreapi/httpapi"
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+	corehttp "github.com/dms3-fs/go-dms3-fs/core/corehttp"
+	keystore "github.com/dms3-fs/go-dms3-fs/keystore"
+	repo "github.com/dms3-fs/go-dms3-fs/repo"
+
+	datastore "github.com/dms3-fs/go-datastore"
+	syncds "github.com/dms3-fs/go-datastore/sync"
+	files "github.com/dms3-fs/go-fs-cmdkit/files"
+	config "github.com/dms3-fs/go-fs-config"
+	idxconfig "github.com/dms3-fs/go-idx-config"
+)
+
+const testPeerID = "QmTFauExutTsy4XP6JbMFcw2Wa9645HJt2bTqL6qYDCKfe"
+
+var _ coreiface.CoreAPI = (*httpapi.HttpApi)(nil)
+
+// makeAPI serves the commands of a new offline node over HTTP, and returns
+// both the embedded and the HTTP implementations of the CoreAPI for it
+func makeAPI(ctx context.Context, t *testing.T) (coreiface.CoreAPI, *httpapi.HttpApi) {
+	r := &repo.Mock{
+		C: config.Config{
+			Identity: config.Identity{
+				PeerID: testPeerID,
+			},
+		},
+		D: syncds.MutexWrap(datastore.NewMapDatastore()),
+		K: keystore.NewMemKeystore(),
+	}
+
+	node, err := core.NewNode(ctx, &core.BuildCfg{Repo: r})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cctx := oldcmds.Context{
+		Online:     true,
+		ConfigRoot: "/tmp/.mockdms3fsconfig",
+		ReqLog:     &oldcmds.ReqLog{},
+		LoadConfig: func(path string) (*config.Config, error) {
+			return node.Repo.Config()
+		},
+		LoadIdxConfig: func(path string) (*idxconfig.IdxConfig, error) {
+			return node.Repo.IdxConfig()
+		},
+		ConstructNode: func() (*core.Dms3FsNode, error) {
+			return node, nil
+		},
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go corehttp.Serve(node, lis, corehttp.CommandsOption(cctx))
+
+	return coreapi.NewCoreAPI(node), httpapi.NewURLApiWithClient(lis.Addr().String(), http.DefaultClient)
+}
+
+func strFile(data string) files.File {
+	return files.NewReaderFile("", "", ioutil.NopCloser(strings.NewReader(data)), nil)
+}
+
+func TestUnixfsRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	local, api := makeAPI(ctx, t)
+
+	p, err := api.Unixfs().Add(ctx, strFile("hello, world!"), options.Unixfs.Pin(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.String() != "/dms3fs/QmQy2Dw4Wk7rdJKjThjYXzfFJNaRKRHhHP5gHHXroJMYxk" {
+		t.Fatalf("unexpected path: %s", p.String())
+	}
+
+	r, err := api.Unixfs().Cat(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if _, err := r.Seek(7, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "world!" {
+		t.Fatalf("unexpected data: %q", data)
+	}
+
+	// the pin option is sent to the daemon, which pinned the file
+	pins, err := local.Pin().Ls(ctx, options.Pin.Type.Recursive())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != 1 || pins[0].Path().Cid().String() != p.Cid().String() {
+		t.Fatalf("unexpected pins: %v", pins)
+	}
+}
+
+func TestUnixfsCatDirectory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	local, api := makeAPI(ctx, t)
+
+	dir, err := local.Object().New(ctx, options.Object.Type("unixfs-dir"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = api.Unixfs().Cat(ctx, coreiface.Dms3FsPath(dir.Cid()))
+	if err != coreiface.ErrIsDir {
+		t.Fatalf("expected ErrIsDir, got: %v", err)
+	}
+}
+
+func TestBlockRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, api := makeAPI(ctx, t)
+
+	p, err := api.Block().Put(ctx, strings.NewReader("Hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Cid().String() != "QmPyo15ynbVrSTVdJL9th7JysHaAbXt9dM9tXk1bMHbRtk" {
+		t.Fatalf("unexpected cid: %s", p.Cid())
+	}
+
+	st, err := api.Block().Stat(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Size() != 5 {
+		t.Fatalf("unexpected size: %d", st.Size())
+	}
+
+	r, err := api.Block().Get(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "Hello" {
+		t.Fatalf("unexpected data: %q", data)
+	}
+}
+
+func TestDagRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, api := makeAPI(ctx, t)
+
+	p, err := api.Dag().Put(ctx, strings.NewReader(`{"a": 1, "b": {"c": "d"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bp, err := coreiface.ParsePath(path.Join(p.String(), "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rp, err := api.ResolvePath(ctx, bp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rp.Root().String() != p.Cid().String() {
+		t.Fatalf("unexpected root: %s", rp.Root())
+	}
+
+	nd, err := api.Dag().Get(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nd.Cid().String() != p.Cid().String() {
+		t.Fatalf("unexpected cid: %s", nd.Cid())
+	}
+
+	v, _, err := nd.Resolve([]string{"b", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "d" {
+		t.Fatalf("unexpected value: %v", v)
+	}
+}
+
+func TestPinRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, api := makeAPI(ctx, t)
+
+	p, err := api.Unixfs().Add(ctx, strFile("foo"), options.Unixfs.Pin(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Pin().Add(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pins, err := api.Pin().Ls(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != 1 || pins[0].Type() != "recursive" || pins[0].Path().Cid().String() != p.Cid().String() {
+		t.Fatalf("unexpected pins: %v", pins)
+	}
+
+	err = api.Pin().Rm(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pins, err = api.Pin().Ls(ctx, options.Pin.Type.Recursive())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != 0 {
+		t.Fatalf("unexpected pins: %v", pins)
+	}
+}
+
+func TestKeyRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, api := makeAPI(ctx, t)
+
+	k, err := api.Key().Generate(ctx, "foo", options.Key.Size(512))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Name() != "foo" {
+		t.Fatalf("unexpected key name: %s", k.Name())
+	}
+
+	keys, err := api.Key().List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0].Name() != "self" || keys[1].Name() != "foo" {
+		t.Fatalf("unexpected keys: %v", keys)
+	}
+	if keys[1].Path().String() != k.Path().String() {
+		t.Fatalf("unexpected key path: %s", keys[1].Path())
+	}
+}
+
+func TestObjectRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, api := makeAPI(ctx, t)
+
+	nd, err := api.Object().New(ctx, options.Object.Type("unixfs-dir"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nd.Cid().String() != "QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn" {
+		t.Fatalf("unexpected cid: %s", nd.Cid())
+	}
+
+	st, err := api.Object().Stat(ctx, coreiface.Dms3FsPath(nd.Cid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.NumLinks != 0 || st.BlockSize != 4 {
+		t.Fatalf("unexpected stat: %+v", st)
+	}
+}
+
+func TestDhtOffline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, api := makeAPI(ctx, t)
+
+	_, err := api.Dht().GetValue(ctx, "/pk/foo")
+	if err != coreiface.ErrOffline {
+		t.Fatalf("expected ErrOffline, got: %v", err)
+	}
+}
+
+func TestRepoVerify(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	local, api := makeAPI(ctx, t)
+
+	p, err := local.Unixfs().Add(ctx, strFile("verify"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := api.Repo().Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for res := range out {
+		if res.Err != nil || res.Corrupt != nil {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+		if res.Cid.String() == p.Cid().String() {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected %s to be verified", p.Cid())
+	}
+}
+
+func TestBitswapOffline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, api := makeAPI(ctx, t)
+
+	_, err := api.Bitswap().Stat(ctx)
+	if err != coreiface.ErrOffline {
+		t.Fatalf("expected ErrOffline, got: %v", err)
+	}
+}
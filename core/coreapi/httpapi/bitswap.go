@@ -0,0 +1,83 @@
+package httpapi
+
+import (
+	"context"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	cid "github.com/dms3-fs/go-cid"
+	peer "github.com/dms3-p2p/go-p2p-peer"
+)
+
+type BitswapAPI HttpApi
+
+// Wantlist returns the blocks on the wantlist of the daemon, or of the peer
+// set with the Peer option
+func (api *BitswapAPI) Wantlist(ctx context.Context, opts ...caopts.BitswapWantlistOption) ([]*cid.Cid, error) {
+	settings, err := caopts.BitswapWantlistOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	req := api.core().request("bitswap/wantlist")
+	if settings.Peer != "" {
+		req.Option("peer", settings.Peer.Pretty())
+	}
+
+	var out struct {
+		Keys []*cid.Cid
+	}
+	if err := req.Exec(ctx, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Keys, nil
+}
+
+// Unwant removes the objects at the given paths from the wantlist of the
+// daemon
+func (api *BitswapAPI) Unwant(ctx context.Context, paths ...coreiface.Path) error {
+	ks := make([]string, 0, len(paths))
+	for _, p := range paths {
+		rp, err := api.core().ResolvePath(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		ks = append(ks, rp.Cid().String())
+	}
+
+	return api.core().request("bitswap/unwant", ks...).Exec(ctx, nil)
+}
+
+// Ledger returns the ledger the daemon keeps for peer `p`
+func (api *BitswapAPI) Ledger(ctx context.Context, p peer.ID) (*coreiface.BitswapLedger, error) {
+	var out coreiface.BitswapLedger
+	err := api.core().request("bitswap/ledger", p.Pretty()).Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// Stat returns diagnostic information about the bitswap agent of the daemon
+func (api *BitswapAPI) Stat(ctx context.Context) (*coreiface.BitswapStat, error) {
+	var out coreiface.BitswapStat
+	err := api.core().request("bitswap/stat").Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// Reprovide triggers the reprovider of the daemon
+func (api *BitswapAPI) Reprovide(ctx context.Context) error {
+	return api.core().request("bitswap/reprovide").Exec(ctx, nil)
+}
+
+func (api *BitswapAPI) core() *HttpApi {
+	return (*HttpApi)(api)
+}
@@ -0,0 +1,150 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	cid "github.com/dms3-fs/go-cid"
+	mh "github.com/dms3-mft/go-multihash"
+)
+
+type BlockAPI HttpApi
+
+type blockStat struct {
+	path coreiface.ResolvedPath
+	size int
+}
+
+// Put uploads the block data to the daemon, which hashes and stores it using
+// the specified settings
+func (api *BlockAPI) Put(ctx context.Context, src io.Reader, opts ...caopts.BlockPutOption) (coreiface.ResolvedPath, error) {
+	settings, err := caopts.BlockPutOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	mht, ok := mh.Codes[settings.MhType]
+	if !ok {
+		return nil, fmt.Errorf("unknown multihash type %d", settings.MhType)
+	}
+
+	var out struct {
+		Key string
+	}
+
+	err = api.core().request("block/put").
+		Option("format", settings.Codec).
+		Option("mhtype", mht).
+		Option("mhlen", settings.MhLength).
+		Body(src).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cid.Parse(out.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return coreiface.Dms3LdPath(c), nil
+}
+
+// Get returns a reader for the data of the block at the path `p`
+func (api *BlockAPI) Get(ctx context.Context, p coreiface.Path) (io.Reader, error) {
+	rp, err := api.core().ResolvePath(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := api.core().request("block/get", rp.Cid().String()).ExecRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(data), nil
+}
+
+// Rm removes the block at the path `p` from the blockstore of the daemon
+func (api *BlockAPI) Rm(ctx context.Context, p coreiface.Path, opts ...caopts.BlockRmOption) error {
+	settings, err := caopts.BlockRmOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	rp, err := api.core().ResolvePath(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	var out struct {
+		Hash  string
+		Error string
+	}
+
+	err = api.core().request("block/rm", rp.Cid().String()).
+		Option("force", settings.Force).
+		Exec(ctx, &out)
+	if err != nil {
+		return err
+	}
+
+	if out.Error != "" {
+		return errors.New(out.Error)
+	}
+
+	return nil
+}
+
+// Stat returns information about the block at the path `p`
+func (api *BlockAPI) Stat(ctx context.Context, p coreiface.Path) (coreiface.BlockStat, error) {
+	rp, err := api.core().ResolvePath(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Key  string
+		Size int
+	}
+
+	err = api.core().request("block/stat", rp.Cid().String()).Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cid.Parse(out.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &blockStat{
+		path: coreiface.Dms3LdPath(c),
+		size: out.Size,
+	}, nil
+}
+
+func (bs *blockStat) Size() int {
+	return bs.size
+}
+
+func (bs *blockStat) Path() coreiface.ResolvedPath {
+	return bs.path
+}
+
+func (api *BlockAPI) core() *HttpApi {
+	return (*HttpApi)(api)
+}
@@ -0,0 +1,253 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	gopath "path"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+	coredag "github.com/dms3-fs/go-dms3-fs/core/coredag"
+
+	cid "github.com/dms3-fs/go-cid"
+	dms3ld "github.com/dms3-fs/go-ld-format"
+	mh "github.com/dms3-mft/go-multihash"
+)
+
+type DagAPI HttpApi
+
+type dagBatch struct {
+	api   *DagAPI
+	toPut []dms3ld.Node
+
+	lk sync.Mutex
+}
+
+// Put uploads the data to the daemon, which decodes it using the specified
+// input encoding and stores it using the specified format
+func (api *DagAPI) Put(ctx context.Context, src io.Reader, opts ...caopts.DagPutOption) (coreiface.ResolvedPath, error) {
+	settings, err := caopts.DagPutOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, ok := cid.CodecToStr[settings.Codec]
+	if !ok {
+		return nil, fmt.Errorf("invalid codec %d", settings.Codec)
+	}
+
+	if settings.MhLength != -1 {
+		return nil, errors.New("dag put: custom hash lengths are not supported by the daemon")
+	}
+
+	req := api.core().request("dag/put").
+		Option("format", codec).
+		Option("input-enc", settings.InputEnc)
+
+	if settings.MhType != math.MaxUint64 {
+		mht, ok := mh.Codes[settings.MhType]
+		if !ok {
+			return nil, fmt.Errorf("unknown multihash type %d", settings.MhType)
+		}
+		req.Option("hash", mht)
+	}
+
+	var out struct {
+		Cid *cid.Cid
+	}
+
+	err = req.Body(src).Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return coreiface.Dms3LdPath(out.Cid), nil
+}
+
+// Get resolves `path` on the daemon, returns the resolved Node.
+func (api *DagAPI) Get(ctx context.Context, path coreiface.Path) (dms3ld.Node, error) {
+	return api.core().ResolveNode(ctx, path)
+}
+
+// Tree returns list of paths within a node specified by the path `p`.
+func (api *DagAPI) Tree(ctx context.Context, p coreiface.Path, opts ...caopts.DagTreeOption) ([]coreiface.Path, error) {
+	settings, err := caopts.DagTreeOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := api.Get(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	paths := n.Tree("", settings.Depth)
+	out := make([]coreiface.Path, len(paths))
+	for n, p2 := range paths {
+		out[n], err = coreiface.ParsePath(gopath.Join(p.String(), p2))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// TreeAsync streams the paths within the node specified by the path `p`. The
+// node is fetched from the daemon and its paths are listed locally
+func (api *DagAPI) TreeAsync(ctx context.Context, p coreiface.Path, opts ...caopts.DagTreeOption) (<-chan coreiface.DagTreeResult, error) {
+	paths, err := api.Tree(ctx, p, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan coreiface.DagTreeResult)
+	go func() {
+		defer close(out)
+		for _, p := range paths {
+			select {
+			case out <- coreiface.DagTreeResult{Path: p}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// Diff returns the changes needed to transform the node at path `before` into
+// the node at path `after`. The object diff command of the daemon supports
+// nodes of any format
+func (api *DagAPI) Diff(ctx context.Context, before coreiface.Path, after coreiface.Path, opts ...caopts.DagDiffOption) ([]coreiface.ObjectChange, error) {
+	settings, err := caopts.DagDiffOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Changes []*change
+	}
+
+	err = api.core().request("object/diff", before.String(), after.String()).
+		Option("depth", settings.Depth).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]coreiface.ObjectChange, len(out.Changes))
+	for i, c := range out.Changes {
+		res[i] = c.objectChange(coreiface.Dms3LdPath)
+	}
+
+	return res, nil
+}
+
+// Merge asks the daemon to merge the changes from the node at path `base` to
+// the nodes at paths `a` and `b`
+func (api *DagAPI) Merge(ctx context.Context, base coreiface.Path, a coreiface.Path, b coreiface.Path) (coreiface.ResolvedPath, []coreiface.DagConflict, error) {
+	var out struct {
+		Cid       *cid.Cid
+		Conflicts []struct {
+			A *change
+			B *change
+		}
+	}
+
+	err := api.core().request("dag/merge", base.String(), a.String(), b.String()).Exec(ctx, &out)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(out.Conflicts) > 0 {
+		conflicts := make([]coreiface.DagConflict, len(out.Conflicts))
+		for i, c := range out.Conflicts {
+			conflicts[i] = coreiface.DagConflict{
+				A: c.A.objectChange(coreiface.Dms3LdPath),
+				B: c.B.objectChange(coreiface.Dms3LdPath),
+			}
+		}
+		return nil, conflicts, nil
+	}
+
+	if out.Cid == nil {
+		return nil, nil, errors.New("dag merge: no cid returned by the daemon")
+	}
+
+	return coreiface.Dms3LdPath(out.Cid), nil, nil
+}
+
+// Batch creates new DagBatch. The nodes are encoded locally and uploaded to
+// the daemon as raw blocks on Commit
+func (api *DagAPI) Batch(ctx context.Context) coreiface.DagBatch {
+	return &dagBatch{api: api}
+}
+
+// Put decodes the data using the specified input encoding and queues the
+// resulting node. Returns the path of the node.
+func (b *dagBatch) Put(ctx context.Context, src io.Reader, opts ...caopts.DagPutOption) (coreiface.ResolvedPath, error) {
+	settings, err := caopts.DagPutOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, ok := cid.CodecToStr[settings.Codec]
+	if !ok {
+		return nil, fmt.Errorf("invalid codec %d", settings.Codec)
+	}
+
+	nds, err := coredag.ParseInputs(settings.InputEnc, codec, src, settings.MhType, settings.MhLength)
+	if err != nil {
+		return nil, err
+	}
+	if len(nds) == 0 {
+		return nil, fmt.Errorf("no node returned from ParseInputs")
+	}
+	if len(nds) != 1 {
+		return nil, fmt.Errorf("got more that one node from ParseInputs")
+	}
+
+	b.lk.Lock()
+	b.toPut = append(b.toPut, nds[0])
+	b.lk.Unlock()
+
+	return coreiface.Dms3LdPath(nds[0].Cid()), nil
+}
+
+// Commit uploads the queued nodes to the daemon
+func (b *dagBatch) Commit(ctx context.Context) error {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+	defer func() {
+		b.toPut = nil
+	}()
+
+	for _, nd := range b.toPut {
+		pref := nd.Cid().Prefix()
+
+		format := "v0"
+		if pref.Version != 0 {
+			format = cid.CodecToStr[pref.Codec]
+		}
+
+		_, err := b.api.core().Block().Put(ctx, bytes.NewReader(nd.RawData()),
+			caopts.Block.Format(format),
+			caopts.Block.Hash(pref.MhType, pref.MhLength),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (api *DagAPI) core() *HttpApi {
+	return (*HttpApi)(api)
+}
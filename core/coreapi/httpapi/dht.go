@@ -0,0 +1,193 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	peer "github.com/dms3-p2p/go-p2p-peer"
+	pstore "github.com/dms3-p2p/go-p2p-peerstore"
+	notif "github.com/dms3-p2p/go-p2p-routing/notifications"
+	b58 "github.com/mr-tron/base58/base58"
+)
+
+type DhtAPI HttpApi
+
+// FindPeer asks the daemon to find the addresses of the peer
+func (api *DhtAPI) FindPeer(ctx context.Context, p peer.ID) (pstore.PeerInfo, error) {
+	var pi *pstore.PeerInfo
+
+	err := api.core().queryEvents(ctx, api.core().request("dht/findpeer", p.Pretty()), func(ev *notif.QueryEvent) {
+		if ev.Type == notif.FinalPeer && len(ev.Responses) > 0 {
+			pi = ev.Responses[0]
+		}
+	})
+	if err != nil {
+		return pstore.PeerInfo{}, err
+	}
+
+	if pi == nil {
+		return pstore.PeerInfo{}, errors.New("dht findpeer: peer not found")
+	}
+
+	return *pi, nil
+}
+
+// FindProviders asks the daemon to find the peers providing the object at the
+// path `p`
+func (api *DhtAPI) FindProviders(ctx context.Context, p coreiface.Path, opts ...caopts.DhtFindProvidersOption) (<-chan pstore.PeerInfo, error) {
+	settings, err := caopts.DhtFindProvidersOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	rp, err := api.core().ResolvePath(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := api.core().request("dht/findprovs", rp.Cid().String()).
+		Option("num-providers", settings.NumProviders).
+		Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan pstore.PeerInfo)
+	go func() {
+		defer close(out)
+		defer res.Close()
+
+		for {
+			ev := new(notif.QueryEvent)
+			err := res.Decode(ev)
+			if err != nil {
+				if err != io.EOF {
+					log.Errorf("dht findprovs: %s", err)
+				}
+				return
+			}
+
+			if ev.Type != notif.Provider {
+				continue
+			}
+
+			for _, pi := range ev.Responses {
+				select {
+				case out <- *pi:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// Provide asks the daemon to announce to the network that it provides the
+// object at the path `p`
+func (api *DhtAPI) Provide(ctx context.Context, p coreiface.Path, opts ...caopts.DhtProvideOption) error {
+	settings, err := caopts.DhtProvideOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	rp, err := api.core().ResolvePath(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	req := api.core().request("dht/provide", rp.Cid().String()).
+		Option("recursive", settings.Recursive)
+
+	return api.core().queryEvents(ctx, req, nil)
+}
+
+// GetValue asks the daemon to search the routing system for the value stored
+// under the key. Note that the daemon reports the value as a string, so
+// values which are not valid UTF-8 are altered
+func (api *DhtAPI) GetValue(ctx context.Context, key string) ([]byte, error) {
+	ekey, err := encodeDhtKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var value []byte
+	found := false
+
+	err = api.core().queryEvents(ctx, api.core().request("dht/get", ekey), func(ev *notif.QueryEvent) {
+		if ev.Type == notif.Value {
+			value = []byte(ev.Extra)
+			found = true
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, errors.New("dht get: value not found")
+	}
+
+	return value, nil
+}
+
+// PutValue asks the daemon to write the key/value pair to the routing system
+func (api *DhtAPI) PutValue(ctx context.Context, key string, value []byte) error {
+	ekey, err := encodeDhtKey(key)
+	if err != nil {
+		return err
+	}
+
+	return api.core().queryEvents(ctx, api.core().request("dht/put", ekey, string(value)), nil)
+}
+
+// queryEvents sends the request and calls fn for each query event of the
+// output. It returns the error of the first QueryError event
+func (api *HttpApi) queryEvents(ctx context.Context, req *requestBuilder, fn func(*notif.QueryEvent)) error {
+	res, err := req.Send(ctx)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	for {
+		ev := new(notif.QueryEvent)
+		err := res.Decode(ev)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if ev.Type == notif.QueryError {
+			return knownError(&Error{Command: req.command, Message: ev.Extra})
+		}
+
+		if fn != nil {
+			fn(ev)
+		}
+	}
+}
+
+// encodeDhtKey encodes the key in the form expected by the dht commands, which
+// is "/<namespace>/<base58 encoded key>"
+func encodeDhtKey(key string) (string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(key, "/"), "/", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", fmt.Errorf("invalid dht key: %q", key)
+	}
+
+	return "/" + parts[0] + "/" + b58.Encode([]byte(parts[1])), nil
+}
+
+func (api *DhtAPI) core() *HttpApi {
+	return (*HttpApi)(api)
+}
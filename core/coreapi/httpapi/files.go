@@ -0,0 +1,213 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	cid "github.com/dms3-fs/go-cid"
+	mh "github.com/dms3-mft/go-multihash"
+)
+
+type FilesAPI HttpApi
+
+// Read returns a reader for the file at the given path in the mutable
+// filesystem of the daemon
+func (api *FilesAPI) Read(ctx context.Context, p string, opts ...caopts.FilesReadOption) (io.ReadCloser, error) {
+	settings, err := caopts.FilesReadOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	req := api.core().request("files/read", p).
+		Option("offset", settings.Offset)
+	if settings.Count >= 0 {
+		req.Option("count", settings.Count)
+	}
+
+	return req.ExecRaw(ctx)
+}
+
+// Write uploads the data from the reader to the file at the given path
+func (api *FilesAPI) Write(ctx context.Context, p string, r io.Reader, opts ...caopts.FilesWriteOption) error {
+	settings, err := caopts.FilesWriteOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	req := api.core().request("files/write", p).
+		Option("offset", settings.Offset).
+		Option("create", settings.Create).
+		Option("parents", settings.Parents).
+		Option("truncate", settings.Truncate).
+		Option("flush", settings.Flush).
+		Body(r)
+	if settings.Count >= 0 {
+		req.Option("count", settings.Count)
+	}
+	if settings.RawLeavesSet {
+		req.Option("raw-leaves", settings.RawLeaves)
+	}
+	if err := cidOptions(req, settings.CidVersion, settings.MhType); err != nil {
+		return err
+	}
+
+	return req.Exec(ctx, nil)
+}
+
+// Mkdir creates a directory
+func (api *FilesAPI) Mkdir(ctx context.Context, p string, opts ...caopts.FilesMkdirOption) error {
+	settings, err := caopts.FilesMkdirOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	req := api.core().request("files/mkdir", p).
+		Option("parents", settings.Parents).
+		Option("flush", settings.Flush)
+	if err := cidOptions(req, settings.CidVersion, settings.MhType); err != nil {
+		return err
+	}
+
+	return req.Exec(ctx, nil)
+}
+
+// Mv moves files and directories
+func (api *FilesAPI) Mv(ctx context.Context, src string, dst string) error {
+	return api.core().request("files/mv", src, dst).Exec(ctx, nil)
+}
+
+// Cp copies a file or directory into the mutable filesystem
+func (api *FilesAPI) Cp(ctx context.Context, src string, dst string, opts ...caopts.FilesCpOption) error {
+	settings, err := caopts.FilesCpOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	return api.core().request("files/cp", src, dst).
+		Option("flush", settings.Flush).
+		Exec(ctx, nil)
+}
+
+// Ls lists the entries of the directory at the given path
+func (api *FilesAPI) Ls(ctx context.Context, p string, opts ...caopts.FilesLsOption) ([]coreiface.FilesEntry, error) {
+	settings, err := caopts.FilesLsOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Entries []coreiface.FilesEntry
+	}
+
+	err = api.core().request("files/ls", p).
+		Option("l", settings.Long).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Entries, nil
+}
+
+// Stat returns information about the node at the given path
+func (api *FilesAPI) Stat(ctx context.Context, p string, opts ...caopts.FilesStatOption) (*coreiface.FilesStat, error) {
+	settings, err := caopts.FilesStatOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Hash           string
+		Size           uint64
+		CumulativeSize uint64
+		Blocks         int
+		Type           string
+		WithLocality   bool
+		Local          bool
+		SizeLocal      uint64
+	}
+
+	err = api.core().request("files/stat", p).
+		Option("with-local", settings.WithLocal).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cid.Parse(out.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &coreiface.FilesStat{
+		Cid:            c,
+		Size:           out.Size,
+		CumulativeSize: out.CumulativeSize,
+		Blocks:         out.Blocks,
+		Type:           out.Type,
+		WithLocality:   out.WithLocality,
+		Local:          out.Local,
+		SizeLocal:      out.SizeLocal,
+	}, nil
+}
+
+// Rm removes a file or, with the Recursive option, a directory
+func (api *FilesAPI) Rm(ctx context.Context, p string, opts ...caopts.FilesRmOption) error {
+	settings, err := caopts.FilesRmOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	return api.core().request("files/rm", p).
+		Option("recursive", settings.Recursive).
+		Exec(ctx, nil)
+}
+
+// Flush flushes the given path's data to disk
+func (api *FilesAPI) Flush(ctx context.Context, p string) error {
+	return api.core().request("files/flush", p).Exec(ctx, nil)
+}
+
+// Chcid changes the CID version or hash function of the directory at the
+// given path
+func (api *FilesAPI) Chcid(ctx context.Context, p string, opts ...caopts.FilesChcidOption) error {
+	settings, err := caopts.FilesChcidOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	req := api.core().request("files/chcid", p).
+		Option("flush", settings.Flush)
+	if err := cidOptions(req, settings.CidVersion, settings.MhType); err != nil {
+		return err
+	}
+
+	return req.Exec(ctx, nil)
+}
+
+// cidOptions sets the cid-version and hash options of the request, leaving
+// out the ones which were not set so that the daemon applies its defaults
+func cidOptions(req *requestBuilder, cidVer int, mhType uint64) error {
+	if cidVer >= 0 {
+		req.Option("cid-version", cidVer)
+	}
+
+	if mhType != math.MaxUint64 {
+		mht, ok := mh.Codes[mhType]
+		if !ok {
+			return fmt.Errorf("unknown multihash type %d", mhType)
+		}
+		req.Option("hash", mht)
+	}
+
+	return nil
+}
+
+func (api *FilesAPI) core() *HttpApi {
+	return (*HttpApi)(api)
+}
@@ -0,0 +1,284 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	cid "github.com/dms3-fs/go-cid"
+	files "github.com/dms3-fs/go-fs-cmdkit/files"
+)
+
+const (
+	infostoreClass = "infostore"
+	metastoreClass = "metastore"
+
+	// reposetDirName is the name of the add event reporting the reposet root
+	reposetDirName = "reposetdir"
+)
+
+type IndexAPI HttpApi
+
+type reposet struct {
+	class string
+	kind  string
+	name  string
+	path  coreiface.ResolvedPath
+}
+
+// Class returns the reposet class.
+func (r *reposet) Class() string {
+	return r.class
+}
+
+// Kind returns the reposet kind.
+func (r *reposet) Kind() string {
+	return r.kind
+}
+
+// Name returns the reposet name.
+func (r *reposet) Name() string {
+	return r.name
+}
+
+// Path returns the path to the reposet root directory.
+func (r *reposet) Path() coreiface.ResolvedPath {
+	return r.path
+}
+
+// List returns the reposets of the daemon which match the kind, name and
+// class filters, one page at a time
+func (api *IndexAPI) List(ctx context.Context, opts ...caopts.IndexListOption) ([]coreiface.Reposet, error) {
+	settings, err := caopts.IndexListOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []struct {
+		Infoclass   string
+		Reposetkind string
+		Reposetname string
+		Reposetpath string
+	}
+
+	err = api.core().request("index/ls").
+		Option("kind", settings.Kind).
+		Option("name", settings.Name).
+		Option("meta", settings.Metastore).
+		Option("data", settings.Infostore).
+		Option("offset", settings.Offset).
+		Option("length", settings.Length).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]coreiface.Reposet, len(out))
+	for i, r := range out {
+		c, err := cid.Decode(r.Reposetpath)
+		if err != nil {
+			return nil, err
+		}
+
+		res[i] = &reposet{
+			class: r.Infoclass,
+			kind:  r.Reposetkind,
+			name:  r.Reposetname,
+			path:  coreiface.Dms3FsPath(c),
+		}
+	}
+
+	return res, nil
+}
+
+// MakeReposet asks the daemon to create a new reposet, and forwards the add
+// events it reports
+func (api *IndexAPI) MakeReposet(ctx context.Context, kind string, name string, opts ...caopts.IndexMakeOption) (coreiface.Reposet, error) {
+	settings, err := caopts.IndexMakeOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := api.core().request("index/mkidx", settings.Infostores...).
+		Option("kind", kind).
+		Option("name", name).
+		Option("progress", settings.Progress && settings.Events != nil).
+		Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	var root string
+	for {
+		ev := new(coreiface.AddEvent)
+		err := res.Decode(ev)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if ev.Name == reposetDirName && ev.Hash != "" {
+			root = ev.Hash
+		}
+
+		if settings.Events == nil {
+			continue
+		}
+
+		select {
+		case settings.Events <- ev:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if root == "" {
+		return nil, errors.New("index mkidx: no reposet root returned by the daemon")
+	}
+
+	c, err := cid.Decode(root)
+	if err != nil {
+		return nil, err
+	}
+
+	class := infostoreClass
+	if len(settings.Infostores) > 0 {
+		class = metastoreClass
+	}
+
+	return &reposet{
+		class: class,
+		kind:  kind,
+		name:  name,
+		path:  coreiface.Dms3FsPath(c),
+	}, nil
+}
+
+// MakeDoc returns the empty document template the daemon generates for
+// content of kind `kind`
+func (api *IndexAPI) MakeDoc(ctx context.Context, kind string) (string, error) {
+	var out struct {
+		Content string
+	}
+
+	err := api.core().request("index/mkdoc").
+		Option("kind", kind).
+		Exec(ctx, &out)
+	if err != nil {
+		return "", err
+	}
+
+	return out.Content, nil
+}
+
+// Add uploads the document `doc` to the daemon, which adds it to the corpus
+// of the reposet at path `p`
+func (api *IndexAPI) Add(ctx context.Context, p coreiface.Path, doc files.File) (coreiface.ResolvedPath, error) {
+	var out struct {
+		Path string
+	}
+
+	err := api.core().request("index/addoc", p.String()).
+		FileBody(doc).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cid.Decode(out.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return coreiface.Dms3FsPath(c), nil
+}
+
+// Remove removes the document at path `doc` from the reposet at path `p`
+func (api *IndexAPI) Remove(ctx context.Context, p coreiface.Path, doc coreiface.Path) error {
+	return api.core().request("index/rmdoc", doc.String(), p.String()).Exec(ctx, nil)
+}
+
+// Publish asks the daemon to publish the reposet at path `p` under a DMS3NS
+// name
+func (api *IndexAPI) Publish(ctx context.Context, p coreiface.Path, opts ...caopts.IndexPublishOption) (coreiface.Dms3NsEntry, error) {
+	settings, err := caopts.IndexPublishOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Name  string
+		Value string
+	}
+
+	err = api.core().request("index/publish", p.String()).
+		Option("key", settings.Key).
+		Option("lifetime", settings.ValidTime.String()).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := coreiface.ParsePath(out.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dms3nsEntry{name: out.Name, value: value}, nil
+}
+
+// Stat returns the properties of the reposet at path `p`
+func (api *IndexAPI) Stat(ctx context.Context, p coreiface.Path) (*coreiface.ReposetStat, error) {
+	var out struct {
+		Hash      string
+		Infoclass string
+		Kind      string
+		Name      string
+		CreatedAt string
+		MaxAreas  uint8
+		MaxCats   uint8
+		MaxDocs   uint64
+		Docs      uint64
+	}
+
+	err := api.core().request("index/stat", p.String()).Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cid.Decode(out.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, out.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &coreiface.ReposetStat{
+		Cid: c,
+
+		Class: out.Infoclass,
+		Kind:  out.Kind,
+		Name:  out.Name,
+
+		CreatedAt: createdAt,
+		MaxAreas:  out.MaxAreas,
+		MaxCats:   out.MaxCats,
+		MaxDocs:   out.MaxDocs,
+
+		Docs: out.Docs,
+	}, nil
+}
+
+func (api *IndexAPI) core() *HttpApi {
+	return (*HttpApi)(api)
+}
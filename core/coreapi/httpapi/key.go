@@ -0,0 +1,178 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	dms3fspath "github.com/dms3-fs/go-path"
+	peer "github.com/dms3-p2p/go-p2p-peer"
+)
+
+type KeyAPI HttpApi
+
+type key struct {
+	name   string
+	peerID peer.ID
+}
+
+// keyOutput is the wire format of a key in the output of the key commands
+type keyOutput struct {
+	Name string
+	Id   string
+}
+
+func (o *keyOutput) key() (*key, error) {
+	pid, err := peer.IDB58Decode(o.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key{o.Name, pid}, nil
+}
+
+// Name returns the key name
+func (k *key) Name() string {
+	return k.name
+}
+
+// Path returns the path of the key.
+func (k *key) Path() coreiface.Path {
+	path, err := coreiface.ParsePath(dms3fspath.Join([]string{"/dms3ns", k.peerID.Pretty()}))
+	if err != nil {
+		panic("error parsing path: " + err.Error())
+	}
+
+	return path
+}
+
+// ID returns key PeerID
+func (k *key) ID() peer.ID {
+	return k.peerID
+}
+
+// Generate generates new key on the daemon, stores it in its keystore under
+// the specified name and returns the key.
+func (api *KeyAPI) Generate(ctx context.Context, name string, opts ...caopts.KeyGenerateOption) (coreiface.Key, error) {
+	options, err := caopts.KeyGenerateOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	req := api.core().request("key/gen", name).
+		Option("type", options.Algorithm)
+	if options.Size != -1 {
+		req.Option("size", options.Size)
+	}
+
+	var out keyOutput
+	err = req.Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.key()
+}
+
+// List returns a list keys stored in the keystore of the daemon.
+func (api *KeyAPI) List(ctx context.Context) ([]coreiface.Key, error) {
+	var out struct {
+		Keys []keyOutput
+	}
+
+	err := api.core().request("key/list").
+		Option("l", true).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]coreiface.Key, len(out.Keys))
+	for i, k := range out.Keys {
+		res[i], err = k.key()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// ListAsync streams the keys stored in the keystore of the daemon. The daemon
+// returns all keys at once, so the keys are only streamed on the client side
+func (api *KeyAPI) ListAsync(ctx context.Context) (<-chan coreiface.KeyListResult, error) {
+	keys, err := api.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan coreiface.KeyListResult)
+	go func() {
+		defer close(out)
+		for _, k := range keys {
+			select {
+			case out <- coreiface.KeyListResult{Key: k}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// Rename renames `oldName` to `newName`. Returns the key and whether another
+// key was overwritten, or an error.
+func (api *KeyAPI) Rename(ctx context.Context, oldName string, newName string, opts ...caopts.KeyRenameOption) (coreiface.Key, bool, error) {
+	options, err := caopts.KeyRenameOptions(opts...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var out struct {
+		Was       string
+		Now       string
+		Id        string
+		Overwrite bool
+	}
+
+	err = api.core().request("key/rename", oldName, newName).
+		Option("force", options.Force).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, false, err
+	}
+
+	k, err := (&keyOutput{Name: out.Now, Id: out.Id}).key()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return k, out.Overwrite, nil
+}
+
+// Remove removes keys from keystore. Returns dms3ns path of the removed key.
+func (api *KeyAPI) Remove(ctx context.Context, name string) (coreiface.Key, error) {
+	var out struct {
+		Keys []keyOutput
+	}
+
+	err := api.core().request("key/rm", name).
+		Option("l", true).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(out.Keys) != 1 {
+		return nil, errors.New("key rm: expected exactly one removed key")
+	}
+
+	return out.Keys[0].key()
+}
+
+func (api *KeyAPI) core() *HttpApi {
+	return (*HttpApi)(api)
+}
@@ -0,0 +1,86 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+)
+
+type NameAPI HttpApi
+
+type dms3nsEntry struct {
+	name  string
+	value coreiface.Path
+}
+
+// Name returns the dms3nsEntry name.
+func (e *dms3nsEntry) Name() string {
+	return e.name
+}
+
+// Value returns the dms3nsEntry value.
+func (e *dms3nsEntry) Value() coreiface.Path {
+	return e.value
+}
+
+// Publish announces new DMS3NS name through the daemon
+func (api *NameAPI) Publish(ctx context.Context, p coreiface.Path, opts ...caopts.NamePublishOption) (coreiface.Dms3NsEntry, error) {
+	options, err := caopts.NamePublishOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Name  string
+		Value string
+	}
+
+	err = api.core().request("name/publish", p.String()).
+		Option("key", options.Key).
+		Option("lifetime", options.ValidTime.String()).
+		Option("resolve", false).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := coreiface.ParsePath(out.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dms3nsEntry{name: out.Name, value: value}, nil
+}
+
+// Resolve attempts to resolve the newest version of the specified name on the
+// daemon
+func (api *NameAPI) Resolve(ctx context.Context, name string, opts ...caopts.NameResolveOption) (coreiface.Path, error) {
+	options, err := caopts.NameResolveOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Local {
+		return nil, errors.New("name resolve: local resolution is not supported by the daemon")
+	}
+
+	var out struct {
+		Path string
+	}
+
+	err = api.core().request("name/resolve", name).
+		Option("recursive", options.Recursive).
+		Option("nocache", !options.Cache).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return coreiface.ParsePath(out.Path)
+}
+
+func (api *NameAPI) core() *HttpApi {
+	return (*HttpApi)(api)
+}
@@ -0,0 +1,271 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	cid "github.com/dms3-fs/go-cid"
+	dms3ld "github.com/dms3-fs/go-ld-format"
+)
+
+type ObjectAPI HttpApi
+
+// objectOutput is the output of the commands which create new objects
+type objectOutput struct {
+	Hash string
+}
+
+func (o *objectOutput) path() (coreiface.ResolvedPath, error) {
+	c, err := cid.Parse(o.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return coreiface.Dms3FsPath(c), nil
+}
+
+// New creates new, empty (by default) dag-node on the daemon and returns it
+func (api *ObjectAPI) New(ctx context.Context, opts ...caopts.ObjectNewOption) (dms3ld.Node, error) {
+	options, err := caopts.ObjectNewOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	req := api.core().request("object/new")
+	switch options.Type {
+	case "empty":
+	case "unixfs-dir":
+		req.Arguments(options.Type)
+	default:
+		return nil, fmt.Errorf("unknown object type: %s", options.Type)
+	}
+
+	var out objectOutput
+	err = req.Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := out.path()
+	if err != nil {
+		return nil, err
+	}
+
+	return api.core().ResolveNode(ctx, p)
+}
+
+// Put uploads the data to the daemon which imports it into the merkledag
+func (api *ObjectAPI) Put(ctx context.Context, src io.Reader, opts ...caopts.ObjectPutOption) (coreiface.ResolvedPath, error) {
+	options, err := caopts.ObjectPutOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out objectOutput
+	err = api.core().request("object/put").
+		Option("inputenc", options.InputEnc).
+		Option("datafieldenc", options.DataType).
+		Option("pin", options.Pin).
+		Body(src).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.path()
+}
+
+// Get returns the node for the path
+func (api *ObjectAPI) Get(ctx context.Context, path coreiface.Path) (dms3ld.Node, error) {
+	return api.core().ResolveNode(ctx, path)
+}
+
+// Data returns reader for data of the node
+func (api *ObjectAPI) Data(ctx context.Context, path coreiface.Path) (io.Reader, error) {
+	r, err := api.core().request("object/data", path.String()).ExecRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(data), nil
+}
+
+// Links returns the links the node contains
+func (api *ObjectAPI) Links(ctx context.Context, path coreiface.Path) ([]*dms3ld.Link, error) {
+	var out struct {
+		Links []struct {
+			Name, Hash string
+			Size       uint64
+		}
+	}
+
+	err := api.core().request("object/links", path.String()).Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]*dms3ld.Link, len(out.Links))
+	for i, l := range out.Links {
+		c, err := cid.Parse(l.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		links[i] = &dms3ld.Link{
+			Name: l.Name,
+			Size: l.Size,
+			Cid:  c,
+		}
+	}
+
+	return links, nil
+}
+
+// Stat returns information about the node
+func (api *ObjectAPI) Stat(ctx context.Context, path coreiface.Path) (*coreiface.ObjectStat, error) {
+	var out dms3ld.NodeStat
+	err := api.core().request("object/stat", path.String()).Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cid.Parse(out.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &coreiface.ObjectStat{
+		Cid:            c,
+		NumLinks:       out.NumLinks,
+		BlockSize:      out.BlockSize,
+		LinksSize:      out.LinksSize,
+		DataSize:       out.DataSize,
+		CumulativeSize: out.CumulativeSize,
+	}, nil
+}
+
+// AddLink adds a link under the specified path. child path can point to a
+// subdirectory within the patent which must be present (can be overridden
+// with WithCreate option).
+func (api *ObjectAPI) AddLink(ctx context.Context, base coreiface.Path, name string, child coreiface.Path, opts ...caopts.ObjectAddLinkOption) (coreiface.ResolvedPath, error) {
+	options, err := caopts.ObjectAddLinkOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out objectOutput
+	err = api.core().request("object/patch/add-link", base.String(), name, child.String()).
+		Option("create", options.Create).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.path()
+}
+
+// RmLink removes a link from the node
+func (api *ObjectAPI) RmLink(ctx context.Context, base coreiface.Path, link string) (coreiface.ResolvedPath, error) {
+	var out objectOutput
+	err := api.core().request("object/patch/rm-link", base.String(), link).Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.path()
+}
+
+// AppendData appends data to the node
+func (api *ObjectAPI) AppendData(ctx context.Context, path coreiface.Path, r io.Reader) (coreiface.ResolvedPath, error) {
+	var out objectOutput
+	err := api.core().request("object/patch/append-data", path.String()).
+		Body(r).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.path()
+}
+
+// SetData sets the data contained in the node
+func (api *ObjectAPI) SetData(ctx context.Context, path coreiface.Path, r io.Reader) (coreiface.ResolvedPath, error) {
+	var out objectOutput
+	err := api.core().request("object/patch/set-data", path.String()).
+		Body(r).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.path()
+}
+
+// Diff returns a set of changes needed to transform the first object into the
+// second.
+func (api *ObjectAPI) Diff(ctx context.Context, before coreiface.Path, after coreiface.Path, opts ...caopts.ObjectDiffOption) ([]coreiface.ObjectChange, error) {
+	settings, err := caopts.ObjectDiffOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Changes []*change
+	}
+
+	err = api.core().request("object/diff", before.String(), after.String()).
+		Option("depth", settings.Depth).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]coreiface.ObjectChange, len(out.Changes))
+	for i, c := range out.Changes {
+		res[i] = c.objectChange(coreiface.Dms3FsPath)
+	}
+
+	return res, nil
+}
+
+// change is the wire format of the changes reported by the object diff and
+// dag merge commands
+type change struct {
+	Type   coreiface.ChangeType
+	Path   string
+	Before *cid.Cid
+	After  *cid.Cid
+}
+
+func (c *change) objectChange(mkPath func(*cid.Cid) coreiface.ResolvedPath) coreiface.ObjectChange {
+	out := coreiface.ObjectChange{
+		Type: c.Type,
+		Path: c.Path,
+	}
+
+	if c.Before != nil {
+		out.Before = mkPath(c.Before)
+	}
+
+	if c.After != nil {
+		out.After = mkPath(c.After)
+	}
+
+	return out
+}
+
+func (api *ObjectAPI) core() *HttpApi {
+	return (*HttpApi)(api)
+}
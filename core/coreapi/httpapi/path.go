@@ -0,0 +1,86 @@
+package httpapi
+
+import (
+	"context"
+	"io/ioutil"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+
+	blocks "github.com/dms3-fs/go-block-format"
+	cid "github.com/dms3-fs/go-cid"
+	dms3ld "github.com/dms3-fs/go-ld-format"
+	dms3fspath "github.com/dms3-fs/go-path"
+
+	// registers the dag-pb, raw and dag-cbor block decoders
+	_ "github.com/dms3-fs/go-merkledag"
+)
+
+// ResolvePath resolves the path `p` on the daemon, returns the resolved path.
+func (api *HttpApi) ResolvePath(ctx context.Context, p coreiface.Path) (coreiface.ResolvedPath, error) {
+	if rp, ok := p.(coreiface.ResolvedPath); ok {
+		return rp, nil
+	}
+
+	if p.Mutable() {
+		var out struct {
+			Path string
+		}
+
+		err := api.request("name/resolve", p.String()).
+			Option("recursive", true).
+			Exec(ctx, &out)
+		if err != nil {
+			return nil, err
+		}
+
+		p, err = coreiface.ParsePath(out.Path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var out struct {
+		Cid     *cid.Cid
+		RemPath string
+	}
+
+	err := api.request("dag/resolve", p.String()).Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	ipath := dms3fspath.Path(p.String())
+	root, err := cid.Parse(ipath.Segments()[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return coreiface.NewResolvedPath(ipath, out.Cid, root, out.RemPath), nil
+}
+
+// ResolveNode resolves the path `p` on the daemon, fetches the raw block of
+// the resolved node and decodes it.
+func (api *HttpApi) ResolveNode(ctx context.Context, p coreiface.Path) (dms3ld.Node, error) {
+	rp, err := api.ResolvePath(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := api.request("block/get", rp.Cid().String()).ExecRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := blocks.NewBlockWithCid(data, rp.Cid())
+	if err != nil {
+		return nil, err
+	}
+
+	return dms3ld.Decode(b)
+}
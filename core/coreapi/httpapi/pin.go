@@ -0,0 +1,278 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	cid "github.com/dms3-fs/go-cid"
+)
+
+type PinAPI HttpApi
+
+type pinInfo struct {
+	pinType string
+	path    coreiface.ResolvedPath
+	name    string
+	meta    map[string]string
+	created time.Time
+	expires time.Time
+	owners  []string
+}
+
+func (p *pinInfo) Path() coreiface.ResolvedPath {
+	return p.path
+}
+
+func (p *pinInfo) Type() string {
+	return p.pinType
+}
+
+func (p *pinInfo) Name() string {
+	return p.name
+}
+
+func (p *pinInfo) Meta() map[string]string {
+	return p.meta
+}
+
+func (p *pinInfo) Created() time.Time {
+	return p.created
+}
+
+func (p *pinInfo) Expires() time.Time {
+	return p.expires
+}
+
+func (p *pinInfo) Owners() []string {
+	return p.owners
+}
+
+// parseTime parses a time of the pin/ls output, which is empty if unset
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// metaOption encodes pin metadata as the comma-separated key=value pairs the
+// --meta option of the pin commands takes
+func metaOption(meta map[string]string) string {
+	pairs := make([]string, 0, len(meta))
+	for k, v := range meta {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+type pinStatus struct {
+	ok       bool
+	badNodes []coreiface.BadPinNode
+}
+
+func (s *pinStatus) Ok() bool {
+	return s.ok
+}
+
+func (s *pinStatus) BadNodes() []coreiface.BadPinNode {
+	return s.badNodes
+}
+
+type badNode struct {
+	path coreiface.ResolvedPath
+	err  error
+}
+
+func (n *badNode) Path() coreiface.ResolvedPath {
+	return n.path
+}
+
+func (n *badNode) Err() error {
+	return n.err
+}
+
+func (api *PinAPI) Add(ctx context.Context, p coreiface.Path, opts ...caopts.PinAddOption) error {
+	settings, err := caopts.PinAddOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	return api.core().request("pin/add", p.String()).
+		Option("recursive", settings.Recursive).
+		Option("name", settings.Name).
+		Option("meta", metaOption(settings.Meta)).
+		Option("expire-in", settings.ExpireIn.String()).
+		Option("owner", settings.Owner).
+		Exec(ctx, nil)
+}
+
+func (api *PinAPI) Ls(ctx context.Context, opts ...caopts.PinLsOption) ([]coreiface.Pin, error) {
+	settings, err := caopts.PinLsOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Keys map[string]struct {
+			Type    string
+			Name    string
+			Meta    map[string]string
+			Created string
+			Expires string
+			Owners  []string
+		}
+	}
+
+	err = api.core().request("pin/ls").
+		Option("type", settings.Type).
+		Option("name", settings.Name).
+		Option("meta", metaOption(settings.Meta)).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	pins := make([]coreiface.Pin, 0, len(out.Keys))
+	for k, v := range out.Keys {
+		c, err := cid.Parse(k)
+		if err != nil {
+			return nil, err
+		}
+
+		created, err := parseTime(v.Created)
+		if err != nil {
+			return nil, err
+		}
+
+		expires, err := parseTime(v.Expires)
+		if err != nil {
+			return nil, err
+		}
+
+		pins = append(pins, &pinInfo{
+			pinType: v.Type,
+			path:    coreiface.Dms3LdPath(c),
+			name:    v.Name,
+			meta:    v.Meta,
+			created: created,
+			expires: expires,
+			owners:  v.Owners,
+		})
+	}
+
+	return pins, nil
+}
+
+// LsAsync streams the pins of the daemon. The daemon returns the whole set at
+// once, so the pins are only streamed on the client side
+func (api *PinAPI) LsAsync(ctx context.Context, opts ...caopts.PinLsOption) (<-chan coreiface.PinLsResult, error) {
+	pins, err := api.Ls(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan coreiface.PinLsResult)
+	go func() {
+		defer close(out)
+		for _, p := range pins {
+			select {
+			case out <- coreiface.PinLsResult{Pin: p}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (api *PinAPI) Rm(ctx context.Context, p coreiface.Path, opts ...caopts.PinRmOption) error {
+	settings, err := caopts.PinRmOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	return api.core().request("pin/rm", p.String()).
+		Option("recursive", true).
+		Option("owner", settings.Owner).
+		Exec(ctx, nil)
+}
+
+func (api *PinAPI) Update(ctx context.Context, from coreiface.Path, to coreiface.Path, opts ...caopts.PinUpdateOption) error {
+	settings, err := caopts.PinUpdateOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	return api.core().request("pin/update", from.String(), to.String()).
+		Option("unpin", settings.Unpin).
+		Exec(ctx, nil)
+}
+
+func (api *PinAPI) Verify(ctx context.Context) (<-chan coreiface.PinStatus, error) {
+	res, err := api.core().request("pin/verify").
+		Option("verbose", true).
+		Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan coreiface.PinStatus)
+	go func() {
+		defer close(out)
+		defer res.Close()
+
+		for {
+			var v struct {
+				Cid      string
+				Ok       bool
+				BadNodes []struct {
+					Cid string
+					Err string
+				}
+			}
+
+			err := res.Decode(&v)
+			if err != nil {
+				if err != io.EOF {
+					log.Errorf("pin verify: %s", err)
+				}
+				return
+			}
+
+			status := &pinStatus{ok: v.Ok}
+			for _, bn := range v.BadNodes {
+				c, err := cid.Parse(bn.Cid)
+				if err != nil {
+					log.Errorf("pin verify: %s", err)
+					return
+				}
+
+				status.badNodes = append(status.badNodes, &badNode{
+					path: coreiface.Dms3LdPath(c),
+					err:  errors.New(bn.Err),
+				})
+			}
+
+			select {
+			case out <- status:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (api *PinAPI) core() *HttpApi {
+	return (*HttpApi)(api)
+}
@@ -0,0 +1,193 @@
+package httpapi
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	peer "github.com/dms3-p2p/go-p2p-peer"
+)
+
+type PubSubAPI HttpApi
+
+// pubSubSubscription reads the messages from the streaming output of the
+// pubsub sub command
+type pubSubSubscription struct {
+	res *response
+
+	results chan pubSubResult
+	closed  chan struct{}
+	once    sync.Once
+}
+
+type pubSubResult struct {
+	msg coreiface.PubSubMessage
+	err error
+}
+
+type pubSubMessage struct {
+	from   peer.ID
+	data   []byte
+	seq    []byte
+	topics []string
+}
+
+// Ls lists the topics the daemon is subscribed to
+func (api *PubSubAPI) Ls(ctx context.Context) ([]string, error) {
+	var out struct {
+		Strings []string
+	}
+
+	err := api.core().request("pubsub/ls").Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Strings, nil
+}
+
+// Peers lists the peers the daemon is pubsubbing with
+func (api *PubSubAPI) Peers(ctx context.Context, opts ...caopts.PubSubPeersOption) ([]peer.ID, error) {
+	settings, err := caopts.PubSubPeersOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	req := api.core().request("pubsub/peers")
+	if settings.Topic != "" {
+		req.Arguments(settings.Topic)
+	}
+
+	var out struct {
+		Strings []string
+	}
+
+	err = req.Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]peer.ID, len(out.Strings))
+	for i, s := range out.Strings {
+		res[i], err = peer.IDB58Decode(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// Publish publishes the message to the topic through the daemon
+func (api *PubSubAPI) Publish(ctx context.Context, topic string, data []byte) error {
+	return api.core().request("pubsub/pub", topic, string(data)).Exec(ctx, nil)
+}
+
+// Subscribe subscribes the daemon to the topic, and streams the messages it
+// receives until the subscription is closed
+func (api *PubSubAPI) Subscribe(ctx context.Context, topic string, opts ...caopts.PubSubSubscribeOption) (coreiface.PubSubSubscription, error) {
+	settings, err := caopts.PubSubSubscribeOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := api.core().request("pubsub/sub", topic).
+		Option("discover", settings.Discover).
+		Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &pubSubSubscription{
+		res:     res,
+		results: make(chan pubSubResult),
+		closed:  make(chan struct{}),
+	}
+	go sub.read()
+
+	return sub, nil
+}
+
+// read decodes the messages of the output until it fails or the subscription
+// is closed
+func (sub *pubSubSubscription) read() {
+	defer close(sub.results)
+
+	for {
+		var out struct {
+			From     []byte   `json:"from,omitempty"`
+			Data     []byte   `json:"data,omitempty"`
+			Seqno    []byte   `json:"seqno,omitempty"`
+			TopicIDs []string `json:"topicIDs,omitempty"`
+		}
+
+		var r pubSubResult
+		r.err = sub.res.Decode(&out)
+		if r.err == nil {
+			r.msg = &pubSubMessage{
+				from:   peer.ID(out.From),
+				data:   out.Data,
+				seq:    out.Seqno,
+				topics: out.TopicIDs,
+			}
+		}
+
+		select {
+		case sub.results <- r:
+		case <-sub.closed:
+			return
+		}
+
+		if r.err != nil {
+			return
+		}
+	}
+}
+
+func (sub *pubSubSubscription) Close() error {
+	var err error
+	sub.once.Do(func() {
+		close(sub.closed)
+		err = sub.res.Close()
+	})
+	return err
+}
+
+// Next returns the next message received on the subscription. It returns
+// io.EOF when the subscription ended
+func (sub *pubSubSubscription) Next(ctx context.Context) (coreiface.PubSubMessage, error) {
+	select {
+	case r, ok := <-sub.results:
+		if !ok {
+			return nil, io.EOF
+		}
+		return r.msg, r.err
+	case <-sub.closed:
+		return nil, io.EOF
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (msg *pubSubMessage) From() peer.ID {
+	return msg.from
+}
+
+func (msg *pubSubMessage) Data() []byte {
+	return msg.data
+}
+
+func (msg *pubSubMessage) Seq() []byte {
+	return msg.seq
+}
+
+func (msg *pubSubMessage) Topics() []string {
+	return msg.topics
+}
+
+func (api *PubSubAPI) core() *HttpApi {
+	return (*HttpApi)(api)
+}
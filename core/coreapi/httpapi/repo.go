@@ -0,0 +1,165 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	cid "github.com/dms3-fs/go-cid"
+)
+
+// errRepoFsckUnsupported is returned by RepoAPI.Fsck, as the repo fsck
+// command can't run on a daemon
+var errRepoFsckUnsupported = errors.New("repo fsck: removing lockfiles is not supported over the HTTP API")
+
+type RepoAPI HttpApi
+
+// Gc asks the daemon to run a garbage collection sweep, and streams the
+// removed blocks and the errors it reports
+func (api *RepoAPI) Gc(ctx context.Context) (<-chan coreiface.RepoGcResult, error) {
+	res, err := api.core().request("repo/gc").
+		Option("stream-errors", true).
+		Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan coreiface.RepoGcResult)
+	go func() {
+		defer close(out)
+		defer res.Close()
+
+		errs := false
+		for {
+			var v struct {
+				Key   *cid.Cid
+				Error string
+			}
+
+			r := coreiface.RepoGcResult{}
+			err := res.Decode(&v)
+			switch {
+			case err == io.EOF:
+				return
+			case err != nil:
+				// the daemon fails the command once done if errors were
+				// streamed, which were already sent
+				if errs {
+					return
+				}
+				r.Error = err
+			case v.Error != "":
+				errs = true
+				r.Error = errors.New(v.Error)
+			default:
+				r.KeyRemoved = v.Key
+			}
+
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// Stat returns the size and object count of the repo of the daemon
+func (api *RepoAPI) Stat(ctx context.Context, opts ...caopts.RepoStatOption) (*coreiface.RepoStat, error) {
+	settings, err := caopts.RepoStatOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out coreiface.RepoStat
+	err = api.core().request("repo/stat").
+		Option("size-only", settings.SizeOnly).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// Fsck is not supported over the HTTP API, as the daemon itself holds the
+// lockfiles of its repo
+func (api *RepoAPI) Fsck(ctx context.Context, repoPath string) error {
+	return errRepoFsckUnsupported
+}
+
+// Verify asks the daemon to verify the blocks of its repo, and streams the
+// status it reports for each of them
+func (api *RepoAPI) Verify(ctx context.Context) (<-chan coreiface.RepoVerifyResult, error) {
+	res, err := api.core().request("repo/verify").Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan coreiface.RepoVerifyResult)
+	go func() {
+		defer close(out)
+		defer res.Close()
+
+		var corrupt error
+		fails := false
+		for {
+			var v struct {
+				Msg      string
+				Progress int
+				Cid      *cid.Cid
+			}
+
+			r := coreiface.RepoVerifyResult{}
+			err := res.Decode(&v)
+			switch {
+			case err == io.EOF:
+				return
+			case err != nil:
+				// the daemon fails the command once done if corrupt blocks
+				// were found, which were already sent
+				if fails {
+					return
+				}
+				r.Err = err
+			case v.Cid == nil:
+				// summary message
+				continue
+			case v.Progress == 0:
+				// the corrupt block message precedes the block progress
+				corrupt = errors.New(v.Msg)
+				fails = true
+				continue
+			default:
+				r.Cid = v.Cid
+				r.Corrupt = corrupt
+				corrupt = nil
+			}
+
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (api *RepoAPI) core() *HttpApi {
+	return (*HttpApi)(api)
+}
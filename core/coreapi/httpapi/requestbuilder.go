@@ -0,0 +1,138 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	files "github.com/dms3-fs/go-fs-cmdkit/files"
+)
+
+// requestBuilder builds a single call to an API command
+type requestBuilder struct {
+	command     string
+	args        []string
+	opts        map[string]string
+	body        io.Reader
+	contentType string
+
+	api *HttpApi
+}
+
+// Arguments adds positional arguments to the request
+func (r *requestBuilder) Arguments(args ...string) *requestBuilder {
+	r.args = append(r.args, args...)
+	return r
+}
+
+// Option sets an option of the request
+func (r *requestBuilder) Option(key string, value interface{}) *requestBuilder {
+	var s string
+	switch v := value.(type) {
+	case bool:
+		s = strconv.FormatBool(v)
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		s = fmt.Sprint(value)
+	}
+
+	if r.opts == nil {
+		r.opts = make(map[string]string, 1)
+	}
+	r.opts[key] = s
+	return r
+}
+
+// Body sets the reader as the single file argument of the request
+func (r *requestBuilder) Body(body io.Reader) *requestBuilder {
+	return r.FileBody(files.NewReaderFile("", "", ioutil.NopCloser(body), nil))
+}
+
+// FileBody sets the file, or the directory tree, as the file argument of the
+// request
+func (r *requestBuilder) FileBody(f files.File) *requestBuilder {
+	mfr := files.NewMultiFileReader(files.NewSliceFile("", "", []files.File{f}), true)
+	r.body = mfr
+	r.contentType = "multipart/form-data; boundary=" + mfr.Boundary()
+	return r
+}
+
+// Send sends the request and returns the response. Command errors are
+// returned as *Error
+func (r *requestBuilder) Send(ctx context.Context) (*response, error) {
+	values := url.Values{}
+	for _, arg := range r.args {
+		values.Add("arg", arg)
+	}
+	for k, v := range r.opts {
+		values.Set(k, v)
+	}
+	values.Set("encoding", "json")
+	values.Set("stream-channels", "true")
+
+	u := fmt.Sprintf("%s/%s?%s", r.api.url, r.command, values.Encode())
+
+	req, err := http.NewRequest("POST", u, r.body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+
+	resp, err := r.api.httpcli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, readError(r.command, resp)
+	}
+
+	return newResponse(r.command, resp), nil
+}
+
+// Exec sends the request, decodes the first value of the response into res,
+// if not nil, and discards the rest
+func (r *requestBuilder) Exec(ctx context.Context, res interface{}) error {
+	resp, err := r.Send(ctx)
+	if err != nil {
+		return err
+	}
+	defer resp.Close()
+
+	if res == nil {
+		return resp.Drain()
+	}
+
+	err = resp.Decode(res)
+	if err == io.EOF {
+		return fmt.Errorf("%s: empty response", r.command)
+	}
+	if err != nil {
+		return err
+	}
+
+	return resp.Drain()
+}
+
+// ExecRaw sends the request and returns the raw response output. The caller
+// must close it
+func (r *requestBuilder) ExecRaw(ctx context.Context) (io.ReadCloser, error) {
+	resp, err := r.Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,177 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+)
+
+// streamErrHeader is the trailer set by the daemon when a command fails after
+// its output started streaming
+const streamErrHeader = "X-Stream-Error"
+
+// notOnlineMsg is the message of the error returned by the commands which
+// need the daemon to be online, such as the bitswap ones. It is reported as
+// ErrOffline
+const notOnlineMsg = "this command must be run in online mode. Try running 'dms3fs daemon' first"
+
+// knownErrors maps the messages of the coreiface errors back to the errors,
+// so that callers can compare them the same way as with the local API
+var knownErrors = []error{
+	coreiface.ErrIsDir,
+	coreiface.ErrOffline,
+	coreiface.ErrNotConnected,
+	coreiface.ErrConnNotFound,
+}
+
+// Error is an error returned by the daemon while running an API command
+type Error struct {
+	Command string
+	Message string
+	Code    int
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// response is the output of an API command
+type response struct {
+	command string
+	resp    *http.Response
+	output  io.Reader
+	dec     *json.Decoder
+}
+
+func newResponse(command string, resp *http.Response) *response {
+	return &response{
+		command: command,
+		resp:    resp,
+		output:  &trailerReader{command: command, resp: resp},
+	}
+}
+
+// Read reads the raw output of the command
+func (r *response) Read(p []byte) (int, error) {
+	return r.output.Read(p)
+}
+
+// Close closes the response, cancelling the command if it is still running
+func (r *response) Close() error {
+	return r.resp.Body.Close()
+}
+
+// Decode decodes the next JSON value of the output into v. It returns io.EOF
+// when the output is exhausted
+func (r *response) Decode(v interface{}) error {
+	if r.dec == nil {
+		r.dec = json.NewDecoder(r.output)
+	}
+
+	var raw json.RawMessage
+	err := r.dec.Decode(&raw)
+	if err != nil {
+		return err
+	}
+
+	if err := valueError(r.command, raw); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+// Drain reads the remaining values of the output, returning the first error
+// found in it
+func (r *response) Drain() error {
+	for {
+		var v json.RawMessage
+		err := r.Decode(&v)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// trailerReader reads the body of a response and reports the error set in its
+// trailer, if any, instead of io.EOF
+type trailerReader struct {
+	command string
+	resp    *http.Response
+}
+
+func (r *trailerReader) Read(p []byte) (int, error) {
+	n, err := r.resp.Body.Read(p)
+	if err == io.EOF {
+		if msg := r.resp.Trailer.Get(streamErrHeader); msg != "" {
+			return n, knownError(&Error{Command: r.command, Message: msg})
+		}
+	}
+	return n, err
+}
+
+// valueError returns the error encoded in a value of the output stream, if
+// the value is an error
+func valueError(command string, raw json.RawMessage) error {
+	if len(raw) == 0 || raw[0] != '{' {
+		return nil
+	}
+
+	var e struct {
+		Type    json.RawMessage
+		Message string
+		Code    int
+	}
+	if err := json.Unmarshal(raw, &e); err != nil || string(e.Type) != `"error"` {
+		return nil
+	}
+
+	return knownError(&Error{Command: command, Message: e.Message, Code: e.Code})
+}
+
+// readError reads the error from the response of a failed command
+func readError(command string, resp *http.Response) error {
+	e := &Error{Command: command}
+
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mt {
+	case "application/json":
+		err := json.NewDecoder(resp.Body).Decode(e)
+		if err != nil {
+			e.Message = fmt.Sprintf("%s (%s)", resp.Status, err)
+		}
+	default:
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		e.Message = strings.TrimSpace(string(body))
+	}
+
+	if e.Message == "" {
+		e.Message = resp.Status
+	}
+
+	return knownError(e)
+}
+
+func knownError(e *Error) error {
+	if e.Message == notOnlineMsg {
+		return coreiface.ErrOffline
+	}
+	for _, known := range knownErrors {
+		if e.Message == known.Error() {
+			return known
+		}
+	}
+	return e
+}
@@ -0,0 +1,270 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	ma "github.com/dms3-mft/go-multiaddr"
+	net "github.com/dms3-p2p/go-p2p-net"
+	peer "github.com/dms3-p2p/go-p2p-peer"
+	pstore "github.com/dms3-p2p/go-p2p-peerstore"
+	protocol "github.com/dms3-p2p/go-p2p-protocol"
+)
+
+type SwarmAPI HttpApi
+
+// connInfo implements coreiface.ConnectionInfo from the output of the swarm
+// peers command. The daemon doesn't report the direction of connections
+type connInfo struct {
+	peer    peer.ID
+	addr    ma.Multiaddr
+	latency time.Duration
+	streams []protocol.ID
+}
+
+func (ci *connInfo) ID() peer.ID {
+	return ci.peer
+}
+
+func (ci *connInfo) Address() ma.Multiaddr {
+	return ci.addr
+}
+
+func (ci *connInfo) Direction() net.Direction {
+	return net.DirUnknown
+}
+
+func (ci *connInfo) Latency() (time.Duration, error) {
+	return ci.latency, nil
+}
+
+func (ci *connInfo) Streams() ([]protocol.ID, error) {
+	return ci.streams, nil
+}
+
+// Connect connects the daemon to the given peer
+func (api *SwarmAPI) Connect(ctx context.Context, pi pstore.PeerInfo) error {
+	pidma, err := ma.NewMultiaddr(fmt.Sprintf("/%s/%s", ma.ProtocolWithCode(ma.P_DMS3FS).Name, pi.ID.Pretty()))
+	if err != nil {
+		return err
+	}
+
+	addrs := make([]string, len(pi.Addrs))
+	for i, addr := range pi.Addrs {
+		addrs[i] = addr.Encapsulate(pidma).String()
+	}
+	if len(addrs) == 0 {
+		addrs = []string{pidma.String()}
+	}
+
+	return api.core().request("swarm/connect", addrs...).Exec(ctx, nil)
+}
+
+// Disconnect closes the connections of the daemon to the address `addr`. If
+// the address doesn't contain the transport part, all connections to the peer
+// are closed.
+func (api *SwarmAPI) Disconnect(ctx context.Context, addr ma.Multiaddr) error {
+	var out struct {
+		Strings []string
+	}
+
+	err := api.core().request("swarm/disconnect", addr.String()).Exec(ctx, &out)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range out.Strings {
+		i := strings.Index(s, " failure: ")
+		if i < 0 {
+			continue
+		}
+
+		return knownError(&Error{
+			Command: "swarm/disconnect",
+			Message: s[i+len(" failure: "):],
+		})
+	}
+
+	return nil
+}
+
+// KnownAddrs returns the addresses of all the peers the daemon knows about.
+func (api *SwarmAPI) KnownAddrs(ctx context.Context) (map[peer.ID][]ma.Multiaddr, error) {
+	var out struct {
+		Addrs map[string][]string
+	}
+
+	err := api.core().request("swarm/addrs").Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[peer.ID][]ma.Multiaddr, len(out.Addrs))
+	for spid, saddrs := range out.Addrs {
+		pid, err := peer.IDB58Decode(spid)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs, err := parseAddrs(saddrs)
+		if err != nil {
+			return nil, err
+		}
+		res[pid] = addrs
+	}
+
+	return res, nil
+}
+
+// LocalAddrs returns the listening addresses the daemon announces to the
+// network.
+func (api *SwarmAPI) LocalAddrs(ctx context.Context) ([]ma.Multiaddr, error) {
+	var out struct {
+		Strings []string
+	}
+
+	err := api.core().request("swarm/addrs/local").Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseAddrs(out.Strings)
+}
+
+// ListenAddrs returns all interface addresses the daemon is listening on.
+func (api *SwarmAPI) ListenAddrs(ctx context.Context) ([]ma.Multiaddr, error) {
+	var out struct {
+		Strings []string
+	}
+
+	err := api.core().request("swarm/addrs/listen").Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseAddrs(out.Strings)
+}
+
+// Peers returns the list of peers the daemon has open connections to.
+func (api *SwarmAPI) Peers(ctx context.Context) ([]coreiface.ConnectionInfo, error) {
+	var out struct {
+		Peers []struct {
+			Addr    string
+			Peer    string
+			Latency string
+			Streams []struct {
+				Protocol string
+			}
+		}
+	}
+
+	err := api.core().request("swarm/peers").
+		Option("streams", true).
+		Option("latency", true).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]coreiface.ConnectionInfo, len(out.Peers))
+	for i, p := range out.Peers {
+		ci := &connInfo{}
+
+		ci.peer, err = peer.IDB58Decode(p.Peer)
+		if err != nil {
+			return nil, err
+		}
+
+		ci.addr, err = ma.NewMultiaddr(p.Addr)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.Latency != "" && p.Latency != "n/a" {
+			ci.latency, err = time.ParseDuration(p.Latency)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, s := range p.Streams {
+			ci.streams = append(ci.streams, protocol.ID(s.Protocol))
+		}
+
+		res[i] = ci
+	}
+
+	return res, nil
+}
+
+// Filters returns the address filters currently applied to the swarm of the
+// daemon.
+func (api *SwarmAPI) Filters(ctx context.Context) ([]string, error) {
+	var out struct {
+		Strings []string
+	}
+
+	err := api.core().request("swarm/filters").Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Strings, nil
+}
+
+// FilterAdd adds an address filter to the swarm of the daemon. The daemon
+// always adds the filter to its config as well, so the Persist option must be
+// set.
+func (api *SwarmAPI) FilterAdd(ctx context.Context, filter string, opts ...caopts.SwarmFilterOption) error {
+	settings, err := caopts.SwarmFilterOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	if !settings.Persist {
+		return errNotPersisted
+	}
+
+	return api.core().request("swarm/filters/add", filter).Exec(ctx, nil)
+}
+
+// FilterRm removes an address filter from the swarm of the daemon. The daemon
+// always removes the filter from its config as well, so the Persist option
+// must be set.
+func (api *SwarmAPI) FilterRm(ctx context.Context, filter string, opts ...caopts.SwarmFilterOption) error {
+	settings, err := caopts.SwarmFilterOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	if !settings.Persist {
+		return errNotPersisted
+	}
+
+	return api.core().request("swarm/filters/rm", filter).Exec(ctx, nil)
+}
+
+var errNotPersisted = errors.New("swarm filters: the daemon always persists filter changes, set the Persist option")
+
+func parseAddrs(saddrs []string) ([]ma.Multiaddr, error) {
+	addrs := make([]ma.Multiaddr, len(saddrs))
+	for i, s := range saddrs {
+		a, err := ma.NewMultiaddr(s)
+		if err != nil {
+			return nil, err
+		}
+		addrs[i] = a
+	}
+
+	return addrs, nil
+}
+
+func (api *SwarmAPI) core() *HttpApi {
+	return (*HttpApi)(api)
+}
@@ -0,0 +1,274 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	cid "github.com/dms3-fs/go-cid"
+	files "github.com/dms3-fs/go-fs-cmdkit/files"
+	dms3ld "github.com/dms3-fs/go-ld-format"
+	mh "github.com/dms3-mft/go-multihash"
+)
+
+type UnixfsAPI HttpApi
+
+// Add uploads the file, or the directory tree, to the daemon which imports it
+// into the merkledag, and returns the path of the resulting root node
+func (api *UnixfsAPI) Add(ctx context.Context, f files.File, opts ...caopts.UnixfsAddOption) (coreiface.ResolvedPath, error) {
+	settings, _, err := caopts.UnixfsAddOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	mht, ok := mh.Codes[settings.MhType]
+	if !ok {
+		return nil, fmt.Errorf("unknown multihash type %d", settings.MhType)
+	}
+
+	// the root hash is read from the output, so the output is never silenced
+	// on the daemon side
+	req := api.core().request("add").
+		Option("cid-version", settings.CidVersion).
+		Option("hash", mht).
+		Option("inline", settings.Inline).
+		Option("inline-limit", settings.InlineLimit).
+		Option("raw-leaves", settings.RawLeaves).
+		Option("chunker", settings.Chunker).
+		Option("trickle", settings.Layout == caopts.TrickleLayout).
+		Option("pin", settings.Pin).
+		Option("only-hash", settings.OnlyHash).
+		Option("local", settings.Local).
+		Option("fscache", settings.FsCache).
+		Option("nocopy", settings.NoCopy).
+		Option("wrap-with-directory", settings.Wrap).
+		Option("hidden", settings.Hidden).
+		Option("progress", settings.Progress && settings.Events != nil).
+		FileBody(f)
+
+	res, err := req.Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	var root string
+	for {
+		ev := new(coreiface.AddEvent)
+		err := res.Decode(ev)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if ev.Hash != "" {
+			root = ev.Hash
+		}
+
+		if settings.Events == nil || (settings.Silent && ev.Hash != "") {
+			continue
+		}
+
+		select {
+		case settings.Events <- ev:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if root == "" {
+		return nil, errors.New("add: no root returned by the daemon")
+	}
+
+	c, err := cid.Decode(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return coreiface.Dms3FsPath(c), nil
+}
+
+// Cat returns a reader for the file at the path `p`. Seeking the reader
+// restarts the transfer at the new offset
+func (api *UnixfsAPI) Cat(ctx context.Context, p coreiface.Path) (coreiface.Reader, error) {
+	r := &catReader{
+		ctx:  ctx,
+		api:  api,
+		path: p.String(),
+		size: -1,
+	}
+
+	// open eagerly, so that errors such as missing files or directories are
+	// reported here rather than on the first read
+	err := r.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Ls returns the contents of the directory at the path `p`
+func (api *UnixfsAPI) Ls(ctx context.Context, p coreiface.Path) ([]*dms3ld.Link, error) {
+	var out struct {
+		Objects []struct {
+			Hash  string
+			Links []struct {
+				Name, Hash string
+				Size       uint64
+			}
+		}
+	}
+
+	err := api.core().request("ls", p.String()).
+		Option("resolve-type", false).
+		Exec(ctx, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(out.Objects) != 1 {
+		return nil, fmt.Errorf("ls: expected 1 object, got %d", len(out.Objects))
+	}
+
+	links := make([]*dms3ld.Link, len(out.Objects[0].Links))
+	for i, l := range out.Objects[0].Links {
+		c, err := cid.Decode(l.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		links[i] = &dms3ld.Link{
+			Name: l.Name,
+			Size: l.Size,
+			Cid:  c,
+		}
+	}
+
+	return links, nil
+}
+
+// LsAsync streams the contents of the directory at the path `p`. The daemon
+// returns the whole listing at once, so the links are only streamed on the
+// client side
+func (api *UnixfsAPI) LsAsync(ctx context.Context, p coreiface.Path) (<-chan coreiface.UnixfsLsResult, error) {
+	links, err := api.Ls(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan coreiface.UnixfsLsResult)
+	go func() {
+		defer close(out)
+		for _, l := range links {
+			select {
+			case out <- coreiface.UnixfsLsResult{Link: l}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (api *UnixfsAPI) core() *HttpApi {
+	return (*HttpApi)(api)
+}
+
+// catReader implements coreiface.Reader over the output of the cat command
+type catReader struct {
+	ctx  context.Context
+	api  *UnixfsAPI
+	path string
+
+	r      io.ReadCloser
+	offset int64
+	size   int64
+}
+
+func (r *catReader) open() error {
+	rc, err := r.api.core().request("cat", r.path).
+		Option("offset", r.offset).
+		ExecRaw(r.ctx)
+	if err != nil {
+		return err
+	}
+
+	r.r = rc
+	return nil
+}
+
+func (r *catReader) Read(p []byte) (int, error) {
+	if r.r == nil {
+		err := r.open()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.r.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *catReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		size, err := r.fileSize()
+		if err != nil {
+			return r.offset, err
+		}
+		offset += size
+	default:
+		return r.offset, errors.New("invalid whence")
+	}
+
+	if offset < 0 {
+		return r.offset, errors.New("invalid offset")
+	}
+
+	if offset != r.offset {
+		r.Close()
+		r.offset = offset
+	}
+
+	return r.offset, nil
+}
+
+func (r *catReader) Close() error {
+	if r.r == nil {
+		return nil
+	}
+
+	err := r.r.Close()
+	r.r = nil
+	return err
+}
+
+func (r *catReader) fileSize() (int64, error) {
+	if r.size >= 0 {
+		return r.size, nil
+	}
+
+	var out struct {
+		Size uint64
+	}
+
+	err := r.api.core().request("files/stat", r.path).Exec(r.ctx, &out)
+	if err != nil {
+		return 0, err
+	}
+
+	r.size = int64(out.Size)
+	return r.size, nil
+}
@@ -2,109 +2,579 @@ package coreapi
 
 import (
 	"context"
-//	"io"
+	"errors"
+	"fmt"
+	"os"
+	gopath "path"
+	"path/filepath"
+	"strconv"
+	"time"
 
 	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+	idxkvs "github.com/dms3-fs/go-dms3-fs/core/coreindex/kvs"
+	idxlfs "github.com/dms3-fs/go-dms3-fs/core/coreindex/lfs"
+	idxufs "github.com/dms3-fs/go-dms3-fs/core/coreindex/ufs"
+	"github.com/dms3-fs/go-dms3-fs/pin"
+	dag "github.com/dms3-fs/go-merkledag"
 
-	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
-//	ipath "github.com/dms3-fs/go-path"
+	cid "github.com/dms3-fs/go-cid"
+	dsquery "github.com/dms3-fs/go-datastore/query"
+	files "github.com/dms3-fs/go-fs-cmdkit/files"
 	logging "github.com/dms3-fs/go-log"
 )
 
 // log is the command logger
 var log = logging.Logger("command/index")
 
+const (
+	infostoreClass = "infostore"
+	metastoreClass = "metastore"
+
+	reposetPropsName = "reposetprops"
+	reposetDirName   = "reposetdir"
+)
+
 type IndexAPI CoreAPI
 
-type repoList struct {
-	name 	string
-	id 		string
+type reposet struct {
+	class string
+	kind  string
+	name  string
+	path  coreiface.ResolvedPath
+}
+
+// Class returns the reposet class.
+func (r *reposet) Class() string {
+	return r.class
+}
+
+// Kind returns the reposet kind.
+func (r *reposet) Kind() string {
+	return r.kind
 }
 
-// Name returns the repository name.
-func (r *repoList) Name() string {
+// Name returns the reposet name.
+func (r *reposet) Name() string {
 	return r.name
 }
 
-// Id returns the repository id.
-func (r *repoList) Id() string {
-	return r.id
+// Path returns the path to the reposet root directory.
+func (r *reposet) Path() coreiface.ResolvedPath {
+	return r.path
+}
+
+// List returns the reposets registered in the index key value store which
+// match the kind, name and class filters, one page at a time.
+func (api *IndexAPI) List(ctx context.Context, opts ...caopts.IndexListOption) ([]coreiface.Reposet, error) {
+	settings, err := caopts.IndexListOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.Offset < 0 {
+		return nil, fmt.Errorf("page offset must not be negative")
+	}
+
+	if settings.Length < 1 {
+		return nil, fmt.Errorf("page length must be greater than 0")
+	}
+
+	res, err := api.kvstore().Query(dsquery.Query{Prefix: idxkvs.GetRepoSetPrefix().String()})
+	if err != nil {
+		return nil, fmt.Errorf("cannot issue query request: %v", err)
+	}
+	defer res.Close()
+
+	skip := settings.Offset * settings.Length
+	out := []coreiface.Reposet{}
+
+	for result := range res.Next() {
+		if result.Error != nil {
+			return nil, fmt.Errorf("query returned internal error: %v", result.Error)
+		}
+
+		class, kind, name, err := idxkvs.DecomposeRepoSetKey(result.Key)
+		if err != nil {
+			// corpus document keys share the reposet prefix
+			continue
+		}
+
+		if !((settings.Metastore && class == metastoreClass) || (settings.Infostore && class == infostoreClass)) {
+			continue
+		}
+
+		if settings.Kind != "" && kind != "" && settings.Kind != kind {
+			continue
+		}
+
+		if settings.Name != "" && settings.Name != name {
+			continue
+		}
+
+		if skip > 0 {
+			skip--
+			continue
+		}
+
+		v := idxkvs.NewRps()
+		if err := v.Unmarshal(result.Value); err != nil {
+			return nil, err
+		}
+
+		out = append(out, &reposet{
+			class: class,
+			kind:  kind,
+			name:  name,
+			path:  coreiface.Dms3FsPath(v.GetCid()),
+		})
+
+		if len(out) == settings.Length {
+			break
+		}
+	}
+
+	return out, nil
+}
+
+// MakeReposet creates the local parameters file of a new reposet, adds it to
+// a new reposet root directory along with the reposet properties, and
+// registers the root in the index key value store.
+func (api *IndexAPI) MakeReposet(ctx context.Context, kind string, name string, opts ...caopts.IndexMakeOption) (coreiface.Reposet, error) {
+	settings, err := caopts.IndexMakeOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == "" {
+		return nil, errors.New("kind of content key must be specified")
+	}
+
+	if name == "" {
+		return nil, errors.New("reposet name must be specified")
+	}
+
+	class := infostoreClass
+	if len(settings.Infostores) > 0 {
+		// metastore to infostore associations
+		class = metastoreClass
+
+		for _, s := range settings.Infostores {
+			p, err := coreiface.ParsePath(s)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse path to infostore: %s", err)
+			}
+
+			if _, _, err := api.reposetProps(ctx, p); err != nil {
+				return nil, fmt.Errorf("invalid infostore %s: %s", s, err)
+			}
+		}
+	}
+
+	// load the index configuration
+	icfg, err := api.node.Repo.IdxConfig()
+	if err != nil {
+		return nil, errors.New("could not load index config")
+	}
+
+	// check repo kind is configured
+	found, err := idxlfs.IsKindConfigured(icfg, kind)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("metadata not configured for repo kind %s", kind)
+	}
+
+	// check repo does not already exist on local filesystem
+	found, rpath, err := idxlfs.ReposetExists(kind, name)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return nil, fmt.Errorf("named reposet path already exists %s", rpath)
+	}
+
+	// check repo does not already exist in kvstore
+	key, err := idxkvs.GetRepoSetKey(class, kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	dstore := api.kvstore()
+
+	has, err := dstore.Has(key)
+	if err != nil {
+		return nil, err
+	}
+	if has {
+		return nil, fmt.Errorf("named reposet key already exists %s", key)
+	}
+
+	// create the params file on local filesystem
+	paramsfile, reponame, ct, err := idxlfs.MakeRepo(icfg, rpath, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := paramsFile(paramsfile)
+	if err != nil {
+		return nil, err
+	}
+
+	pp, err := api.core().Unixfs().Add(ctx, f,
+		caopts.Unixfs.CidVersion(1),
+		caopts.Unixfs.Pin(true),
+		caopts.Unixfs.Events(settings.Events),
+		caopts.Unixfs.Progress(settings.Progress),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := api.core().ResolveNode(ctx, pp)
+	if err != nil {
+		return nil, err
+	}
+
+	sr, err := idxufs.NewStoreRoot(ctx, api.node.DAG, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rp := idxufs.NewRepoProps()
+	rp.SetType(class)
+	rp.SetKind(kind)
+	rp.SetName(reponame) // includes area/cat/offset matching values below
+	rp.SetOffset(0)      // offset is zero at creation time
+	rp.SetArea(1)        // 0 implies N/A
+	rp.SetCat(1)         // 0 implies N/A
+	rp.SetPath(paramsfile)
+
+	rpid, err := sr.AddProps(reponame, rp)
+	if err != nil {
+		return nil, err
+	}
+	api.sendAddEvent(ctx, settings.Events, reponame, rpid.String())
+
+	rps := idxufs.NewReposetProps()
+	rps.SetType(class)
+	rps.SetKind(kind)
+	rps.SetName(name)
+	rps.SetCreatedAt(uint64(ct.Unix()))
+	rps.SetMaxAreas(64)      // TODO: should be configurable, per indexer or kind
+	rps.SetMaxCats(64)       // TODO: should be configurable, per indexer or kind
+	rps.SetMaxDocs(50000000) // TODO: should be configurable, per indexer or kind
+
+	rpsid, err := sr.AddProps(reposetPropsName, rps)
+	if err != nil {
+		return nil, err
+	}
+	api.sendAddEvent(ctx, settings.Events, reposetPropsName, rpsid.String())
+
+	rootdir := sr.GetDirectory()
+
+	_, pfname := gopath.Split(paramsfile)
+	err = rootdir.AddChild(pfname, params)
+	if err != nil {
+		return nil, err
+	}
+
+	nd, err := rootdir.GetNode() // adds rootdir to dag
+	if err != nil {
+		return nil, err
+	}
+
+	err = api.pinRoot(nd.Cid())
+	if err != nil {
+		return nil, err
+	}
+	api.sendAddEvent(ctx, settings.Events, reposetDirName, nd.Cid().String())
+
+	// track the reposet cid in the kvstore, which enables reposet lookup by
+	// class, kind and name
+	v := idxkvs.NewRps()
+	v.SetCid(nd.Cid())
+
+	value, err := v.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal reposet value: %s", err)
+	}
+
+	err = dstore.Put(key, value)
+	if err != nil {
+		return nil, fmt.Errorf("could not put reposet key value: %s", err)
+	}
+	log.Debugf("reposet key %v value %v", key, value)
+
+	return &reposet{
+		class: class,
+		kind:  kind,
+		name:  name,
+		path:  coreiface.Dms3FsPath(nd.Cid()),
+	}, nil
+}
+
+// MakeDoc returns an empty document template with the fields configured for
+// content of kind `kind`.
+func (api *IndexAPI) MakeDoc(ctx context.Context, kind string) (string, error) {
+	if kind == "" {
+		return "", errors.New("kind of content key must be specified")
+	}
+
+	icfg, err := api.node.Repo.IdxConfig()
+	if err != nil {
+		return "", errors.New("could not load index config")
+	}
+
+	return idxlfs.MakeDoc(*icfg, kind)
 }
 
-//type repoList []repoEntry
+// Add adds and pins the document `doc`, and records it in the corpus of the
+// reposet at path `p`.
+func (api *IndexAPI) Add(ctx context.Context, p coreiface.Path, doc files.File) (coreiface.ResolvedPath, error) {
+	rs, err := api.lookupReposet(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	dp, err := api.core().Unixfs().Add(ctx, doc, caopts.Unixfs.Pin(true))
+	if err != nil {
+		return nil, err
+	}
 
+	key, err := idxkvs.GetCorpusKey(rs.class, rs.kind, rs.name, dp.Cid())
+	if err != nil {
+		return nil, err
+	}
 
-// Index returns the data contained by an DMS3FS or DMS3NS object(s) at path `p`.
-//func (api *IndexAPI) Index(ctx context.Context, r io.Reader) (coreiface.Reader, error) {
-func (api *IndexAPI) Index(ctx context.Context, p coreiface.Path, opts ...options.IndexListOption) (coreiface.RepoList, error) {
-	iopts, err := options.IndexListOptions(opts...)
+	value, err := idxkvs.NewCorpusProps(rs.class, rs.kind, 0, dp.Cid()).Marshal()
 	if err != nil {
 		return nil, err
 	}
-	n := api.node
-/*
-	pth, err := ipath.ParsePath(p.String())
+
+	err = api.kvstore().Put(key, value)
 	if err != nil {
 		return nil, err
 	}
-*/
-	log.Debugf("meta %s", iopts.Meta)
 
-	return &repoList{
-		name:  "testing name",
-		id: n.Identity.Pretty(),
+	return dp, nil
+}
+
+// Remove removes the document at path `doc` from the corpus of the reposet at
+// path `p`. The document is not unpinned, as it may still be referenced
+// elsewhere.
+func (api *IndexAPI) Remove(ctx context.Context, p coreiface.Path, doc coreiface.Path) error {
+	rs, err := api.lookupReposet(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	dp, err := api.core().ResolvePath(ctx, doc)
+	if err != nil {
+		return err
+	}
+
+	key, err := idxkvs.GetCorpusKey(rs.class, rs.kind, rs.name, dp.Cid())
+	if err != nil {
+		return err
+	}
+
+	dstore := api.kvstore()
+
+	has, err := dstore.Has(key)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return fmt.Errorf("document %s not found in reposet %s", dp.Cid(), rs.name)
+	}
+
+	return dstore.Delete(key)
+}
+
+// Publish publishes the reposet at path `p` under the DMS3NS name of the
+// configured key.
+func (api *IndexAPI) Publish(ctx context.Context, p coreiface.Path, opts ...caopts.IndexPublishOption) (coreiface.Dms3NsEntry, error) {
+	settings, err := caopts.IndexPublishOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := api.lookupReposet(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.core().Name().Publish(ctx, rs.path,
+		caopts.Name.Key(settings.Key),
+		caopts.Name.ValidTime(settings.ValidTime),
+	)
+}
+
+// Stat returns the properties of the reposet at path `p` along with the
+// number of documents in its corpus.
+func (api *IndexAPI) Stat(ctx context.Context, p coreiface.Path) (*coreiface.ReposetStat, error) {
+	rs, rps, err := api.lookupReposetProps(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := idxkvs.GetCorpusPrefix(rs.class, rs.kind, rs.name)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := api.kvstore().Query(dsquery.Query{Prefix: prefix.String(), KeysOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("cannot issue query request: %v", err)
+	}
+	defer res.Close()
+
+	var docs uint64
+	for result := range res.Next() {
+		if result.Error != nil {
+			return nil, fmt.Errorf("query returned internal error: %v", result.Error)
+		}
+		docs++
+	}
+
+	return &coreiface.ReposetStat{
+		Cid: rs.path.Cid(),
+
+		Class: rs.class,
+		Kind:  rs.kind,
+		Name:  rs.name,
+
+		CreatedAt: time.Unix(int64(rps.GetCreatedAt()), 0).UTC(),
+		MaxAreas:  rps.GetMaxAreas(),
+		MaxCats:   rps.GetMaxCats(),
+		MaxDocs:   rps.GetMaxDocs(),
+
+		Docs: docs,
 	}, nil
 }
 
-/*
-
-* local command, cannot run on daemon
-
-1) configuration management of draft parameters file *
-	syntax: index gen params
-	arguments:
-		path
-		-- the path to a file to be generated
-	options:
-	outputs: draft default parameters file
-	effects:
-	- generates a draft parameters file with built-in default paramters
-
-	futures:
-	- overwrite entries in draft paramaters file
-	- invoke editor to modify draft paramaters file
-	- verify [read/parse/validate] draft parameters file
-		- prints messages showing invalid parameter that needs to be fixed
-
-2) same as above for metadata definitions file *
-	options:
-	-template/t=<template-name>
-		-- name of template file, ex: blogs,...
-
-3) create a new index data repository *
-	syntax:
-	index mk name
-	arguments:
-	-name/-n=<repo-name> -- repository name, required
-	options:
-	-param-file=<params-file>
-		-- defines index parameters, or use system defaults
-	-meta-file=<meta-file>
-		-- defines index metadata, or use system defaults
-	-max-area/-ma=<int>
-		-- number of logical areas, defaults to 63 (same as physical)
-	-max-cat/-mc=<int>
-		-- number of logical categories, defaults to 63 (same as physical)
-	outputs: hash referring to index DAG object
-	effects:
-	- verify draft parameters file
-	- verify metadef file
-	- create config director
-	- add files to config directory
-	- create index director
-	- create repo director
-	- stage above in tmp folder then add -r to Unixfs
-	- print out last hash
-
-
-*/
+// lookupReposet returns the reposet at path `p`, which must be registered in
+// the index key value store.
+func (api *IndexAPI) lookupReposet(ctx context.Context, p coreiface.Path) (*reposet, error) {
+	rs, _, err := api.lookupReposetProps(ctx, p)
+	return rs, err
+}
+
+func (api *IndexAPI) lookupReposetProps(ctx context.Context, p coreiface.Path) (*reposet, idxufs.ReposetProps, error) {
+	rp, rps, err := api.reposetProps(ctx, p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := idxkvs.GetRepoSetKey(rps.GetType(), rps.GetKind(), rps.GetName())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	value, err := api.kvstore().Get(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reposet %s is not registered: %s", rps.GetName(), err)
+	}
+
+	v := idxkvs.NewRps()
+	if err := v.Unmarshal(value); err != nil {
+		return nil, nil, err
+	}
+
+	if !v.GetCid().Equals(rp.Cid()) {
+		return nil, nil, fmt.Errorf("reposet %s is registered with a different root %s", rps.GetName(), v.GetCid())
+	}
+
+	return &reposet{
+		class: rps.GetType(),
+		kind:  rps.GetKind(),
+		name:  rps.GetName(),
+		path:  rp,
+	}, rps, nil
+}
+
+// reposetProps reads the reposet properties stored in the reposet root
+// directory at path `p`.
+func (api *IndexAPI) reposetProps(ctx context.Context, p coreiface.Path) (coreiface.ResolvedPath, idxufs.ReposetProps, error) {
+	rp, err := api.core().ResolvePath(ctx, p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nd, err := api.node.DAG.Get(ctx, rp.Cid())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pn, ok := nd.(*dag.ProtoNode)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid reposet node %s", rp.Cid())
+	}
+
+	sr, err := idxufs.NewStoreRoot(ctx, api.node.DAG, pn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ri, err := sr.GetProps(reposetPropsName, idxufs.NewReposetProps())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get reposet properties: %s", err)
+	}
+
+	rps, ok := ri.(idxufs.ReposetProps)
+	if !ok {
+		return nil, nil, errors.New("invalid reposet properties")
+	}
+
+	return rp, rps, nil
+}
+
+func (api *IndexAPI) pinRoot(c *cid.Cid) error {
+	defer api.node.Blockstore.PinLock().Unlock()
+
+	api.node.Pinning.PinWithMode(c, pin.Recursive)
+	return api.node.Pinning.Flush()
+}
+
+func (api *IndexAPI) sendAddEvent(ctx context.Context, events chan<- interface{}, name, hash string) {
+	if events == nil {
+		return
+	}
+
+	select {
+	case events <- &coreiface.AddEvent{
+		Name: name,
+		Hash: hash,
+		Size: strconv.FormatUint(0, 10),
+	}:
+	case <-ctx.Done():
+	}
+}
+
+// kvstore returns the index key value store, backed by the node datastore.
+func (api *IndexAPI) kvstore() idxkvs.KVStore {
+	idxkvs.InitIndexKVStore(api.node.Repo.Datastore())
+	return idxkvs.GetIndexKVStore()
+}
+
+func (api *IndexAPI) core() coreiface.CoreAPI {
+	return (*CoreAPI)(api)
+}
+
+// paramsFile opens the reposet parameters file at `fpath` for adding.
+func paramsFile(fpath string) (files.File, error) {
+	fpath = filepath.ToSlash(filepath.Clean(fpath))
+
+	stat, err := os.Lstat(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	if stat.IsDir() {
+		return nil, fmt.Errorf("invalid params file path '%s', path must not be a directory", fpath)
+	}
+
+	return files.NewSerialFile(gopath.Base(fpath), fpath, false, stat)
+}
@@ -0,0 +1,296 @@
+package coreapi_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	core "github.com/dms3-fs/go-dms3-fs/core"
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+	idxkvs "github.com/dms3-fs/go-dms3-fs/core/coreindex/kvs"
+	idxufs "github.com/dms3-fs/go-dms3-fs/core/coreindex/ufs"
+)
+
+// makeReposet registers a reposet root holding only the reposet properties,
+// without the local parameters file created by Index().MakeReposet
+func makeReposet(ctx context.Context, node *core.Dms3FsNode, class, kind, name string) (coreiface.ResolvedPath, error) {
+	sr, err := idxufs.NewStoreRoot(ctx, node.DAG, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rps := idxufs.NewReposetProps()
+	rps.SetType(class)
+	rps.SetKind(kind)
+	rps.SetName(name)
+	rps.SetCreatedAt(1500000000)
+	rps.SetMaxAreas(64)
+	rps.SetMaxCats(64)
+	rps.SetMaxDocs(1000)
+
+	_, err = sr.AddProps("reposetprops", rps)
+	if err != nil {
+		return nil, err
+	}
+
+	nd, err := sr.GetDirectory().GetNode()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := idxkvs.GetRepoSetKey(class, kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	v := idxkvs.NewRps()
+	v.SetCid(nd.Cid())
+	value, err := v.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	idxkvs.InitIndexKVStore(node.Repo.Datastore())
+	err = idxkvs.GetIndexKVStore().Put(key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return coreiface.Dms3FsPath(nd.Cid()), nil
+}
+
+func TestIndexList(t *testing.T) {
+	ctx := context.Background()
+	node, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := makeReposet(ctx, node, "infostore", "blog", "foodblog")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = makeReposet(ctx, node, "metastore", "blog", "foodmeta")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = makeReposet(ctx, node, "infostore", "news", "daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := api.Index().List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 3 {
+		t.Fatalf("expected 3 reposets, got %d", len(list))
+	}
+
+	list, err = api.Index().List(ctx, options.Index.List.Kind("blog"), options.Index.List.Metastore(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 1 {
+		t.Fatalf("expected 1 reposet, got %d", len(list))
+	}
+
+	if list[0].Class() != "infostore" || list[0].Kind() != "blog" || list[0].Name() != "foodblog" {
+		t.Errorf("unexpected reposet %s %s %s", list[0].Class(), list[0].Kind(), list[0].Name())
+	}
+
+	if list[0].Path().String() != info.String() {
+		t.Errorf("expected path %s, got %s", info.String(), list[0].Path().String())
+	}
+
+	list, err = api.Index().List(ctx, options.Index.List.Name("daily"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 1 || list[0].Kind() != "news" {
+		t.Errorf("expected only the 'daily' reposet")
+	}
+
+	seen := map[string]bool{}
+	for page := 0; page < 3; page++ {
+		list, err = api.Index().List(ctx, options.Index.List.Offset(page), options.Index.List.Length(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(list) != 1 {
+			t.Fatalf("expected 1 reposet on page %d, got %d", page, len(list))
+		}
+		seen[list[0].Name()] = true
+	}
+
+	if len(seen) != 3 {
+		t.Errorf("expected 3 distinct reposets across pages, got %d", len(seen))
+	}
+
+	list, err = api.Index().List(ctx, options.Index.List.Offset(3), options.Index.List.Length(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 0 {
+		t.Errorf("expected empty page, got %d reposets", len(list))
+	}
+
+	_, err = api.Index().List(ctx, options.Index.List.Length(0))
+	if err == nil {
+		t.Error("expected zero page length to fail")
+	}
+}
+
+func TestIndexAddRemoveStat(t *testing.T) {
+	ctx := context.Background()
+	node, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rp, err := makeReposet(ctx, node, "infostore", "blog", "foodblog")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := api.Index().Stat(ctx, rp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if st.Class != "infostore" || st.Kind != "blog" || st.Name != "foodblog" {
+		t.Errorf("unexpected reposet %s %s %s", st.Class, st.Kind, st.Name)
+	}
+
+	if st.CreatedAt.Unix() != 1500000000 {
+		t.Errorf("expected creation time 1500000000, got %d", st.CreatedAt.Unix())
+	}
+
+	if st.MaxDocs != 1000 {
+		t.Errorf("expected 1000 max docs, got %d", st.MaxDocs)
+	}
+
+	if st.Docs != 0 {
+		t.Errorf("expected no docs, got %d", st.Docs)
+	}
+
+	d1, err := api.Index().Add(ctx, rp, strFile("<doc>one</doc>")())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = api.Index().Add(ctx, rp, strFile("<doc>two</doc>")())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st, err = api.Index().Stat(ctx, rp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if st.Docs != 2 {
+		t.Errorf("expected 2 docs, got %d", st.Docs)
+	}
+
+	// corpus keys must not show up as reposets
+	list, err := api.Index().List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 1 {
+		t.Errorf("expected 1 reposet, got %d", len(list))
+	}
+
+	err = api.Index().Remove(ctx, rp, d1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Index().Remove(ctx, rp, d1)
+	if err == nil {
+		t.Error("expected removing a missing document to fail")
+	}
+
+	st, err = api.Index().Stat(ctx, rp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if st.Docs != 1 {
+		t.Errorf("expected 1 doc, got %d", st.Docs)
+	}
+
+	_, err = api.Index().Add(ctx, d1, strFile("<doc>three</doc>")())
+	if err == nil {
+		t.Error("expected adding to a non reposet path to fail")
+	}
+}
+
+func TestIndexMakeReposet(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = api.Index().MakeReposet(ctx, "", "foodblog")
+	if err == nil {
+		t.Error("expected empty kind to fail")
+	}
+
+	_, err = api.Index().MakeReposet(ctx, "blog", "")
+	if err == nil {
+		t.Error("expected empty name to fail")
+	}
+
+	_, err = api.Index().MakeReposet(ctx, "blog", "foodblog")
+	if err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("expected unconfigured kind to fail, got %v", err)
+	}
+
+	p, err := api.Unixfs().Add(ctx, strFile("hello")())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = api.Index().MakeReposet(ctx, "blog", "foodmeta", options.Index.Make.Infostores(p.String()))
+	if err == nil || !strings.Contains(err.Error(), "invalid infostore") {
+		t.Errorf("expected invalid infostore to fail, got %v", err)
+	}
+}
+
+func TestIndexPublish(t *testing.T) {
+	ctx := context.Background()
+	node, api, err := makeAPIIdent(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rp, err := makeReposet(ctx, node, "infostore", "blog", "foodblog")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := api.Index().Publish(ctx, rp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Name() != node.Identity.Pretty() {
+		t.Errorf("expected name %s, got %s", node.Identity.Pretty(), e.Name())
+	}
+
+	if e.Value().String() != rp.String() {
+		t.Errorf("expected value %s, got %s", rp.String(), e.Value().String())
+	}
+}
@@ -0,0 +1,52 @@
+package iface
+
+import (
+	"context"
+
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	cid "github.com/dms3-fs/go-cid"
+	peer "github.com/dms3-p2p/go-p2p-peer"
+)
+
+// BitswapStat contains diagnostic information about the bitswap agent
+type BitswapStat struct {
+	ProvideBufLen   int
+	Wantlist        []*cid.Cid
+	Peers           []string
+	BlocksReceived  uint64
+	DataReceived    uint64
+	BlocksSent      uint64
+	DataSent        uint64
+	DupBlksReceived uint64
+	DupDataReceived uint64
+}
+
+// BitswapLedger contains the amount of data exchanged with a peer
+type BitswapLedger struct {
+	Peer      string
+	Value     float64
+	Sent      uint64
+	Recv      uint64
+	Exchanged uint64
+}
+
+// BitswapAPI specifies the interface to the bitswap agent
+type BitswapAPI interface {
+	// Wantlist returns the blocks currently on the wantlist of the local
+	// peer, or of another peer with the Peer option
+	Wantlist(context.Context, ...options.BitswapWantlistOption) ([]*cid.Cid, error)
+
+	// Unwant removes the objects at the given paths from the wantlist
+	Unwant(context.Context, ...Path) error
+
+	// Ledger returns the ledger kept for the given peer
+	Ledger(context.Context, peer.ID) (*BitswapLedger, error)
+
+	// Stat returns diagnostic information about the bitswap agent
+	Stat(context.Context) (*BitswapStat, error)
+
+	// Reprovide triggers the reprovider to announce the local data to the
+	// network
+	Reprovide(context.Context) error
+}
@@ -34,6 +34,24 @@ type CoreAPI interface {
 	// ObjectAPI returns an implementation of Object API
 	Object() ObjectAPI
 
+	// Swarm returns an implementation of Swarm API
+	Swarm() SwarmAPI
+
+	// PubSub returns an implementation of PubSub API
+	PubSub() PubSubAPI
+
+	// Dht returns an implementation of Dht API
+	Dht() DhtAPI
+
+	// Files returns an implementation of Files API
+	Files() FilesAPI
+
+	// Bitswap returns an implementation of Bitswap API
+	Bitswap() BitswapAPI
+
+	// Repo returns an implementation of Repo API
+	Repo() RepoAPI
+
 	// ResolvePath resolves the path using Unixfs resolver
 	ResolvePath(context.Context, Path) (ResolvedPath, error)
 
@@ -26,6 +26,23 @@ type DagBatch interface {
 	Commit(ctx context.Context) error
 }
 
+// DagTreeResult is sent on the channel returned by DagAPI.TreeAsync. Either
+// Path or Err is set
+type DagTreeResult struct {
+	Path Path
+	Err  error
+}
+
+// DagConflict represents two incompatible changes made to the same path, or to
+// paths above and below one another, during a three-way merge
+type DagConflict struct {
+	// A is the change from the base to the first node
+	A ObjectChange
+
+	// B is the change from the base to the second node
+	B ObjectChange
+}
+
 // DagAPI specifies the interface to DMS3LD
 type DagAPI interface {
 	DagOps
@@ -36,6 +53,21 @@ type DagAPI interface {
 	// Tree returns list of paths within a node specified by the path.
 	Tree(ctx context.Context, path Path, opts ...options.DagTreeOption) ([]Path, error)
 
+	// TreeAsync streams the paths within a node specified by the path. The
+	// channel is closed once all paths were sent, after an error, or when the
+	// context is cancelled, which is not reported on the channel
+	TreeAsync(ctx context.Context, path Path, opts ...options.DagTreeOption) (<-chan DagTreeResult, error)
+
+	// Diff returns the changes needed to transform the node at path `before`
+	// into the node at path `after`, recursively and for any format
+	Diff(ctx context.Context, before Path, after Path, opts ...options.DagDiffOption) ([]ObjectChange, error)
+
+	// Merge applies the changes from the node at path `base` to the nodes at
+	// paths `a` and `b` onto `base`, and returns the path of the merged node.
+	// If the changes conflict, no node is created and the conflicts are
+	// returned instead. Only protobuf nodes can be merged
+	Merge(ctx context.Context, base Path, a Path, b Path) (ResolvedPath, []DagConflict, error)
+
 	// Batch creates new DagBatch
 	Batch(ctx context.Context) DagBatch
 }
@@ -0,0 +1,32 @@
+package iface
+
+import (
+	"context"
+
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	peer "github.com/dms3-p2p/go-p2p-peer"
+	pstore "github.com/dms3-p2p/go-p2p-peerstore"
+)
+
+// DhtAPI specifies the interface to the DHT
+type DhtAPI interface {
+	// FindPeer queries the DHT for all of the multiaddresses associated with a
+	// Peer ID
+	FindPeer(context.Context, peer.ID) (pstore.PeerInfo, error)
+
+	// FindProviders finds peers in the DHT who can provide a specific value
+	// given a key.
+	FindProviders(context.Context, Path, ...options.DhtFindProvidersOption) (<-chan pstore.PeerInfo, error)
+
+	// Provide announces to the network that you are providing given values
+	Provide(context.Context, Path, ...options.DhtProvideOption) error
+
+	// GetValue searches the routing system for the value stored under the key.
+	// Keys are in the form used by the routing system, e.g.
+	// "/dms3ns/" + string(peerID)
+	GetValue(ctx context.Context, key string) ([]byte, error)
+
+	// PutValue writes a key/value pair to the routing system
+	PutValue(ctx context.Context, key string, value []byte) error
+}
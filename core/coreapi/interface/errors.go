@@ -3,6 +3,8 @@ package iface
 import "errors"
 
 var (
-	ErrIsDir   = errors.New("object is a directory")
-	ErrOffline = errors.New("can't resolve, dms3fs node is offline")
+	ErrIsDir        = errors.New("object is a directory")
+	ErrOffline      = errors.New("can't resolve, dms3fs node is offline")
+	ErrNotConnected = errors.New("not connected")
+	ErrConnNotFound = errors.New("conn not found")
 )
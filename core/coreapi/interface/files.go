@@ -0,0 +1,85 @@
+package iface
+
+import (
+	"context"
+	"io"
+
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	cid "github.com/dms3-fs/go-cid"
+)
+
+// FilesStat holds information about a node in the mutable filesystem
+type FilesStat struct {
+	// Cid is the CID of the node
+	Cid *cid.Cid
+
+	// Size is the size of the file data, or of the directory node
+	Size uint64
+
+	// CumulativeSize is the size of the tree referenced by the node
+	CumulativeSize uint64
+
+	// Blocks is the number of child blocks of the node
+	Blocks int
+
+	// Type is either "file" or "directory"
+	Type string
+
+	// WithLocality is set when the locality information below was computed
+	WithLocality bool
+
+	// Local indicates whether the whole tree is available locally
+	Local bool
+
+	// SizeLocal is the size of the part of the tree available locally
+	SizeLocal uint64
+}
+
+// FilesEntry is a single entry of a directory listing in the mutable
+// filesystem. Type, Size and Hash are only set for long listings.
+type FilesEntry struct {
+	Name string
+	Type int
+	Size int64
+	Hash string
+}
+
+// FilesAPI specifies the interface to the mutable filesystem (MFS), which
+// allows manipulating DMS3FS objects as if they were a unix filesystem.
+//
+// All paths are absolute MFS paths, except the source of Cp which can also be
+// an /dms3fs/ path.
+type FilesAPI interface {
+	// Read returns a reader for the file at the given path
+	Read(ctx context.Context, path string, opts ...options.FilesReadOption) (io.ReadCloser, error)
+
+	// Write writes data from the reader to the file at the given path
+	Write(ctx context.Context, path string, r io.Reader, opts ...options.FilesWriteOption) error
+
+	// Mkdir creates a directory
+	Mkdir(ctx context.Context, path string, opts ...options.FilesMkdirOption) error
+
+	// Mv moves files and directories
+	Mv(ctx context.Context, src string, dst string) error
+
+	// Cp copies a file or directory into the mutable filesystem. If dst ends
+	// with a slash, the base name of src is appended to it
+	Cp(ctx context.Context, src string, dst string, opts ...options.FilesCpOption) error
+
+	// Ls lists the entries of the directory at the given path
+	Ls(ctx context.Context, path string, opts ...options.FilesLsOption) ([]FilesEntry, error)
+
+	// Stat returns information about the node at the given path
+	Stat(ctx context.Context, path string, opts ...options.FilesStatOption) (*FilesStat, error)
+
+	// Rm removes a file or, with the Recursive option, a directory
+	Rm(ctx context.Context, path string, opts ...options.FilesRmOption) error
+
+	// Flush flushes the given path's data to disk
+	Flush(ctx context.Context, path string) error
+
+	// Chcid changes the CID version or hash function of the directory at the
+	// given path
+	Chcid(ctx context.Context, path string, opts ...options.FilesChcidOption) error
+}
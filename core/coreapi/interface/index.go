@@ -2,30 +2,70 @@ package iface
 
 import (
 	"context"
-//	"io"
+	"time"
 
 	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
-//	dms3ld "github.com/dms3-fs/go-ld-format"
+
+	cid "github.com/dms3-fs/go-cid"
+	files "github.com/dms3-fs/go-fs-cmdkit/files"
 )
 
-//type RepoEntry interface {
-type RepoList interface {
-	// Name returns repository name
+// Reposet is a reference to an index repository set
+type Reposet interface {
+	// Class returns the reposet class, either "infostore" or "metastore"
+	Class() string
+
+	// Kind returns the kind of content hosted by the reposet, ex: "blog"
+	Kind() string
+
+	// Name returns the reposet name
 	Name() string
-	// Id returns repository path
-	Id() string
+
+	// Path returns the path to the reposet root directory
+	Path() ResolvedPath
 }
-//type RepoList []RepoEntry
 
-// UnixfsAPI is the basic interface to immutable files in DMS3FS
+// ReposetStat holds the properties of an index repository set
+type ReposetStat struct {
+	Cid *cid.Cid
+
+	Class string
+	Kind  string
+	Name  string
+
+	CreatedAt time.Time
+	MaxAreas  uint8
+	MaxCats   uint8
+	MaxDocs   uint64
+
+	// Docs is the number of documents added to the reposet
+	Docs uint64
+}
+
+// IndexAPI specifies the interface to DMS3FS index repositories
 type IndexAPI interface {
-	// Add imports the data from the reader into merkledag file
-	//Add(context.Context, io.Reader) (ResolvedPath, error)
+	// List returns the list of local reposets
+	List(context.Context, ...options.IndexListOption) ([]Reposet, error)
+
+	// MakeReposet creates a new reposet for documents of the given kind. With
+	// the Infostores option a metastore reposet is created for the given
+	// infostore reposets, otherwise an infostore reposet is created
+	MakeReposet(ctx context.Context, kind string, name string, opts ...options.IndexMakeOption) (Reposet, error)
+
+	// MakeDoc returns an empty document template for content of the given
+	// kind
+	MakeDoc(ctx context.Context, kind string) (string, error)
+
+	// Add adds the document to the reposet at path `reposet` and returns the
+	// path of the document
+	Add(ctx context.Context, reposet Path, doc files.File) (ResolvedPath, error)
+
+	// Remove removes the document at path `doc` from the reposet
+	Remove(ctx context.Context, reposet Path, doc Path) error
 
-	// Cat returns a reader for the file
-	//Cat(context.Context, Path) (Reader, error)
-	Index(ctx context.Context, path Path, opts ...options.IndexListOption) (RepoList, error)
+	// Publish publishes the reposet under a DMS3NS name
+	Publish(ctx context.Context, reposet Path, opts ...options.IndexPublishOption) (Dms3NsEntry, error)
 
-	// Ls returns the list of links in a directory
-	//Ls(context.Context, Path) ([]*dms3ld.Link, error)
+	// Stat returns the properties of the reposet
+	Stat(ctx context.Context, reposet Path) (*ReposetStat, error)
 }
@@ -20,6 +20,13 @@ type Key interface {
 	ID() peer.ID
 }
 
+// KeyListResult is sent on the channel returned by KeyAPI.ListAsync. Either
+// Key or Err is set
+type KeyListResult struct {
+	Key Key
+	Err error
+}
+
 // KeyAPI specifies the interface to Keystore
 type KeyAPI interface {
 	// Generate generates new key, stores it in the keystore under the specified
@@ -33,6 +40,11 @@ type KeyAPI interface {
 	// List lists keys stored in keystore
 	List(ctx context.Context) ([]Key, error)
 
+	// ListAsync streams the keys stored in keystore. The channel is closed
+	// once all keys were sent, after an error, or when the context is
+	// cancelled, which is not reported on the channel
+	ListAsync(ctx context.Context) (<-chan KeyListResult, error)
+
 	// Remove removes keys from keystore. Returns dms3ns path of the removed key
 	Remove(ctx context.Context, name string) (Key, error)
 }
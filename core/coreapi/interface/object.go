@@ -53,7 +53,8 @@ type ObjectChange struct {
 	// * DiffMod - Modified a link
 	Type ChangeType
 
-	// Path to the changed link
+	// Path to the changed link, relative to the diffed nodes. A change of the
+	// diffed node itself has an empty path
 	Path string
 
 	// Before holds the link path before the change. Note that when a link is
@@ -102,5 +103,5 @@ type ObjectAPI interface {
 
 	// Diff returns a set of changes needed to transform the first object into the
 	// second.
-	Diff(context.Context, Path, Path) ([]ObjectChange, error)
+	Diff(context.Context, Path, Path, ...options.ObjectDiffOption) ([]ObjectChange, error)
 }
@@ -0,0 +1,38 @@
+package options
+
+import (
+	peer "github.com/dms3-p2p/go-p2p-peer"
+)
+
+type BitswapWantlistSettings struct {
+	Peer peer.ID
+}
+
+type BitswapWantlistOption func(*BitswapWantlistSettings) error
+
+func BitswapWantlistOptions(opts ...BitswapWantlistOption) (*BitswapWantlistSettings, error) {
+	options := &BitswapWantlistSettings{
+		Peer: "",
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+type bitswapOpts struct{}
+
+var Bitswap bitswapOpts
+
+// Peer is an option for Bitswap.Wantlist which specifies the peer to show the
+// wantlist for. Default is the local peer
+func (bitswapOpts) Peer(p peer.ID) BitswapWantlistOption {
+	return func(settings *BitswapWantlistSettings) error {
+		settings.Peer = p
+		return nil
+	}
+}
@@ -17,8 +17,13 @@ type DagTreeSettings struct {
 	Depth int
 }
 
+type DagDiffSettings struct {
+	Depth int
+}
+
 type DagPutOption func(*DagPutSettings) error
 type DagTreeOption func(*DagTreeSettings) error
+type DagDiffOption func(*DagDiffSettings) error
 
 func DagPutOptions(opts ...DagPutOption) (*DagPutSettings, error) {
 	options := &DagPutSettings{
@@ -51,6 +56,20 @@ func DagTreeOptions(opts ...DagTreeOption) (*DagTreeSettings, error) {
 	return options, nil
 }
 
+func DagDiffOptions(opts ...DagDiffOption) (*DagDiffSettings, error) {
+	options := &DagDiffSettings{
+		Depth: -1,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
 type dagOpts struct{}
 
 var Dag dagOpts
@@ -93,3 +112,13 @@ func (dagOpts) Depth(depth int) DagTreeOption {
 		return nil
 	}
 }
+
+// DiffDepth is an option for Dag.Diff which specifies the maximum depth at
+// which changes are reported. Modified links below it are reported as
+// modified without being descended into. Default is -1 (no depth limit)
+func (dagOpts) DiffDepth(depth int) DagDiffOption {
+	return func(settings *DagDiffSettings) error {
+		settings.Depth = depth
+		return nil
+	}
+}
@@ -0,0 +1,422 @@
+package options
+
+import (
+	"math"
+)
+
+type FilesReadSettings struct {
+	Offset int64
+	Count  int64
+}
+
+type FilesWriteSettings struct {
+	Offset   int64
+	Count    int64
+	Create   bool
+	Parents  bool
+	Truncate bool
+	Flush    bool
+
+	RawLeaves    bool
+	RawLeavesSet bool
+
+	CidVersion int
+	MhType     uint64
+}
+
+type FilesMkdirSettings struct {
+	Parents bool
+	Flush   bool
+
+	CidVersion int
+	MhType     uint64
+}
+
+type FilesCpSettings struct {
+	Flush bool
+}
+
+type FilesLsSettings struct {
+	Long bool
+}
+
+type FilesStatSettings struct {
+	WithLocal bool
+}
+
+type FilesRmSettings struct {
+	Recursive bool
+}
+
+type FilesChcidSettings struct {
+	Flush bool
+
+	CidVersion int
+	MhType     uint64
+}
+
+type FilesReadOption func(*FilesReadSettings) error
+type FilesWriteOption func(*FilesWriteSettings) error
+type FilesMkdirOption func(*FilesMkdirSettings) error
+type FilesCpOption func(*FilesCpSettings) error
+type FilesLsOption func(*FilesLsSettings) error
+type FilesStatOption func(*FilesStatSettings) error
+type FilesRmOption func(*FilesRmSettings) error
+type FilesChcidOption func(*FilesChcidSettings) error
+
+func FilesReadOptions(opts ...FilesReadOption) (*FilesReadSettings, error) {
+	options := &FilesReadSettings{
+		Offset: 0,
+		Count:  -1,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+func FilesWriteOptions(opts ...FilesWriteOption) (*FilesWriteSettings, error) {
+	options := &FilesWriteSettings{
+		Offset:   0,
+		Count:    -1,
+		Create:   false,
+		Parents:  false,
+		Truncate: false,
+		Flush:    true,
+
+		RawLeaves:    false,
+		RawLeavesSet: false,
+
+		CidVersion: -1,
+		MhType:     math.MaxUint64,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+func FilesMkdirOptions(opts ...FilesMkdirOption) (*FilesMkdirSettings, error) {
+	options := &FilesMkdirSettings{
+		Parents: false,
+		Flush:   true,
+
+		CidVersion: -1,
+		MhType:     math.MaxUint64,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+func FilesCpOptions(opts ...FilesCpOption) (*FilesCpSettings, error) {
+	options := &FilesCpSettings{
+		Flush: true,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+func FilesLsOptions(opts ...FilesLsOption) (*FilesLsSettings, error) {
+	options := &FilesLsSettings{
+		Long: false,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+func FilesStatOptions(opts ...FilesStatOption) (*FilesStatSettings, error) {
+	options := &FilesStatSettings{
+		WithLocal: false,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+func FilesRmOptions(opts ...FilesRmOption) (*FilesRmSettings, error) {
+	options := &FilesRmSettings{
+		Recursive: false,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+func FilesChcidOptions(opts ...FilesChcidOption) (*FilesChcidSettings, error) {
+	options := &FilesChcidSettings{
+		Flush: true,
+
+		CidVersion: -1,
+		MhType:     math.MaxUint64,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+type filesReadOpts struct{}
+type filesWriteOpts struct{}
+type filesMkdirOpts struct{}
+type filesCpOpts struct{}
+type filesLsOpts struct{}
+type filesStatOpts struct{}
+type filesRmOpts struct{}
+type filesChcidOpts struct{}
+
+type filesOpts struct {
+	Read  filesReadOpts
+	Write filesWriteOpts
+	Mkdir filesMkdirOpts
+	Cp    filesCpOpts
+	Ls    filesLsOpts
+	Stat  filesStatOpts
+	Rm    filesRmOpts
+	Chcid filesChcidOpts
+}
+
+var Files filesOpts
+
+// Offset is an option for Files.Read which specifies the byte offset to begin
+// reading from. Default is 0
+func (filesReadOpts) Offset(offset int64) FilesReadOption {
+	return func(settings *FilesReadSettings) error {
+		settings.Offset = offset
+		return nil
+	}
+}
+
+// Count is an option for Files.Read which specifies the maximum number of
+// bytes to read. Default is -1 (read until the end of the file)
+func (filesReadOpts) Count(count int64) FilesReadOption {
+	return func(settings *FilesReadSettings) error {
+		settings.Count = count
+		return nil
+	}
+}
+
+// Offset is an option for Files.Write which specifies the byte offset to begin
+// writing at. Default is 0
+func (filesWriteOpts) Offset(offset int64) FilesWriteOption {
+	return func(settings *FilesWriteSettings) error {
+		settings.Offset = offset
+		return nil
+	}
+}
+
+// Count is an option for Files.Write which specifies the maximum number of
+// bytes to read from the input. Default is -1 (read the whole input)
+func (filesWriteOpts) Count(count int64) FilesWriteOption {
+	return func(settings *FilesWriteSettings) error {
+		settings.Count = count
+		return nil
+	}
+}
+
+// Create is an option for Files.Write which specifies whether the file should
+// be created if it does not exist. Default is false
+func (filesWriteOpts) Create(create bool) FilesWriteOption {
+	return func(settings *FilesWriteSettings) error {
+		settings.Create = create
+		return nil
+	}
+}
+
+// Parents is an option for Files.Write which specifies whether the parent
+// directories should be created as needed. Default is false
+func (filesWriteOpts) Parents(parents bool) FilesWriteOption {
+	return func(settings *FilesWriteSettings) error {
+		settings.Parents = parents
+		return nil
+	}
+}
+
+// Truncate is an option for Files.Write which specifies whether the file
+// should be truncated to size zero before writing. Default is false
+func (filesWriteOpts) Truncate(truncate bool) FilesWriteOption {
+	return func(settings *FilesWriteSettings) error {
+		settings.Truncate = truncate
+		return nil
+	}
+}
+
+// Flush is an option for Files.Write which specifies whether the file and its
+// ancestors should be flushed after the write. Default is true
+func (filesWriteOpts) Flush(flush bool) FilesWriteOption {
+	return func(settings *FilesWriteSettings) error {
+		settings.Flush = flush
+		return nil
+	}
+}
+
+// RawLeaves is an option for Files.Write which specifies whether newly created
+// leaf nodes should be raw blocks. By default raw leaves are used when the CID
+// version of the file is non-zero
+func (filesWriteOpts) RawLeaves(rawLeaves bool) FilesWriteOption {
+	return func(settings *FilesWriteSettings) error {
+		settings.RawLeaves = rawLeaves
+		settings.RawLeavesSet = true
+		return nil
+	}
+}
+
+// CidVersion is an option for Files.Write which specifies the CID version to
+// use for a newly created file. By default the file inherits the CID version
+// of its parent directory
+func (filesWriteOpts) CidVersion(version int) FilesWriteOption {
+	return func(settings *FilesWriteSettings) error {
+		settings.CidVersion = version
+		return nil
+	}
+}
+
+// Hash is an option for Files.Write which specifies the multihash function to
+// use for a newly created file. Setting it implies CID version 1. By default
+// the file inherits the hash function of its parent directory
+func (filesWriteOpts) Hash(mhType uint64) FilesWriteOption {
+	return func(settings *FilesWriteSettings) error {
+		settings.MhType = mhType
+		return nil
+	}
+}
+
+// Parents is an option for Files.Mkdir which specifies whether parent
+// directories should be created as needed, without failing if the directory
+// already exists. Default is false
+func (filesMkdirOpts) Parents(parents bool) FilesMkdirOption {
+	return func(settings *FilesMkdirSettings) error {
+		settings.Parents = parents
+		return nil
+	}
+}
+
+// Flush is an option for Files.Mkdir which specifies whether the new directory
+// and its ancestors should be flushed. Default is true
+func (filesMkdirOpts) Flush(flush bool) FilesMkdirOption {
+	return func(settings *FilesMkdirSettings) error {
+		settings.Flush = flush
+		return nil
+	}
+}
+
+// CidVersion is an option for Files.Mkdir which specifies the CID version to
+// use for the new directory. By default the directory inherits the CID version
+// of its parent
+func (filesMkdirOpts) CidVersion(version int) FilesMkdirOption {
+	return func(settings *FilesMkdirSettings) error {
+		settings.CidVersion = version
+		return nil
+	}
+}
+
+// Hash is an option for Files.Mkdir which specifies the multihash function to
+// use for the new directory. Setting it implies CID version 1
+func (filesMkdirOpts) Hash(mhType uint64) FilesMkdirOption {
+	return func(settings *FilesMkdirSettings) error {
+		settings.MhType = mhType
+		return nil
+	}
+}
+
+// Flush is an option for Files.Cp which specifies whether the destination and
+// its ancestors should be flushed after the copy. Default is true
+func (filesCpOpts) Flush(flush bool) FilesCpOption {
+	return func(settings *FilesCpSettings) error {
+		settings.Flush = flush
+		return nil
+	}
+}
+
+// Long is an option for Files.Ls which specifies whether the type, size and
+// hash of the entries should be returned as well. Default is false
+func (filesLsOpts) Long(long bool) FilesLsOption {
+	return func(settings *FilesLsSettings) error {
+		settings.Long = long
+		return nil
+	}
+}
+
+// WithLocal is an option for Files.Stat which specifies whether to compute the
+// amount of the DAG which is available locally. Default is false
+func (filesStatOpts) WithLocal(withLocal bool) FilesStatOption {
+	return func(settings *FilesStatSettings) error {
+		settings.WithLocal = withLocal
+		return nil
+	}
+}
+
+// Recursive is an option for Files.Rm which specifies whether directories
+// should be removed. Default is false
+func (filesRmOpts) Recursive(recursive bool) FilesRmOption {
+	return func(settings *FilesRmSettings) error {
+		settings.Recursive = recursive
+		return nil
+	}
+}
+
+// Flush is an option for Files.Chcid which specifies whether the directory
+// should be flushed after the change. Default is true
+func (filesChcidOpts) Flush(flush bool) FilesChcidOption {
+	return func(settings *FilesChcidSettings) error {
+		settings.Flush = flush
+		return nil
+	}
+}
+
+// CidVersion is an option for Files.Chcid which specifies the new CID version
+// of the directory
+func (filesChcidOpts) CidVersion(version int) FilesChcidOption {
+	return func(settings *FilesChcidSettings) error {
+		settings.CidVersion = version
+		return nil
+	}
+}
+
+// Hash is an option for Files.Chcid which specifies the new multihash function
+// of the directory. Setting it implies CID version 1
+func (filesChcidOpts) Hash(mhType uint64) FilesChcidOption {
+	return func(settings *FilesChcidSettings) error {
+		settings.MhType = mhType
+		return nil
+	}
+}
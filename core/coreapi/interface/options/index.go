@@ -1,22 +1,42 @@
 package options
 
 import (
-//	"time"
-)
-
-const (
-	RepoWindowStart = iota
+	"time"
 )
 
 type IndexListSettings struct {
-	Meta      string
+	Kind      string
+	Name      string
+	Metastore bool
+	Infostore bool
+	Offset    int
+	Length    int
+}
+
+type IndexMakeSettings struct {
+	Infostores []string
+
+	Events   chan<- interface{}
+	Progress bool
+}
+
+type IndexPublishSettings struct {
+	Key       string
+	ValidTime time.Duration
 }
 
 type IndexListOption func(*IndexListSettings) error
+type IndexMakeOption func(*IndexMakeSettings) error
+type IndexPublishOption func(*IndexPublishSettings) error
 
 func IndexListOptions(opts ...IndexListOption) (*IndexListSettings, error) {
 	options := &IndexListSettings{
-		Meta:       "",
+		Kind:      "",
+		Name:      "",
+		Metastore: true,
+		Infostore: true,
+		Offset:    0,
+		Length:    24,
 	}
 
 	for _, opt := range opts {
@@ -28,3 +48,150 @@ func IndexListOptions(opts ...IndexListOption) (*IndexListSettings, error) {
 
 	return options, nil
 }
+
+func IndexMakeOptions(opts ...IndexMakeOption) (*IndexMakeSettings, error) {
+	options := &IndexMakeSettings{
+		Infostores: nil,
+
+		Events:   nil,
+		Progress: false,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
+func IndexPublishOptions(opts ...IndexPublishOption) (*IndexPublishSettings, error) {
+	options := &IndexPublishSettings{
+		Key:       "self",
+		ValidTime: DefaultNameValidTime,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
+type indexListOpts struct{}
+type indexMakeOpts struct{}
+type indexPublishOpts struct{}
+
+type indexOpts struct {
+	List    indexListOpts
+	Make    indexMakeOpts
+	Publish indexPublishOpts
+}
+
+var Index indexOpts
+
+// Kind is an option for Index.List which limits the listing to reposets of
+// the given kind. Default is "" (all kinds)
+func (indexListOpts) Kind(kind string) IndexListOption {
+	return func(settings *IndexListSettings) error {
+		settings.Kind = kind
+		return nil
+	}
+}
+
+// Name is an option for Index.List which limits the listing to reposets with
+// the given name. Default is "" (all names)
+func (indexListOpts) Name(name string) IndexListOption {
+	return func(settings *IndexListSettings) error {
+		settings.Name = name
+		return nil
+	}
+}
+
+// Metastore is an option for Index.List which specifies whether metastore
+// reposets should be listed. Default is true
+func (indexListOpts) Metastore(metastore bool) IndexListOption {
+	return func(settings *IndexListSettings) error {
+		settings.Metastore = metastore
+		return nil
+	}
+}
+
+// Infostore is an option for Index.List which specifies whether infostore
+// reposets should be listed. Default is true
+func (indexListOpts) Infostore(infostore bool) IndexListOption {
+	return func(settings *IndexListSettings) error {
+		settings.Infostore = infostore
+		return nil
+	}
+}
+
+// Offset is an option for Index.List which specifies the page of results to
+// return, counted in pages of Length entries. Default is 0
+func (indexListOpts) Offset(offset int) IndexListOption {
+	return func(settings *IndexListSettings) error {
+		settings.Offset = offset
+		return nil
+	}
+}
+
+// Length is an option for Index.List which specifies the number of entries in
+// a page of results. Default is 24
+func (indexListOpts) Length(length int) IndexListOption {
+	return func(settings *IndexListSettings) error {
+		settings.Length = length
+		return nil
+	}
+}
+
+// Infostores is an option for Index.MakeReposet which specifies the paths of
+// the infostore reposets described by the new reposet. Setting it makes the
+// new reposet a metastore. Default is none
+func (indexMakeOpts) Infostores(paths ...string) IndexMakeOption {
+	return func(settings *IndexMakeSettings) error {
+		settings.Infostores = paths
+		return nil
+	}
+}
+
+// Events is an option for Index.MakeReposet which specifies the channel to
+// send *coreiface.AddEvent values to for each node added to the new reposet.
+// The channel is not closed by MakeReposet
+func (indexMakeOpts) Events(sink chan<- interface{}) IndexMakeOption {
+	return func(settings *IndexMakeSettings) error {
+		settings.Events = sink
+		return nil
+	}
+}
+
+// Progress is an option for Index.MakeReposet which specifies whether progress
+// events should be sent on the Events channel. Default is false
+func (indexMakeOpts) Progress(enable bool) IndexMakeOption {
+	return func(settings *IndexMakeSettings) error {
+		settings.Progress = enable
+		return nil
+	}
+}
+
+// Key is an option for Index.Publish which specifies the key to publish the
+// reposet with. Default is "self"
+func (indexPublishOpts) Key(key string) IndexPublishOption {
+	return func(settings *IndexPublishSettings) error {
+		settings.Key = key
+		return nil
+	}
+}
+
+// ValidTime is an option for Index.Publish which specifies for how long the
+// published record is valid. Default is 24h
+func (indexPublishOpts) ValidTime(validTime time.Duration) IndexPublishOption {
+	return func(settings *IndexPublishSettings) error {
+		settings.ValidTime = validTime
+		return nil
+	}
+}
@@ -14,9 +14,14 @@ type ObjectAddLinkSettings struct {
 	Create bool
 }
 
+type ObjectDiffSettings struct {
+	Depth int
+}
+
 type ObjectNewOption func(*ObjectNewSettings) error
 type ObjectPutOption func(*ObjectPutSettings) error
 type ObjectAddLinkOption func(*ObjectAddLinkSettings) error
+type ObjectDiffOption func(*ObjectDiffSettings) error
 
 func ObjectNewOptions(opts ...ObjectNewOption) (*ObjectNewSettings, error) {
 	options := &ObjectNewSettings{
@@ -62,6 +67,20 @@ func ObjectAddLinkOptions(opts ...ObjectAddLinkOption) (*ObjectAddLinkSettings,
 	return options, nil
 }
 
+func ObjectDiffOptions(opts ...ObjectDiffOption) (*ObjectDiffSettings, error) {
+	options := &ObjectDiffSettings{
+		Depth: -1,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
 type objectOpts struct{}
 
 var Object objectOpts
@@ -122,3 +141,13 @@ func (objectOpts) Create(create bool) ObjectAddLinkOption {
 		return nil
 	}
 }
+
+// DiffDepth is an option for Object.Diff which specifies the maximum depth at
+// which changes are reported. Modified links below it are reported as
+// modified without being descended into. Default is -1 (no depth limit)
+func (objectOpts) DiffDepth(depth int) ObjectDiffOption {
+	return func(settings *ObjectDiffSettings) error {
+		settings.Depth = depth
+		return nil
+	}
+}
@@ -1,11 +1,25 @@
 package options
 
+import (
+	"time"
+)
+
 type PinAddSettings struct {
 	Recursive bool
+	Name      string
+	Meta      map[string]string
+	ExpireIn  time.Duration
+	Owner     string
 }
 
 type PinLsSettings struct {
 	Type string
+	Name string
+	Meta map[string]string
+}
+
+type PinRmSettings struct {
+	Owner string
 }
 
 type PinUpdateSettings struct {
@@ -14,6 +28,7 @@ type PinUpdateSettings struct {
 
 type PinAddOption func(*PinAddSettings) error
 type PinLsOption func(settings *PinLsSettings) error
+type PinRmOption func(*PinRmSettings) error
 type PinUpdateOption func(*PinUpdateSettings) error
 
 func PinAddOptions(opts ...PinAddOption) (*PinAddSettings, error) {
@@ -46,6 +61,19 @@ func PinLsOptions(opts ...PinLsOption) (*PinLsSettings, error) {
 	return options, nil
 }
 
+func PinRmOptions(opts ...PinRmOption) (*PinRmSettings, error) {
+	options := &PinRmSettings{}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
 func PinUpdateOptions(opts ...PinUpdateOption) (*PinUpdateSettings, error) {
 	options := &PinUpdateSettings{
 		Unpin: true,
@@ -63,8 +91,14 @@ func PinUpdateOptions(opts ...PinUpdateOption) (*PinUpdateSettings, error) {
 
 type pinType struct{}
 
+type pinLsOpts struct{}
+
+type pinRmOpts struct{}
+
 type pinOpts struct {
 	Type pinType
+	Ls   pinLsOpts
+	Rm   pinRmOpts
 }
 
 var Pin pinOpts
@@ -102,6 +136,81 @@ func (pinOpts) Recursive(recursive bool) PinAddOption {
 	}
 }
 
+// Name is an option for Pin.Add which names the pin. Names do not have to be
+// unique
+func (pinOpts) Name(name string) PinAddOption {
+	return func(settings *PinAddSettings) error {
+		settings.Name = name
+		return nil
+	}
+}
+
+// Meta is an option for Pin.Add which attaches a key/value pair to the pin.
+// It can be given several times
+func (pinOpts) Meta(key, value string) PinAddOption {
+	return func(settings *PinAddSettings) error {
+		if settings.Meta == nil {
+			settings.Meta = make(map[string]string)
+		}
+		settings.Meta[key] = value
+		return nil
+	}
+}
+
+// ExpireIn is an option for Pin.Add which makes the pin expire after the
+// given duration. Expired pins are removed by the daemon. With the Owner
+// option, only the pin of the owner expires. Default value is 0, which means
+// the pin does not expire
+func (pinOpts) ExpireIn(d time.Duration) PinAddOption {
+	return func(settings *PinAddSettings) error {
+		settings.ExpireIn = d
+		return nil
+	}
+}
+
+// Owner is an option for Pin.Add which pins the object on behalf of the given
+// owner. The object stays pinned until all its owners, and the pin without an
+// owner if any, remove their pins with the Rm.Owner option, or until it is
+// removed without an owner
+func (pinOpts) Owner(owner string) PinAddOption {
+	return func(settings *PinAddSettings) error {
+		settings.Owner = owner
+		return nil
+	}
+}
+
+// Owner is an option for Pin.Rm which only removes the pin of the given
+// owner. The object is unpinned if it was its last owner. By default the
+// object is unpinned for all its owners
+func (pinRmOpts) Owner(owner string) PinRmOption {
+	return func(settings *PinRmSettings) error {
+		settings.Owner = owner
+		return nil
+	}
+}
+
+// Name is an option for Pin.Ls which only returns the direct and recursive
+// pins with the given name
+func (pinLsOpts) Name(name string) PinLsOption {
+	return func(settings *PinLsSettings) error {
+		settings.Name = name
+		return nil
+	}
+}
+
+// Meta is an option for Pin.Ls which only returns the direct and recursive
+// pins with the given key/value pair in their metadata. It can be given
+// several times
+func (pinLsOpts) Meta(key, value string) PinLsOption {
+	return func(settings *PinLsSettings) error {
+		if settings.Meta == nil {
+			settings.Meta = make(map[string]string)
+		}
+		settings.Meta[key] = value
+		return nil
+	}
+}
+
 // Type is an option for Pin.Ls which allows to specify which pin types should
 // be returned
 //
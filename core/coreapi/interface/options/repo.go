@@ -0,0 +1,34 @@
+package options
+
+type RepoStatSettings struct {
+	SizeOnly bool
+}
+
+type RepoStatOption func(*RepoStatSettings) error
+
+func RepoStatOptions(opts ...RepoStatOption) (*RepoStatSettings, error) {
+	options := &RepoStatSettings{
+		SizeOnly: false,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+type repoOpts struct{}
+
+var Repo repoOpts
+
+// SizeOnly is an option for Repo.Stat which specifies whether to only report
+// the repo size and the storage limit, skipping the slower object count
+func (repoOpts) SizeOnly(sizeOnly bool) RepoStatOption {
+	return func(settings *RepoStatSettings) error {
+		settings.SizeOnly = sizeOnly
+		return nil
+	}
+}
@@ -0,0 +1,36 @@
+package options
+
+type SwarmFilterSettings struct {
+	Persist bool
+}
+
+type SwarmFilterOption func(*SwarmFilterSettings) error
+
+func SwarmFilterOptions(opts ...SwarmFilterOption) (*SwarmFilterSettings, error) {
+	options := &SwarmFilterSettings{
+		Persist: false,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
+type swarmOpts struct{}
+
+var Swarm swarmOpts
+
+// Persist is an option for Swarm.FilterAdd and Swarm.FilterRm which specifies
+// whether the change should also be written to the "Swarm.AddrFilters" config
+// key, so that it survives daemon restarts. Default is false
+func (swarmOpts) Persist(persist bool) SwarmFilterOption {
+	return func(settings *SwarmFilterSettings) error {
+		settings.Persist = persist
+		return nil
+	}
+}
@@ -0,0 +1,295 @@
+package options
+
+import (
+	"errors"
+	"fmt"
+
+	cid "github.com/dms3-fs/go-cid"
+	dag "github.com/dms3-fs/go-merkledag"
+	mh "github.com/dms3-mft/go-multihash"
+)
+
+type Layout int
+
+const (
+	BalancedLayout Layout = iota
+	TrickleLayout
+)
+
+type UnixfsAddSettings struct {
+	CidVersion int
+	MhType     uint64
+
+	Inline       bool
+	InlineLimit  int
+	RawLeaves    bool
+	RawLeavesSet bool
+
+	Chunker string
+	Layout  Layout
+
+	Pin      bool
+	OnlyHash bool
+	Local    bool
+	FsCache  bool
+	NoCopy   bool
+
+	Wrap   bool
+	Hidden bool
+
+	Events   chan<- interface{}
+	Silent   bool
+	Progress bool
+}
+
+type UnixfsAddOption func(*UnixfsAddSettings) error
+
+// UnixfsAddOptions applies the given options and resolves the dependencies
+// between them. It returns the settings along with the CID prefix to use for
+// the added nodes.
+func UnixfsAddOptions(opts ...UnixfsAddOption) (*UnixfsAddSettings, cid.Prefix, error) {
+	options := &UnixfsAddSettings{
+		CidVersion: -1,
+		MhType:     mh.SHA2_256,
+
+		Inline:       false,
+		InlineLimit:  32,
+		RawLeaves:    false,
+		RawLeavesSet: false,
+
+		Chunker: "size-262144",
+		Layout:  BalancedLayout,
+
+		Pin:      false,
+		OnlyHash: false,
+		Local:    false,
+		FsCache:  false,
+		NoCopy:   false,
+
+		Wrap:   false,
+		Hidden: false,
+
+		Events:   nil,
+		Silent:   false,
+		Progress: false,
+	}
+
+	for _, opt := range opts {
+		err := opt(options)
+		if err != nil {
+			return nil, cid.Prefix{}, err
+		}
+	}
+
+	// The options are subject to the following constraints.
+	//
+	// nocopy -> rawblocks
+	// (hash != sha2-256) -> cidv1
+
+	// NOTE: 'rawblocks -> cidv1' is missing. Legacy reasons.
+
+	// nocopy -> rawblocks
+	if options.NoCopy && !options.RawLeaves {
+		// fixed?
+		if options.RawLeavesSet {
+			return nil, cid.Prefix{}, fmt.Errorf("nocopy option requires '--raw-leaves' to be enabled as well")
+		}
+
+		// No, satisfy mandatory constraint.
+		options.RawLeaves = true
+	}
+
+	// (hash != "sha2-256") -> CIDv1
+	if options.MhType != mh.SHA2_256 {
+		switch options.CidVersion {
+		case 0:
+			return nil, cid.Prefix{}, errors.New("CIDv0 only supports sha2-256")
+		case -1:
+			options.CidVersion = 1
+		}
+	}
+
+	if options.CidVersion < 0 {
+		options.CidVersion = 0
+	}
+
+	// cidV1 -> raw blocks (by default)
+	if options.CidVersion > 0 && !options.RawLeavesSet {
+		options.RawLeaves = true
+	}
+
+	prefix, err := dag.PrefixForCidVersion(options.CidVersion)
+	if err != nil {
+		return nil, cid.Prefix{}, err
+	}
+
+	prefix.MhType = options.MhType
+	prefix.MhLength = -1
+
+	return options, prefix, nil
+}
+
+type unixfsOpts struct{}
+
+var Unixfs unixfsOpts
+
+// CidVersion is an option for Unixfs.Add which specifies the CID version to use
+// for the added nodes. Default is 0, or 1 if a hash function other than
+// sha2-256 is used
+func (unixfsOpts) CidVersion(version int) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.CidVersion = version
+		return nil
+	}
+}
+
+// Hash is an option for Unixfs.Add which specifies the multihash function to
+// use for the added nodes. Hash functions other than sha2-256 imply CIDv1.
+// Default is mh.SHA2_256
+func (unixfsOpts) Hash(mhType uint64) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.MhType = mhType
+		return nil
+	}
+}
+
+// RawLeaves is an option for Unixfs.Add which specifies whether to use raw
+// blocks for the leaf nodes. By default raw leaves are used with CIDv1 and
+// with the Nocopy option
+func (unixfsOpts) RawLeaves(enable bool) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.RawLeaves = enable
+		settings.RawLeavesSet = true
+		return nil
+	}
+}
+
+// Inline is an option for Unixfs.Add which specifies whether blocks smaller
+// than the inline limit should be inlined into their CIDs. Default is false
+func (unixfsOpts) Inline(enable bool) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.Inline = enable
+		return nil
+	}
+}
+
+// InlineLimit is an option for Unixfs.Add which specifies the maximum size of
+// the blocks which are inlined with the Inline option. Default is 32
+func (unixfsOpts) InlineLimit(limit int) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.InlineLimit = limit
+		return nil
+	}
+}
+
+// Chunker is an option for Unixfs.Add which specifies the chunking algorithm,
+// either "size-[bytes]" or "rabin-[min]-[avg]-[max]". Default is
+// "size-262144"
+func (unixfsOpts) Chunker(chunker string) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.Chunker = chunker
+		return nil
+	}
+}
+
+// Layout is an option for Unixfs.Add which specifies the DAG layout of the
+// added files. Default is options.BalancedLayout
+func (unixfsOpts) Layout(layout Layout) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.Layout = layout
+		return nil
+	}
+}
+
+// Pin is an option for Unixfs.Add which specifies whether the added root
+// should be pinned recursively. Default is false
+func (unixfsOpts) Pin(pin bool) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.Pin = pin
+		return nil
+	}
+}
+
+// HashOnly is an option for Unixfs.Add which specifies whether to only
+// calculate the hashes of the added data without storing it. Default is false
+func (unixfsOpts) HashOnly(hashOnly bool) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.OnlyHash = hashOnly
+		return nil
+	}
+}
+
+// Local is an option for Unixfs.Add which specifies whether the added data
+// should only be stored locally, without announcing it to the network.
+// Default is false
+func (unixfsOpts) Local(local bool) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.Local = local
+		return nil
+	}
+}
+
+// FsCache is an option for Unixfs.Add which specifies whether to check the
+// filestore for pre-existing blocks. Default is false
+func (unixfsOpts) FsCache(enable bool) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.FsCache = enable
+		return nil
+	}
+}
+
+// Nocopy is an option for Unixfs.Add which specifies whether the data should
+// be added using the filestore, which requires it to be enabled in the config.
+// Implies raw leaves. Default is false
+func (unixfsOpts) Nocopy(enable bool) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.NoCopy = enable
+		return nil
+	}
+}
+
+// Wrap is an option for Unixfs.Add which specifies whether the added file
+// should be wrapped in a directory, which preserves its name. Default is false
+func (unixfsOpts) Wrap(wrap bool) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.Wrap = wrap
+		return nil
+	}
+}
+
+// Hidden is an option for Unixfs.Add which specifies whether hidden files
+// should be included when adding a directory. Default is false
+func (unixfsOpts) Hidden(hidden bool) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.Hidden = hidden
+		return nil
+	}
+}
+
+// Events is an option for Unixfs.Add which specifies the channel to send
+// *coreiface.AddEvent values to for each added node and, with the Progress
+// option, for progress updates. The channel is not closed by Add
+func (unixfsOpts) Events(sink chan<- interface{}) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.Events = sink
+		return nil
+	}
+}
+
+// Silent is an option for Unixfs.Add which specifies whether events for the
+// added nodes should be suppressed. Default is false
+func (unixfsOpts) Silent(silent bool) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.Silent = silent
+		return nil
+	}
+}
+
+// Progress is an option for Unixfs.Add which specifies whether progress
+// events should be sent on the Events channel. Default is false
+func (unixfsOpts) Progress(enable bool) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.Progress = enable
+		return nil
+	}
+}
@@ -2,6 +2,7 @@ package iface
 
 import (
 	"context"
+	"time"
 
 	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
 )
@@ -13,6 +14,32 @@ type Pin interface {
 
 	// Type of the pin
 	Type() string
+
+	// Name the pin was given, if any
+	Name() string
+
+	// Meta returns the metadata attached to the pin
+	Meta() map[string]string
+
+	// Created returns when the pin was created, or the zero time if it is
+	// unknown. Only named pins and pins with metadata or an expiry time
+	// record it
+	Created() time.Time
+
+	// Expires returns when the last of the pins of the owners, and the pin
+	// without an owner, expires, or the zero time if one of them does not
+	Expires() time.Time
+
+	// Owners returns the owners of the pin, which is only removed once all
+	// of them removed it
+	Owners() []string
+}
+
+// PinLsResult is sent on the channel returned by PinAPI.LsAsync. Either Pin
+// or Err is set
+type PinLsResult struct {
+	Pin Pin
+	Err error
 }
 
 // PinStatus holds information about pin health
@@ -42,8 +69,14 @@ type PinAPI interface {
 	// Ls returns list of pinned objects on this node
 	Ls(context.Context, ...options.PinLsOption) ([]Pin, error)
 
+	// LsAsync streams the pinned objects on this node. The channel is closed
+	// once all pins were sent, after an error, or when the context is
+	// cancelled, which is not reported on the channel. The indirect pins
+	// sent so far are kept in memory, so that each is only sent once
+	LsAsync(context.Context, ...options.PinLsOption) (<-chan PinLsResult, error)
+
 	// Rm removes pin for object specified by the path
-	Rm(context.Context, Path) error
+	Rm(context.Context, Path, ...options.PinRmOption) error
 
 	// Update changes one pin to another, skipping checks for matching paths in
 	// the old tree
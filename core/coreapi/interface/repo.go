@@ -0,0 +1,64 @@
+package iface
+
+import (
+	"context"
+
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	cid "github.com/dms3-fs/go-cid"
+)
+
+// RepoStat contains information about the objects stored in the repo
+type RepoStat struct {
+	// RepoSize is the size of the repo in bytes
+	RepoSize uint64
+
+	// StorageMax is the maximum datastore size in bytes
+	StorageMax uint64
+
+	// NumObjects is the number of blocks in the repo. It is not set with the
+	// SizeOnly option
+	NumObjects uint64
+
+	// RepoPath is the path of the repo. It is not set with the SizeOnly option
+	RepoPath string
+
+	// Version is the repo version. It is not set with the SizeOnly option
+	Version string
+}
+
+// RepoGcResult is sent on the channel returned by RepoAPI.Gc. It mirrors
+// gc.Result, which can't be used here as the pin packages import this one:
+// either KeyRemoved or Error is set
+type RepoGcResult struct {
+	KeyRemoved *cid.Cid
+	Error      error
+}
+
+// RepoVerifyResult is sent on the channel returned by RepoAPI.Verify for
+// every block of the repo. Corrupt is set when the block Cid failed to
+// verify. Err is set, without a Cid, when verification could not proceed
+type RepoVerifyResult struct {
+	Cid     *cid.Cid
+	Corrupt error
+	Err     error
+}
+
+// RepoAPI specifies the interface to the repo of the node
+type RepoAPI interface {
+	// Gc runs a garbage collection sweep, removing the blocks which are not
+	// pinned. The channel is closed once the sweep is done
+	Gc(context.Context) (<-chan RepoGcResult, error)
+
+	// Stat returns the size and object count of the repo
+	Stat(context.Context, ...options.RepoStatOption) (*RepoStat, error)
+
+	// Fsck removes the lockfiles of the repo at the given path. The repo must
+	// not be in use
+	Fsck(ctx context.Context, repoPath string) error
+
+	// Verify rehashes every block of the repo, reporting the status of each.
+	// The channel is closed once all blocks were checked, after an error, or
+	// when the context is cancelled
+	Verify(context.Context) (<-chan RepoVerifyResult, error)
+}
@@ -0,0 +1,64 @@
+package iface
+
+import (
+	"context"
+	"time"
+
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	ma "github.com/dms3-mft/go-multiaddr"
+	net "github.com/dms3-p2p/go-p2p-net"
+	peer "github.com/dms3-p2p/go-p2p-peer"
+	pstore "github.com/dms3-p2p/go-p2p-peerstore"
+	protocol "github.com/dms3-p2p/go-p2p-protocol"
+)
+
+// ConnectionInfo contains information about a peer
+type ConnectionInfo interface {
+	// ID returns PeerID
+	ID() peer.ID
+
+	// Address returns the multiaddress via which we are connected with the peer
+	Address() ma.Multiaddr
+
+	// Direction returns which way the connection was established
+	Direction() net.Direction
+
+	// Latency returns last known round trip time to the peer
+	Latency() (time.Duration, error)
+
+	// Streams returns list of streams established with the peer
+	Streams() ([]protocol.ID, error)
+}
+
+// SwarmAPI specifies the interface to dms3p2p swarm
+type SwarmAPI interface {
+	// Connect to a given peer
+	Connect(context.Context, pstore.PeerInfo) error
+
+	// Disconnect from a given address. If the address doesn't contain
+	// the transport part, all connections to the peer are closed
+	Disconnect(context.Context, ma.Multiaddr) error
+
+	// Peers returns the list of peers we are connected to
+	Peers(context.Context) ([]ConnectionInfo, error)
+
+	// KnownAddrs returns the list of all addresses this node is aware of
+	KnownAddrs(context.Context) (map[peer.ID][]ma.Multiaddr, error)
+
+	// LocalAddrs returns the list of announced listening addresses
+	LocalAddrs(context.Context) ([]ma.Multiaddr, error)
+
+	// ListenAddrs returns the list of all listening addresses
+	ListenAddrs(context.Context) ([]ma.Multiaddr, error)
+
+	// Filters returns the list of address filters currently applied to the
+	// swarm, in multiaddr-filter format (e.g. /ip4/192.168.0.0/ipcidr/16)
+	Filters(context.Context) ([]string, error)
+
+	// FilterAdd adds an address filter to the swarm
+	FilterAdd(ctx context.Context, filter string, opts ...options.SwarmFilterOption) error
+
+	// FilterRm removes an address filter from the swarm
+	FilterRm(ctx context.Context, filter string, opts ...options.SwarmFilterOption) error
+}
@@ -2,19 +2,45 @@ package iface
 
 import (
 	"context"
-	"io"
 
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	files "github.com/dms3-fs/go-fs-cmdkit/files"
 	dms3ld "github.com/dms3-fs/go-ld-format"
 )
 
+// AddEvent is sent on the Events channel of UnixfsAPI.Add for each added node,
+// and for progress updates when the Progress option is set. Progress updates
+// only have Name and Bytes set
+type AddEvent struct {
+	Name  string
+	Hash  string `json:",omitempty"`
+	Bytes int64  `json:",omitempty"`
+	Size  string `json:",omitempty"`
+}
+
+// UnixfsLsResult is sent on the channel returned by UnixfsAPI.LsAsync. Either
+// Link or Err is set
+type UnixfsLsResult struct {
+	Link *dms3ld.Link
+	Err  error
+}
+
 // UnixfsAPI is the basic interface to immutable files in DMS3FS
 type UnixfsAPI interface {
-	// Add imports the data from the reader into merkledag file
-	Add(context.Context, io.Reader) (ResolvedPath, error)
+	// Add imports the file, or the directory tree, into the merkledag and
+	// returns the path of the resulting root node
+	Add(context.Context, files.File, ...options.UnixfsAddOption) (ResolvedPath, error)
 
 	// Cat returns a reader for the file
 	Cat(context.Context, Path) (Reader, error)
 
 	// Ls returns the list of links in a directory
 	Ls(context.Context, Path) ([]*dms3ld.Link, error)
+
+	// LsAsync streams the links in a directory, without loading the whole
+	// listing of large sharded directories in memory. The channel is closed
+	// once all links were sent, after an error, or when the context is
+	// cancelled, which is not reported on the channel
+	LsAsync(context.Context, Path) (<-chan UnixfsLsResult, error)
 }
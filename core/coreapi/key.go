@@ -101,31 +101,79 @@ func (api *KeyAPI) Generate(ctx context.Context, name string, opts ...caopts.Key
 
 // List returns a list keys stored in keystore.
 func (api *KeyAPI) List(ctx context.Context) ([]coreiface.Key, error) {
-	keys, err := api.node.Repo.Keystore().List()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	keys, err := api.ListAsync(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	sort.Strings(keys)
+	out := []coreiface.Key{}
+	for r := range keys {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		out = append(out, r.Key)
+	}
 
-	out := make([]coreiface.Key, len(keys)+1)
-	out[0] = &key{"self", api.node.Identity}
+	// the channel is closed without an error when the context is cancelled
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	for n, k := range keys {
-		privKey, err := api.node.Repo.Keystore().Get(k)
-		if err != nil {
-			return nil, err
+	return out, nil
+}
+
+// ListAsync streams the keys stored in the keystore, starting with 'self'
+// followed by the other keys sorted by name. Keys are read from the keystore
+// one at a time as they are consumed.
+func (api *KeyAPI) ListAsync(ctx context.Context) (<-chan coreiface.KeyListResult, error) {
+	ks := api.node.Repo.Keystore()
+
+	names, err := ks.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(names)
+
+	out := make(chan coreiface.KeyListResult)
+	go func() {
+		defer close(out)
+
+		send := func(r coreiface.KeyListResult) bool {
+			select {
+			case out <- r:
+				return true
+			case <-ctx.Done():
+				return false
+			}
 		}
 
-		pubKey := privKey.GetPublic()
+		if !send(coreiface.KeyListResult{Key: &key{"self", api.node.Identity}}) {
+			return
+		}
 
-		pid, err := peer.IDFromPublicKey(pubKey)
-		if err != nil {
-			return nil, err
+		for _, k := range names {
+			privKey, err := ks.Get(k)
+			if err != nil {
+				send(coreiface.KeyListResult{Err: err})
+				return
+			}
+
+			pid, err := peer.IDFromPublicKey(privKey.GetPublic())
+			if err != nil {
+				send(coreiface.KeyListResult{Err: err})
+				return
+			}
+
+			if !send(coreiface.KeyListResult{Key: &key{k, pid}}) {
+				return
+			}
 		}
+	}()
 
-		out[n+1] = &key{k, pid}
-	}
 	return out, nil
 }
 
@@ -0,0 +1,29 @@
+package coreapi
+
+import (
+	"time"
+
+	mfs "github.com/dms3-fs/go-mfs"
+	prometheus "github.com/gxed/client_golang/prometheus"
+)
+
+var mfsFlushDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Namespace: "dms3fs",
+	Subsystem: "mfs",
+	Name:      "flush_duration_seconds",
+	Help:      "Latency of MFS flushes",
+	Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+})
+
+func init() {
+	prometheus.MustRegister(mfsFlushDuration)
+}
+
+// flushPath flushes the MFS path p, recording the latency of the flush.
+func flushPath(root *mfs.Root, p string) error {
+	defer func(start time.Time) {
+		mfsFlushDuration.Observe(time.Since(start).Seconds())
+	}(time.Now())
+
+	return mfs.FlushPath(root, p)
+}
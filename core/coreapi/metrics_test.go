@@ -0,0 +1,41 @@
+package coreapi_test
+
+import (
+	"context"
+	"testing"
+
+	prometheus "github.com/gxed/client_golang/prometheus"
+)
+
+// flushSamples returns the number of MFS flush latencies recorded.
+func flushSamples(t *testing.T) uint64 {
+	t.Helper()
+
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() == "dms3fs_mfs_flush_duration_seconds" {
+			return mf.GetMetric()[0].GetHistogram().GetSampleCount()
+		}
+	}
+	t.Fatal("MFS flush latency metric is not registered")
+	return 0
+}
+
+func TestFilesFlushMetrics(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := flushSamples(t)
+	if err := api.Files().Flush(ctx, "/"); err != nil {
+		t.Fatal(err)
+	}
+	if after := flushSamples(t); after != before+1 {
+		t.Fatalf("expected %d flush samples, got %d", before+1, after)
+	}
+}
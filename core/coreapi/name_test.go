@@ -3,10 +3,12 @@ package coreapi_test
 import (
 	"context"
 	"io"
+	"io/ioutil"
 	"math/rand"
 	"testing"
 	"time"
 
+	files "github.com/dms3-fs/go-fs-cmdkit/files"
 	ipath "github.com/dms3-fs/go-path"
 
 	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
@@ -16,7 +18,7 @@ import (
 var rnd = rand.New(rand.NewSource(0x62796532303137))
 
 func addTestObject(ctx context.Context, api coreiface.CoreAPI) (coreiface.Path, error) {
-	return api.Unixfs().Add(ctx, &io.LimitedReader{R: rnd, N: 4092})
+	return api.Unixfs().Add(ctx, files.NewReaderFile("", "", ioutil.NopCloser(&io.LimitedReader{R: rnd, N: 4092}), nil))
 }
 
 func TestBasicPublishResolve(t *testing.T) {
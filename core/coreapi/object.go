@@ -297,7 +297,12 @@ func (api *ObjectAPI) patchData(ctx context.Context, path coreiface.Path, r io.R
 	return coreiface.Dms3FsPath(pbnd.Cid()), nil
 }
 
-func (api *ObjectAPI) Diff(ctx context.Context, before coreiface.Path, after coreiface.Path) ([]coreiface.ObjectChange, error) {
+func (api *ObjectAPI) Diff(ctx context.Context, before coreiface.Path, after coreiface.Path, opts ...caopts.ObjectDiffOption) ([]coreiface.ObjectChange, error) {
+	settings, err := caopts.ObjectDiffOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
 	beforeNd, err := api.core().ResolveNode(ctx, before)
 	if err != nil {
 		return nil, err
@@ -308,28 +313,35 @@ func (api *ObjectAPI) Diff(ctx context.Context, before coreiface.Path, after cor
 		return nil, err
 	}
 
-	changes, err := dagutils.Diff(ctx, api.node.DAG, beforeNd, afterNd)
+	changes, err := dagutils.DiffDepth(ctx, api.node.DAG, beforeNd, afterNd, settings.Depth)
 	if err != nil {
 		return nil, err
 	}
 
 	out := make([]coreiface.ObjectChange, len(changes))
 	for i, change := range changes {
-		out[i] = coreiface.ObjectChange{
-			Type: change.Type,
-			Path: change.Path,
-		}
+		out[i] = objectChange(change, coreiface.Dms3FsPath)
+	}
 
-		if change.Before != nil {
-			out[i].Before = coreiface.Dms3FsPath(change.Before)
-		}
+	return out, nil
+}
 
-		if change.After != nil {
-			out[i].After = coreiface.Dms3FsPath(change.After)
-		}
+// objectChange converts the dagutils change, building its paths with mkPath
+func objectChange(change *dagutils.Change, mkPath func(*cid.Cid) coreiface.ResolvedPath) coreiface.ObjectChange {
+	out := coreiface.ObjectChange{
+		Type: change.Type,
+		Path: change.Path,
 	}
 
-	return out, nil
+	if change.Before != nil {
+		out.Before = mkPath(change.Before)
+	}
+
+	if change.After != nil {
+		out.After = mkPath(change.After)
+	}
+
+	return out
 }
 
 func (api *ObjectAPI) core() coreiface.CoreAPI {
@@ -14,6 +14,9 @@ import (
 
 	cid "github.com/dms3-fs/go-cid"
 	dms3ld "github.com/dms3-fs/go-ld-format"
+	opentracing "github.com/opentracing/opentracing-go"
+	ext "github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
 )
 
 // ResolveNode resolves the path `p` using Unixfs resolver, gets and returns the
@@ -41,13 +44,23 @@ func resolveNode(ctx context.Context, ng dms3ld.NodeGetter, nsys namesys.NameSys
 	return node, nil
 }
 
-func resolvePath(ctx context.Context, ng dms3ld.NodeGetter, nsys namesys.NameSystem, p coreiface.Path) (coreiface.ResolvedPath, error) {
+func resolvePath(ctx context.Context, ng dms3ld.NodeGetter, nsys namesys.NameSystem, p coreiface.Path) (rp coreiface.ResolvedPath, err error) {
 	if _, ok := p.(coreiface.ResolvedPath); ok {
 		return p.(coreiface.ResolvedPath), nil
 	}
 
+	span, ctx := opentracing.StartSpanFromContext(ctx, "coreapi.ResolvePath")
+	span.SetTag("path", p.String())
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(otlog.Error(err))
+		}
+		span.Finish()
+	}()
+
 	ipath := dms3fspath.Path(p.String())
-	ipath, err := core.ResolveDMS3NS(ctx, nsys, ipath)
+	ipath, err = core.ResolveDMS3NS(ctx, nsys, ipath)
 	if err == core.ErrNoNamesys {
 		return nil, coreiface.ErrOffline
 	} else if err != nil {
@@ -3,11 +3,13 @@ package coreapi
 import (
 	"context"
 	"fmt"
+	"time"
 
 	bserv "github.com/dms3-fs/go-blockservice"
 	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
 	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
 	corerepo "github.com/dms3-fs/go-dms3-fs/core/corerepo"
+	"github.com/dms3-fs/go-dms3-fs/pin"
 	merkledag "github.com/dms3-fs/go-merkledag"
 
 	cid "github.com/dms3-fs/go-cid"
@@ -29,7 +31,14 @@ func (api *PinAPI) Add(ctx context.Context, p coreiface.Path, opts ...caopts.Pin
 		return err
 	}
 
-	_, err = corerepo.Pin(api.node, ctx, []string{rp.Cid().String()}, settings.Recursive)
+	info := pin.Info{Name: settings.Name, Meta: settings.Meta}
+	if settings.ExpireIn > 0 {
+		info.Expires = time.Now().Add(settings.ExpireIn)
+	}
+	if settings.Owner != "" {
+		info.Owners = []string{settings.Owner}
+	}
+	_, err = corerepo.PinWithInfo(api.node, ctx, []string{rp.Cid().String()}, settings.Recursive, info)
 	if err != nil {
 		return err
 	}
@@ -38,6 +47,36 @@ func (api *PinAPI) Add(ctx context.Context, p coreiface.Path, opts ...caopts.Pin
 }
 
 func (api *PinAPI) Ls(ctx context.Context, opts ...caopts.PinLsOption) ([]coreiface.Pin, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pins, err := api.LsAsync(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []coreiface.Pin{}
+	for r := range pins {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		out = append(out, r.Pin)
+	}
+
+	// the channel is closed without an error when the context is cancelled
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// LsAsync streams the pins of the given type. Each pinned object is sent once:
+// recursive pins come first, followed by the indirect pins which are not
+// pinned recursively, and the direct pins which are not pinned otherwise.
+// Filtering by name or metadata only returns direct and recursive pins, as
+// indirect pins have no info.
+func (api *PinAPI) LsAsync(ctx context.Context, opts ...caopts.PinLsOption) (<-chan coreiface.PinLsResult, error) {
 	settings, err := caopts.PinLsOptions(opts...)
 	if err != nil {
 		return nil, err
@@ -49,11 +88,41 @@ func (api *PinAPI) Ls(ctx context.Context, opts ...caopts.PinLsOption) ([]coreif
 		return nil, fmt.Errorf("invalid type '%s', must be one of {direct, indirect, recursive, all}", settings.Type)
 	}
 
-	return api.pinLsAll(settings.Type, ctx)
+	out := make(chan coreiface.PinLsResult)
+	go func() {
+		defer close(out)
+
+		filter := settings.Name != "" || len(settings.Meta) > 0
+		err := api.pinLsAll(ctx, settings.Type, func(p *pinInfo) bool {
+			if filter && (p.pinType == "indirect" || !p.info.Matches(settings.Name, settings.Meta)) {
+				return true
+			}
+
+			select {
+			case out <- coreiface.PinLsResult{Pin: p}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+		if err != nil {
+			select {
+			case out <- coreiface.PinLsResult{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return out, nil
 }
 
-func (api *PinAPI) Rm(ctx context.Context, p coreiface.Path) error {
-	_, err := corerepo.Unpin(api.node, ctx, []string{p.String()}, true)
+func (api *PinAPI) Rm(ctx context.Context, p coreiface.Path, opts ...caopts.PinRmOption) error {
+	settings, err := caopts.PinRmOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	_, err = corerepo.UnpinOwner(api.node, ctx, []string{p.String()}, true, settings.Owner)
 	if err != nil {
 		return err
 	}
@@ -157,6 +226,7 @@ func (api *PinAPI) Verify(ctx context.Context) (<-chan coreiface.PinStatus, erro
 type pinInfo struct {
 	pinType string
 	path    coreiface.ResolvedPath
+	info    pin.Info
 }
 
 func (p *pinInfo) Path() coreiface.ResolvedPath {
@@ -167,42 +237,99 @@ func (p *pinInfo) Type() string {
 	return p.pinType
 }
 
-func (api *PinAPI) pinLsAll(typeStr string, ctx context.Context) ([]coreiface.Pin, error) {
+func (p *pinInfo) Name() string {
+	return p.info.Name
+}
+
+func (p *pinInfo) Meta() map[string]string {
+	return p.info.Meta
+}
+
+func (p *pinInfo) Created() time.Time {
+	return p.info.Created
+}
 
-	keys := make(map[string]*pinInfo)
+func (p *pinInfo) Expires() time.Time {
+	return p.info.Expiry()
+}
 
-	AddToResultKeys := func(keyList []*cid.Cid, typeStr string) {
-		for _, c := range keyList {
-			keys[c.String()] = &pinInfo{
-				pinType: typeStr,
-				path:    coreiface.Dms3LdPath(c),
+func (p *pinInfo) Owners() []string {
+	return p.info.Owners
+}
+
+// keyPinInfo returns the pinInfo of a direct or recursive pin, with its info
+func (api *PinAPI) keyPinInfo(pinType string, c *cid.Cid) *pinInfo {
+	info, _ := api.node.Pinning.Info(c)
+	return &pinInfo{pinType: pinType, path: coreiface.Dms3LdPath(c), info: info}
+}
+
+// pinLsAll calls send for each pin of type typeStr until send returns false.
+// The pins are sent as they are found rather than once all are listed, but
+// listing the indirect pins still keeps all of them in memory by the end of
+// the enumeration: the set of the visited ones is needed to send each once,
+// and to leave out the direct pins which are also pinned indirectly.
+func (api *PinAPI) pinLsAll(ctx context.Context, typeStr string, send func(*pinInfo) bool) error {
+	all := typeStr == "all"
+	recursive := api.node.Pinning.RecursiveKeys()
+
+	recursiveSet := cid.NewSet()
+	for _, c := range recursive {
+		recursiveSet.Add(c)
+	}
+
+	if typeStr == "recursive" || all {
+		for _, c := range recursive {
+			if !send(api.keyPinInfo("recursive", c)) {
+				return ctx.Err()
 			}
 		}
 	}
 
-	if typeStr == "direct" || typeStr == "all" {
-		AddToResultKeys(api.node.Pinning.DirectKeys(), "direct")
-	}
-	if typeStr == "indirect" || typeStr == "all" {
-		set := cid.NewSet()
-		for _, k := range api.node.Pinning.RecursiveKeys() {
-			err := merkledag.EnumerateChildren(ctx, merkledag.GetLinksWithDAG(api.node.DAG), k, set.Visit)
+	// indirect pins must be enumerated to know which direct pins are
+	// also pinned indirectly
+	indirectSet := cid.NewSet()
+	if typeStr == "indirect" || all {
+		stopped := false
+		visit := func(c *cid.Cid) bool {
+			if stopped || !indirectSet.Visit(c) {
+				return false
+			}
+
+			if all && recursiveSet.Has(c) {
+				return true
+			}
+
+			if !send(&pinInfo{pinType: "indirect", path: coreiface.Dms3LdPath(c)}) {
+				stopped = true
+				return false
+			}
+			return true
+		}
+
+		for _, k := range recursive {
+			err := merkledag.EnumerateChildren(ctx, merkledag.GetLinksWithDAG(api.node.DAG), k, visit)
 			if err != nil {
-				return nil, err
+				return err
+			}
+			if stopped {
+				return ctx.Err()
 			}
 		}
-		AddToResultKeys(set.Keys(), "indirect")
-	}
-	if typeStr == "recursive" || typeStr == "all" {
-		AddToResultKeys(api.node.Pinning.RecursiveKeys(), "recursive")
 	}
 
-	out := make([]coreiface.Pin, 0, len(keys))
-	for _, v := range keys {
-		out = append(out, v)
+	if typeStr == "direct" || all {
+		for _, c := range api.node.Pinning.DirectKeys() {
+			if all && (recursiveSet.Has(c) || indirectSet.Has(c)) {
+				continue
+			}
+
+			if !send(api.keyPinInfo("direct", c)) {
+				return ctx.Err()
+			}
+		}
 	}
 
-	return out, nil
+	return nil
 }
 
 func (api *PinAPI) core() coreiface.CoreAPI {
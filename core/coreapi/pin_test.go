@@ -4,8 +4,11 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
 	opt "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+	corerepo "github.com/dms3-fs/go-dms3-fs/core/corerepo"
 )
 
 func TestPinAdd(t *testing.T) {
@@ -15,7 +18,7 @@ func TestPinAdd(t *testing.T) {
 		t.Error(err)
 	}
 
-	p, err := api.Unixfs().Add(ctx, strings.NewReader("foo"))
+	p, err := api.Unixfs().Add(ctx, strFile("foo")())
 	if err != nil {
 		t.Error(err)
 	}
@@ -33,7 +36,7 @@ func TestPinSimple(t *testing.T) {
 		t.Error(err)
 	}
 
-	p, err := api.Unixfs().Add(ctx, strings.NewReader("foo"))
+	p, err := api.Unixfs().Add(ctx, strFile("foo")())
 	if err != nil {
 		t.Error(err)
 	}
@@ -82,12 +85,12 @@ func TestPinRecursive(t *testing.T) {
 		t.Error(err)
 	}
 
-	p0, err := api.Unixfs().Add(ctx, strings.NewReader("foo"))
+	p0, err := api.Unixfs().Add(ctx, strFile("foo")())
 	if err != nil {
 		t.Error(err)
 	}
 
-	p1, err := api.Unixfs().Add(ctx, strings.NewReader("bar"))
+	p1, err := api.Unixfs().Add(ctx, strFile("bar")())
 	if err != nil {
 		t.Error(err)
 	}
@@ -209,3 +212,434 @@ func TestPinRecursive(t *testing.T) {
 		t.Errorf("unexpected verify result count: %d", n)
 	}
 }
+
+func TestPinLsAsync(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	p0, err := api.Unixfs().Add(ctx, strFile("foo")())
+	if err != nil {
+		t.Error(err)
+	}
+
+	p1, err := api.Dag().Put(ctx, strings.NewReader(`{"lnk": {"/": "`+p0.Cid().String()+`"}}`))
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = api.Pin().Add(ctx, p1)
+	if err != nil {
+		t.Error(err)
+	}
+
+	res, err := api.Pin().LsAsync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	types := map[string]string{}
+	for r := range res {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		types[r.Pin.Path().Cid().String()] = r.Pin.Type()
+	}
+
+	if len(types) != 2 {
+		t.Errorf("unexpected pin count: %d", len(types))
+	}
+
+	// p0 is both pinned recursively and referenced by p1, it must only be
+	// listed once as a recursive pin
+	if types[p0.Cid().String()] != "recursive" || types[p1.Cid().String()] != "recursive" {
+		t.Errorf("unexpected pin types: %v", types)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	res, err = api.Pin().LsAsync(cctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-res
+	cancel()
+	for range res {
+	}
+}
+
+func TestPinInfo(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	p0, err := api.Unixfs().Add(ctx, strFile("foo")())
+	if err != nil {
+		t.Error(err)
+	}
+
+	p1, err := api.Unixfs().Add(ctx, strFile("bar")())
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = api.Pin().Add(ctx, p0, opt.Pin.Name("foo"), opt.Pin.Meta("lang", "en"), opt.Pin.Meta("kind", "text"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Pin().Add(ctx, p1, opt.Pin.Name("bar"), opt.Pin.Meta("lang", "fr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := api.Pin().Ls(ctx, opt.Pin.Ls.Meta("lang", "en"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 1 {
+		t.Fatalf("unexpected pin list len: %d", len(list))
+	}
+
+	if list[0].Path().Cid().String() != p0.Cid().String() {
+		t.Error("paths don't match")
+	}
+
+	if list[0].Name() != "foo" || list[0].Meta()["kind"] != "text" {
+		t.Errorf("unexpected pin info: %q %v", list[0].Name(), list[0].Meta())
+	}
+
+	if list[0].Created().IsZero() {
+		t.Error("expected the creation time of the pin to be set")
+	}
+
+	list, err = api.Pin().Ls(ctx, opt.Pin.Ls.Name("bar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 1 || list[0].Path().Cid().String() != p1.Cid().String() {
+		t.Errorf("unexpected pins named bar: %v", list)
+	}
+
+	list, err = api.Pin().Ls(ctx, opt.Pin.Ls.Name("foo"), opt.Pin.Ls.Meta("lang", "fr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 0 {
+		t.Errorf("unexpected pin list len: %d", len(list))
+	}
+}
+
+func TestPinExpiry(t *testing.T) {
+	ctx := context.Background()
+	nd, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	p0, err := api.Unixfs().Add(ctx, strFile("foo")())
+	if err != nil {
+		t.Error(err)
+	}
+
+	p1, err := api.Unixfs().Add(ctx, strFile("bar")())
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = api.Pin().Add(ctx, p0, opt.Pin.ExpireIn(time.Nanosecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Pin().Add(ctx, p1, opt.Pin.ExpireIn(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := api.Pin().Ls(ctx, opt.Pin.Type.Recursive())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range list {
+		if p.Expires().IsZero() {
+			t.Errorf("expected %s to expire", p.Path())
+		}
+	}
+
+	unpinned, err := corerepo.UnpinExpired(nd, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(unpinned) != 1 || unpinned[0].String() != p0.Cid().String() {
+		t.Fatalf("expected only %s to be unpinned, got %v", p0.Cid(), unpinned)
+	}
+
+	list, err = api.Pin().Ls(ctx, opt.Pin.Type.Recursive())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 1 || list[0].Path().Cid().String() != p1.Cid().String() {
+		t.Errorf("unexpected pins after unpinning the expired ones: %v", list)
+	}
+}
+
+func TestPinRepinExpiry(t *testing.T) {
+	ctx := context.Background()
+	nd, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	p0, err := api.Unixfs().Add(ctx, strFile("foo")())
+	if err != nil {
+		t.Error(err)
+	}
+
+	p1, err := api.Unixfs().Add(ctx, strFile("bar")())
+	if err != nil {
+		t.Error(err)
+	}
+
+	pinned := func(p coreiface.Path) coreiface.Pin {
+		t.Helper()
+		list, err := api.Pin().Ls(ctx, opt.Pin.Type.Recursive())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, pin := range list {
+			if pin.Path().Cid().String() == p.Cid().String() {
+				return pin
+			}
+		}
+		t.Fatalf("%s is not pinned", p)
+		return nil
+	}
+
+	// re-pinning a permanent pin with an expiry keeps it and its info
+	err = api.Pin().Add(ctx, p0, opt.Pin.Name("foo"), opt.Pin.Meta("lang", "en"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := pinned(p0).Created()
+
+	err = api.Pin().Add(ctx, p0, opt.Pin.ExpireIn(time.Nanosecond), opt.Pin.Meta("kind", "text"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pin := pinned(p0)
+	if !pin.Expires().IsZero() {
+		t.Errorf("expected the permanent pin not to expire, expires at %s", pin.Expires())
+	}
+	if pin.Name() != "foo" || pin.Meta()["lang"] != "en" || pin.Meta()["kind"] != "text" {
+		t.Errorf("unexpected pin info after re-pinning: %q %v", pin.Name(), pin.Meta())
+	}
+	if !pin.Created().Equal(created) {
+		t.Errorf("expected the creation time %s to be kept, got %s", created, pin.Created())
+	}
+
+	unpinned, err := corerepo.UnpinExpired(nd, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unpinned) != 0 {
+		t.Fatalf("expected no pin to expire, got %v", unpinned)
+	}
+
+	// the expiry of a pin is never brought forward
+	err = api.Pin().Add(ctx, p1, opt.Pin.ExpireIn(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expires := pinned(p1).Expires()
+
+	err = api.Pin().Add(ctx, p1, opt.Pin.ExpireIn(time.Nanosecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := pinned(p1).Expires(); !e.Equal(expires) {
+		t.Errorf("expected the expiry %s to be kept, got %s", expires, e)
+	}
+
+	err = api.Pin().Add(ctx, p1, opt.Pin.ExpireIn(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := pinned(p1).Expires(); !e.After(expires) {
+		t.Errorf("expected the expiry %s to be pushed back, got %s", expires, e)
+	}
+}
+
+func TestPinOwners(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	p, err := api.Unixfs().Add(ctx, strFile("foo")())
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, owner := range []string{"app1", "app2"} {
+		err = api.Pin().Add(ctx, p, opt.Pin.Owner(owner))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := api.Pin().Ls(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 1 || len(list[0].Owners()) != 2 {
+		t.Fatalf("expected one pin with two owners, got %v", list)
+	}
+
+	err = api.Pin().Rm(ctx, p, opt.Pin.Rm.Owner("app1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err = api.Pin().Ls(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 1 || len(list[0].Owners()) != 1 || list[0].Owners()[0] != "app2" {
+		t.Fatalf("expected the pin to be kept for app2, got %v", list)
+	}
+
+	err = api.Pin().Rm(ctx, p, opt.Pin.Rm.Owner("app2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err = api.Pin().Ls(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 0 {
+		t.Errorf("unexpected pin list len: %d", len(list))
+	}
+}
+
+func TestPinUnownedAndOwners(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	p, err := api.Unixfs().Add(ctx, strFile("foo")())
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = api.Pin().Add(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Pin().Add(ctx, p, opt.Pin.Owner("app1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Pin().Rm(ctx, p, opt.Pin.Rm.Owner("app1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := api.Pin().Ls(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 1 || len(list[0].Owners()) != 0 {
+		t.Fatalf("expected the pin to be kept without an owner, got %v", list)
+	}
+}
+
+func TestPinOwnerExpiry(t *testing.T) {
+	ctx := context.Background()
+	nd, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	p, err := api.Unixfs().Add(ctx, strFile("foo")())
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = api.Pin().Add(ctx, p, opt.Pin.Owner("app1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.Pin().Add(ctx, p, opt.Pin.Owner("app2"), opt.Pin.ExpireIn(time.Nanosecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unpinned, err := corerepo.UnpinExpired(nd, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unpinned) != 0 {
+		t.Fatalf("expected no pin to be removed while app1 owns it, got %v", unpinned)
+	}
+
+	list, err := api.Pin().Ls(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 1 || len(list[0].Owners()) != 1 || list[0].Owners()[0] != "app1" {
+		t.Fatalf("expected the pin to be kept for app1 only, got %v", list)
+	}
+	if !list[0].Expires().IsZero() {
+		t.Errorf("expected the pin of app1 not to expire, expires at %s", list[0].Expires())
+	}
+}
+
+func TestPinLsCancel(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	p, err := api.Unixfs().Add(ctx, strFile("foo")())
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = api.Pin().Add(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	list, err := api.Pin().Ls(cctx)
+	if err != context.Canceled {
+		t.Fatalf("expected the cancellation to be returned, got %v with %d pins", err, len(list))
+	}
+}
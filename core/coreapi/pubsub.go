@@ -0,0 +1,166 @@
+package coreapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	core "github.com/dms3-fs/go-dms3-fs/core"
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	blocks "github.com/dms3-fs/go-block-format"
+	cid "github.com/dms3-fs/go-cid"
+	floodsub "github.com/dms3-p2p/go-floodsub"
+	peer "github.com/dms3-p2p/go-p2p-peer"
+	pstore "github.com/dms3-p2p/go-p2p-peerstore"
+)
+
+type PubSubAPI CoreAPI
+
+type pubSubSubscription struct {
+	cancel       context.CancelFunc
+	subscription *floodsub.Subscription
+}
+
+type pubSubMessage struct {
+	msg *floodsub.Message
+}
+
+// Ls lists the names of topics this node is subscribed to.
+func (api *PubSubAPI) Ls(ctx context.Context) ([]string, error) {
+	if err := api.checkNode(); err != nil {
+		return nil, err
+	}
+
+	return api.node.Floodsub.GetTopics(), nil
+}
+
+// Peers lists the pubsub peers this node is connected to. With the Topic
+// option only peers subscribed to that topic are returned.
+func (api *PubSubAPI) Peers(ctx context.Context, opts ...caopts.PubSubPeersOption) ([]peer.ID, error) {
+	settings, err := caopts.PubSubPeersOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := api.checkNode(); err != nil {
+		return nil, err
+	}
+
+	return api.node.Floodsub.ListPeers(settings.Topic), nil
+}
+
+// Publish publishes the message `data` to the given topic.
+func (api *PubSubAPI) Publish(ctx context.Context, topic string, data []byte) error {
+	if err := api.checkNode(); err != nil {
+		return err
+	}
+
+	return api.node.Floodsub.Publish(topic, data)
+}
+
+// Subscribe subscribes to messages on the given topic. The subscription stays
+// active until it is closed or the node shuts down.
+func (api *PubSubAPI) Subscribe(ctx context.Context, topic string, opts ...caopts.PubSubSubscribeOption) (coreiface.PubSubSubscription, error) {
+	settings, err := caopts.PubSubSubscribeOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := api.checkNode(); err != nil {
+		return nil, err
+	}
+
+	sub, err := api.node.Floodsub.Subscribe(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	pubctx, cancel := context.WithCancel(api.node.Context())
+
+	if settings.Discover {
+		go func() {
+			blk := blocks.NewBlock([]byte("floodsub:" + topic))
+			err := api.node.Blocks.AddBlock(blk)
+			if err != nil {
+				log.Error("pubsub discovery: ", err)
+				return
+			}
+
+			connectToPubSubPeers(pubctx, api.node, blk.Cid())
+		}()
+	}
+
+	return &pubSubSubscription{cancel, sub}, nil
+}
+
+func connectToPubSubPeers(ctx context.Context, n *core.Dms3FsNode, cid *cid.Cid) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	provs := n.Routing.FindProvidersAsync(ctx, cid, 10)
+	wg := &sync.WaitGroup{}
+	for p := range provs {
+		wg.Add(1)
+		go func(pi pstore.PeerInfo) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+			defer cancel()
+			err := n.PeerHost.Connect(ctx, pi)
+			if err != nil {
+				log.Info("pubsub discover: ", err)
+				return
+			}
+			log.Info("connected to pubsub peer:", pi.ID)
+		}(p)
+	}
+
+	wg.Wait()
+}
+
+func (api *PubSubAPI) checkNode() error {
+	if !api.node.OnlineMode() {
+		return coreiface.ErrOffline
+	}
+
+	if api.node.Floodsub == nil {
+		return errors.New("experimental pubsub feature not enabled. Run daemon with --enable-pubsub-experiment to use")
+	}
+
+	return nil
+}
+
+// Close cancels the subscription and any pending peer discovery.
+func (sub *pubSubSubscription) Close() error {
+	sub.cancel()
+	sub.subscription.Cancel()
+	return nil
+}
+
+// Next blocks until the next message on the topic arrives or `ctx` is done.
+func (sub *pubSubSubscription) Next(ctx context.Context) (coreiface.PubSubMessage, error) {
+	msg, err := sub.subscription.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pubSubMessage{msg}, nil
+}
+
+func (msg *pubSubMessage) From() peer.ID {
+	return peer.ID(msg.msg.From)
+}
+
+func (msg *pubSubMessage) Data() []byte {
+	return msg.msg.Data
+}
+
+func (msg *pubSubMessage) Seq() []byte {
+	return msg.msg.Seqno
+}
+
+func (msg *pubSubMessage) Topics() []string {
+	return msg.msg.TopicIDs
+}
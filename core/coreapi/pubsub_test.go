@@ -0,0 +1,104 @@
+package coreapi_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+)
+
+func TestBasicPubSub(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nds, apis, err := makeAPISwarm(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sub, err := apis[0].PubSub().Subscribe(ctx, "testch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	go func() {
+		tick := time.Tick(100 * time.Millisecond)
+
+		for {
+			err := apis[1].PubSub().Publish(ctx, "testch", []byte("hello world"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	m, err := sub.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(m.Data()) != "hello world" {
+		t.Errorf("got invalid data: %s", string(m.Data()))
+	}
+
+	if m.From() != nds[1].Identity {
+		t.Errorf("m.From didn't match")
+	}
+
+	if len(m.Topics()) != 1 || m.Topics()[0] != "testch" {
+		t.Errorf("unexpected topics: %v", m.Topics())
+	}
+
+	topics, err := apis[0].PubSub().Ls(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(topics) != 1 || topics[0] != "testch" {
+		t.Errorf("unexpected topic list: %v", topics)
+	}
+
+	peers, err := apis[1].PubSub().Peers(ctx, options.PubSub.Topic("testch"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(peers) != 1 {
+		t.Fatalf("got incorrect number of peers: %d", len(peers))
+	}
+
+	if peers[0] != nds[0].Identity {
+		t.Errorf("peer didn't match")
+	}
+
+	peers, err = apis[1].PubSub().Peers(ctx, options.PubSub.Topic("nottestch"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(peers) != 0 {
+		t.Errorf("got incorrect number of peers: %d", len(peers))
+	}
+}
+
+func TestPubSubOffline(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = api.PubSub().Subscribe(ctx, "testch")
+	if err != coreiface.ErrOffline {
+		t.Errorf("expected ErrOffline, got %v", err)
+	}
+}
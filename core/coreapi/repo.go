@@ -0,0 +1,129 @@
+package coreapi
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+	corerepo "github.com/dms3-fs/go-dms3-fs/core/corerepo"
+	fsrepo "github.com/dms3-fs/go-dms3-fs/repo/fsrepo"
+
+	bstore "github.com/dms3-fs/go-fs-blockstore"
+)
+
+// errRepoInUse is returned by Fsck for a repo which is locked
+var errRepoInUse = errors.New("repo fsck: the repo is in use, no daemon may be running")
+
+type RepoAPI CoreAPI
+
+// Gc runs a garbage collection sweep on the repo. Every removed block, or
+// error met during the sweep, is sent on the returned channel, which is
+// closed once the sweep is done.
+func (api *RepoAPI) Gc(ctx context.Context) (<-chan coreiface.RepoGcResult, error) {
+	gcOut := corerepo.GarbageCollectAsync(api.node, ctx)
+
+	out := make(chan coreiface.RepoGcResult)
+	go func() {
+		defer close(out)
+
+		for res := range gcOut {
+			select {
+			case out <- coreiface.RepoGcResult{KeyRemoved: res.KeyRemoved, Error: res.Error}:
+			case <-ctx.Done():
+				// the collection sends its errors without checking
+				// the context, and must not block with the GC lock held
+				for range gcOut {
+				}
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// Stat returns the size and object count of the repo. With the SizeOnly
+// option, only RepoSize and StorageMax are set.
+func (api *RepoAPI) Stat(ctx context.Context, opts ...caopts.RepoStatOption) (*coreiface.RepoStat, error) {
+	settings, err := caopts.RepoStatOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.SizeOnly {
+		sizeStat, err := corerepo.RepoSize(ctx, api.node)
+		if err != nil {
+			return nil, err
+		}
+
+		return &coreiface.RepoStat{
+			RepoSize:   sizeStat.RepoSize,
+			StorageMax: sizeStat.StorageMax,
+		}, nil
+	}
+
+	stat, err := corerepo.RepoStat(ctx, api.node)
+	if err != nil {
+		return nil, err
+	}
+
+	return &coreiface.RepoStat{
+		RepoSize:   stat.RepoSize,
+		StorageMax: stat.StorageMax,
+		NumObjects: stat.NumObjects,
+		RepoPath:   stat.RepoPath,
+		Version:    stat.Version,
+	}, nil
+}
+
+// Fsck removes the lockfiles of the repo at `repoPath`. It refuses to touch
+// the repo of this node, or a repo locked by another process.
+func (api *RepoAPI) Fsck(ctx context.Context, repoPath string) error {
+	if r, ok := api.node.Repo.(*fsrepo.FSRepo); ok && filepath.Clean(r.Path()) == filepath.Clean(repoPath) {
+		return errRepoInUse
+	}
+
+	locked, err := fsrepo.LockedByOtherProcess(repoPath)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return errRepoInUse
+	}
+
+	return corerepo.Fsck(repoPath)
+}
+
+// Verify rehashes every block of the repo and sends its status on the
+// returned channel.
+func (api *RepoAPI) Verify(ctx context.Context) (<-chan coreiface.RepoVerifyResult, error) {
+	bs := bstore.NewBlockstore(api.node.Repo.Datastore())
+	bs.HashOnRead(true)
+
+	keys, err := bs.AllKeysChan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan coreiface.RepoVerifyResult)
+	go func() {
+		defer close(out)
+
+		for k := range keys {
+			res := coreiface.RepoVerifyResult{Cid: k}
+			if _, err := bs.Get(k); err != nil {
+				res.Corrupt = err
+			}
+
+			select {
+			case out <- res:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,116 @@
+package coreapi_test
+
+import (
+	"context"
+	"testing"
+
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+	corerepo "github.com/dms3-fs/go-dms3-fs/core/corerepo"
+)
+
+func TestRepoGc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := api.Unixfs().Add(ctx, strFile("gc test")(), options.Unixfs.Pin(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := api.Repo().Gc(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed := false
+	for res := range out {
+		if res.Error != nil {
+			t.Fatal(res.Error)
+		}
+		if res.KeyRemoved.Equals(p.Cid()) {
+			removed = true
+		}
+	}
+
+	if !removed {
+		t.Errorf("expected %s to be removed", p.Cid())
+	}
+}
+
+func TestRepoStat(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = api.Unixfs().Add(ctx, strFile("stat test")())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := api.Repo().Stat(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if st.NumObjects == 0 {
+		t.Error("expected objects in the repo")
+	}
+	if st.StorageMax != corerepo.NoLimit {
+		t.Errorf("unexpected storage max: %d", st.StorageMax)
+	}
+
+	st, err = api.Repo().Stat(ctx, options.Repo.SizeOnly(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if st.NumObjects != 0 || st.Version != "" {
+		t.Errorf("unexpected stat with size only: %+v", st)
+	}
+}
+
+func TestRepoVerify(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := api.Unixfs().Add(ctx, strFile("verify test")())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := api.Repo().Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for res := range out {
+		if res.Err != nil {
+			t.Fatal(res.Err)
+		}
+		if res.Corrupt != nil {
+			t.Errorf("block %s is corrupt: %s", res.Cid, res.Corrupt)
+		}
+		if res.Cid.Equals(p.Cid()) {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("expected %s to be verified", p.Cid())
+	}
+}
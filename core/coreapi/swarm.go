@@ -0,0 +1,283 @@
+package coreapi
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
+
+	iaddr "github.com/dms3-fs/go-fs-addr"
+	ma "github.com/dms3-mft/go-multiaddr"
+	inet "github.com/dms3-p2p/go-p2p-net"
+	peer "github.com/dms3-p2p/go-p2p-peer"
+	pstore "github.com/dms3-p2p/go-p2p-peerstore"
+	protocol "github.com/dms3-p2p/go-p2p-protocol"
+	swarm "github.com/dms3-p2p/go-p2p-swarm"
+	mamask "github.com/whyrusleeping/multiaddr-filter"
+)
+
+type SwarmAPI CoreAPI
+
+type connInfo struct {
+	peerstore pstore.Peerstore
+	conn      inet.Conn
+	dir       inet.Direction
+
+	addr ma.Multiaddr
+	peer peer.ID
+}
+
+// Connect opens a new direct connection to the peer, clearing any dial
+// backoff left from previous failed attempts.
+func (api *SwarmAPI) Connect(ctx context.Context, pi pstore.PeerInfo) error {
+	if api.node.PeerHost == nil {
+		return coreiface.ErrOffline
+	}
+
+	if swrm, ok := api.node.PeerHost.Network().(*swarm.Swarm); ok {
+		swrm.Backoff().Clear(pi.ID)
+	}
+
+	return api.node.PeerHost.Connect(ctx, pi)
+}
+
+// Disconnect closes the connection to the address `addr`. If the address
+// doesn't contain the transport part, all connections to the peer are closed.
+func (api *SwarmAPI) Disconnect(ctx context.Context, addr ma.Multiaddr) error {
+	if api.node.PeerHost == nil {
+		return coreiface.ErrOffline
+	}
+
+	ia, err := iaddr.ParseMultiaddr(addr)
+	if err != nil {
+		return err
+	}
+
+	taddr := ia.Transport()
+	id := ia.ID()
+	net := api.node.PeerHost.Network()
+
+	if taddr == nil {
+		if net.Connectedness(id) != inet.Connected {
+			return coreiface.ErrNotConnected
+		}
+		return net.ClosePeer(id)
+	}
+
+	for _, conn := range net.ConnsToPeer(id) {
+		if !conn.RemoteMultiaddr().Equal(taddr) {
+			continue
+		}
+
+		return conn.Close()
+	}
+
+	return coreiface.ErrConnNotFound
+}
+
+// KnownAddrs returns all addresses in the peerstore, grouped by peer.
+func (api *SwarmAPI) KnownAddrs(context.Context) (map[peer.ID][]ma.Multiaddr, error) {
+	if api.node.PeerHost == nil {
+		return nil, coreiface.ErrOffline
+	}
+
+	addrs := make(map[peer.ID][]ma.Multiaddr)
+	ps := api.node.PeerHost.Network().Peerstore()
+	for _, p := range ps.Peers() {
+		for _, a := range ps.Addrs(p) {
+			addrs[p] = append(addrs[p], a)
+		}
+		sort.Slice(addrs[p], func(i, j int) bool {
+			return addrs[p][i].String() < addrs[p][j].String()
+		})
+	}
+
+	return addrs, nil
+}
+
+// LocalAddrs returns the listening addresses announced to the network.
+func (api *SwarmAPI) LocalAddrs(context.Context) ([]ma.Multiaddr, error) {
+	if api.node.PeerHost == nil {
+		return nil, coreiface.ErrOffline
+	}
+
+	return api.node.PeerHost.Addrs(), nil
+}
+
+// ListenAddrs returns all interface addresses the node is listening on.
+func (api *SwarmAPI) ListenAddrs(context.Context) ([]ma.Multiaddr, error) {
+	if api.node.PeerHost == nil {
+		return nil, coreiface.ErrOffline
+	}
+
+	return api.node.PeerHost.Network().InterfaceListenAddresses()
+}
+
+// Peers returns the list of peers this node has open connections to.
+func (api *SwarmAPI) Peers(context.Context) ([]coreiface.ConnectionInfo, error) {
+	if api.node.PeerHost == nil {
+		return nil, coreiface.ErrOffline
+	}
+
+	conns := api.node.PeerHost.Network().Conns()
+
+	var out []coreiface.ConnectionInfo
+	for _, c := range conns {
+		pid := c.RemotePeer()
+		addr := c.RemoteMultiaddr()
+
+		ci := &connInfo{
+			peerstore: api.node.Peerstore,
+			conn:      c,
+			dir:       c.Stat().Direction,
+
+			addr: addr,
+			peer: pid,
+		}
+
+		out = append(out, ci)
+	}
+
+	return out, nil
+}
+
+// Filters returns the address filters currently applied to the swarm.
+func (api *SwarmAPI) Filters(context.Context) ([]string, error) {
+	swrm, err := api.swarm()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for _, f := range swrm.Filters.Filters() {
+		s, err := mamask.ConvertIPNet(f)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+
+	return out, nil
+}
+
+// FilterAdd adds an address filter to the swarm. With the Persist option the
+// filter is also added to the "Swarm.AddrFilters" config key.
+func (api *SwarmAPI) FilterAdd(ctx context.Context, filter string, opts ...caopts.SwarmFilterOption) error {
+	settings, err := caopts.SwarmFilterOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	swrm, err := api.swarm()
+	if err != nil {
+		return err
+	}
+
+	mask, err := mamask.NewMask(filter)
+	if err != nil {
+		return err
+	}
+
+	swrm.Filters.AddDialFilter(mask)
+
+	if !settings.Persist {
+		return nil
+	}
+
+	cfg, err := api.node.Repo.Config()
+	if err != nil {
+		return err
+	}
+
+	for _, f := range cfg.Swarm.AddrFilters {
+		if f == filter {
+			return nil
+		}
+	}
+
+	cfg.Swarm.AddrFilters = append(cfg.Swarm.AddrFilters, filter)
+	return api.node.Repo.SetConfig(cfg)
+}
+
+// FilterRm removes an address filter from the swarm. With the Persist option
+// the filter is also removed from the "Swarm.AddrFilters" config key.
+func (api *SwarmAPI) FilterRm(ctx context.Context, filter string, opts ...caopts.SwarmFilterOption) error {
+	settings, err := caopts.SwarmFilterOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	swrm, err := api.swarm()
+	if err != nil {
+		return err
+	}
+
+	mask, err := mamask.NewMask(filter)
+	if err != nil {
+		return err
+	}
+
+	swrm.Filters.Remove(mask)
+
+	if !settings.Persist {
+		return nil
+	}
+
+	cfg, err := api.node.Repo.Config()
+	if err != nil {
+		return err
+	}
+
+	keep := make([]string, 0, len(cfg.Swarm.AddrFilters))
+	for _, f := range cfg.Swarm.AddrFilters {
+		if f != filter {
+			keep = append(keep, f)
+		}
+	}
+
+	cfg.Swarm.AddrFilters = keep
+	return api.node.Repo.SetConfig(cfg)
+}
+
+func (api *SwarmAPI) swarm() (*swarm.Swarm, error) {
+	if api.node.PeerHost == nil {
+		return nil, coreiface.ErrOffline
+	}
+
+	swrm, ok := api.node.PeerHost.Network().(*swarm.Swarm)
+	if !ok {
+		return nil, errors.New("failed to cast network to swarm network")
+	}
+
+	return swrm, nil
+}
+
+func (ci *connInfo) ID() peer.ID {
+	return ci.peer
+}
+
+func (ci *connInfo) Address() ma.Multiaddr {
+	return ci.addr
+}
+
+func (ci *connInfo) Direction() inet.Direction {
+	return ci.dir
+}
+
+func (ci *connInfo) Latency() (time.Duration, error) {
+	return ci.peerstore.LatencyEWMA(ci.peer), nil
+}
+
+func (ci *connInfo) Streams() ([]protocol.ID, error) {
+	streams := ci.conn.GetStreams()
+
+	out := make([]protocol.ID, len(streams))
+	for i, s := range streams {
+		out[i] = s.Protocol()
+	}
+
+	return out, nil
+}
@@ -0,0 +1,144 @@
+package coreapi_test
+
+import (
+	"context"
+	"testing"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+
+	ma "github.com/dms3-mft/go-multiaddr"
+	inet "github.com/dms3-p2p/go-p2p-net"
+)
+
+func TestSwarmPeers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nds, apis, err := makeAPISwarm(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peers, err := apis[0].Swarm().Peers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(peers) != 1 {
+		t.Fatalf("unexpected number of peers: %d", len(peers))
+	}
+
+	if peers[0].ID() != nds[1].Identity {
+		t.Errorf("expected peer %s, got %s", nds[1].Identity.Pretty(), peers[0].ID().Pretty())
+	}
+
+	if peers[0].Direction() != inet.DirInbound {
+		t.Errorf("expected inbound connection, got %v", peers[0].Direction())
+	}
+
+	if _, err := peers[0].Latency(); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := peers[0].Streams(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSwarmDisconnectConnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nds, apis, err := makeAPISwarm(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err := ma.NewMultiaddr("/dms3fs/" + nds[1].Identity.Pretty())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = apis[0].Swarm().Disconnect(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peers, err := apis[0].Swarm().Peers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(peers) != 0 {
+		t.Fatalf("expected no peers after disconnect, got %d", len(peers))
+	}
+
+	err = apis[0].Swarm().Disconnect(ctx, addr)
+	if err != coreiface.ErrNotConnected {
+		t.Errorf("expected ErrNotConnected, got %v", err)
+	}
+
+	err = apis[0].Swarm().Connect(ctx, nds[1].Peerstore.PeerInfo(nds[1].Identity))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peers, err = apis[0].Swarm().Peers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(peers) != 1 {
+		t.Fatalf("unexpected number of peers: %d", len(peers))
+	}
+
+	if peers[0].Direction() != inet.DirOutbound {
+		t.Errorf("expected outbound connection, got %v", peers[0].Direction())
+	}
+}
+
+func TestSwarmAddrs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nds, apis, err := makeAPISwarm(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	local, err := apis[0].Swarm().LocalAddrs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(local) == 0 {
+		t.Error("expected node to have local addresses")
+	}
+
+	known, err := apis[0].Swarm().KnownAddrs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(known[nds[1].Identity]) == 0 {
+		t.Errorf("expected to know addresses of peer %s", nds[1].Identity.Pretty())
+	}
+}
+
+func TestSwarmOffline(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = api.Swarm().Peers(ctx)
+	if err != coreiface.ErrOffline {
+		t.Errorf("expected ErrOffline, got %v", err)
+	}
+
+	err = api.Swarm().FilterAdd(ctx, "/ip4/192.168.0.0/ipcidr/16")
+	if err != coreiface.ErrOffline {
+		t.Errorf("expected ErrOffline, got %v", err)
+	}
+}
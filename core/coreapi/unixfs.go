@@ -2,30 +2,167 @@ package coreapi
 
 import (
 	"context"
-	"io"
+	"fmt"
 
+	core "github.com/dms3-fs/go-dms3-fs/core"
 	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	caopts "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
 	coreunix "github.com/dms3-fs/go-dms3-fs/core/coreunix"
+	filestore "github.com/dms3-fs/go-dms3-fs/filestore"
+	dag "github.com/dms3-fs/go-merkledag"
+	dagtest "github.com/dms3-fs/go-merkledag/test"
+	ft "github.com/dms3-fs/go-unixfs"
 	uio "github.com/dms3-fs/go-unixfs/io"
 
-	cid "github.com/dms3-fs/go-cid"
+	blockservice "github.com/dms3-fs/go-blockservice"
+	cidutil "github.com/dms3-fs/go-cidutil"
+	bstore "github.com/dms3-fs/go-fs-blockstore"
+	files "github.com/dms3-fs/go-fs-cmdkit/files"
+	offline "github.com/dms3-fs/go-fs-exchange-offline"
 	dms3ld "github.com/dms3-fs/go-ld-format"
+	mfs "github.com/dms3-fs/go-mfs"
 )
 
+const adderOutChanSize = 8
+
 type UnixfsAPI CoreAPI
 
-// Add builds a merkledag node from a reader, adds it to the blockstore,
-// and returns the key representing that node.
-func (api *UnixfsAPI) Add(ctx context.Context, r io.Reader) (coreiface.ResolvedPath, error) {
-	k, err := coreunix.AddWithContext(ctx, api.node, r)
+// Add builds a merkledag from the file or directory tree `file`, adds it to
+// the blockstore, and returns the path of the root node.
+func (api *UnixfsAPI) Add(ctx context.Context, file files.File, opts ...caopts.UnixfsAddOption) (coreiface.ResolvedPath, error) {
+	settings, prefix, err := caopts.UnixfsAddOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	n := api.node
+
+	cfg, err := n.Repo.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	// nocopy -> filestoreEnabled
+	if settings.NoCopy && !cfg.Experimental.FilestoreEnabled {
+		return nil, filestore.ErrFilestoreNotEnabled
+	}
+
+	if settings.OnlyHash {
+		nilnode, err := core.NewNode(ctx, &core.BuildCfg{
+			//TODO: need this to be true or all files
+			// hashed will be stored in memory!
+			NilRepo: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		n = nilnode
+	}
+
+	addblockstore := n.Blockstore
+	if !(settings.FsCache || settings.NoCopy) {
+		addblockstore = bstore.NewGCBlockstore(n.BaseBlocks, n.GCLocker)
+	}
+
+	exch := n.Exchange
+	if settings.Local {
+		exch = offline.Exchange(addblockstore)
+	}
+
+	bserv := blockservice.New(addblockstore, exch) // hash security 001
+	dserv := dag.NewDAGService(bserv)
+
+	fileAdder, err := coreunix.NewAdder(ctx, n.Pinning, n.Blockstore, dserv)
+	if err != nil {
+		return nil, err
+	}
+
+	fileAdder.Chunker = settings.Chunker
+	fileAdder.Hidden = settings.Hidden
+	fileAdder.Wrap = settings.Wrap
+	fileAdder.Pin = settings.Pin && !settings.OnlyHash
+	fileAdder.Silent = settings.Silent
+	fileAdder.RawLeaves = settings.RawLeaves
+	fileAdder.NoCopy = settings.NoCopy
+	fileAdder.CidBuilder = prefix
+
+	switch settings.Layout {
+	case caopts.BalancedLayout:
+		// Default
+	case caopts.TrickleLayout:
+		fileAdder.Trickle = true
+	default:
+		return nil, fmt.Errorf("unknown layout: %d", settings.Layout)
+	}
+
+	if settings.Inline {
+		fileAdder.CidBuilder = cidutil.InlineBuilder{
+			Builder: fileAdder.CidBuilder,
+			Limit:   settings.InlineLimit,
+		}
+	}
+
+	if settings.OnlyHash {
+		md := dagtest.Mock()
+		emptyDirNode := ft.EmptyDirNode()
+		// Use the same prefix for the "empty" MFS root as for the file adder.
+		emptyDirNode.SetCidBuilder(fileAdder.CidBuilder)
+		mr, err := mfs.NewRoot(ctx, md, emptyDirNode, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		fileAdder.SetMfsRoot(mr)
+	}
+
+	if settings.Events != nil {
+		out := make(chan interface{}, adderOutChanSize)
+		fileAdder.Out = out
+		fileAdder.Progress = settings.Progress
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for o := range out {
+				ao := o.(*coreunix.AddedObject)
+				ev := &coreiface.AddEvent{
+					Name:  ao.Name,
+					Hash:  ao.Hash,
+					Bytes: ao.Bytes,
+					Size:  ao.Size,
+				}
+
+				select {
+				case settings.Events <- ev:
+				case <-ctx.Done():
+				}
+			}
+		}()
+
+		defer func() {
+			close(out)
+			<-done
+		}()
+	}
+
+	err = fileAdder.AddFile(file)
 	if err != nil {
 		return nil, err
 	}
-	c, err := cid.Decode(k)
+
+	nd, err := fileAdder.Finalize()
 	if err != nil {
 		return nil, err
 	}
-	return coreiface.Dms3FsPath(c), nil
+
+	if fileAdder.Pin {
+		err = fileAdder.PinRoot()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return coreiface.Dms3FsPath(nd.Cid()), nil
 }
 
 // Cat returns the data contained by an DMS3FS or DMS3NS object(s) at path `p`.
@@ -49,31 +186,75 @@ func (api *UnixfsAPI) Cat(ctx context.Context, p coreiface.Path) (coreiface.Read
 // Ls returns the contents of an DMS3FS or DMS3NS object(s) at path p, with the format:
 // `<link base58 hash> <link size in bytes> <link name>`
 func (api *UnixfsAPI) Ls(ctx context.Context, p coreiface.Path) ([]*dms3ld.Link, error) {
-	dagnode, err := api.core().ResolveNode(ctx, p)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	links, err := api.LsAsync(ctx, p)
 	if err != nil {
 		return nil, err
 	}
 
-	var ndlinks []*dms3ld.Link
-	dir, err := uio.NewDirectoryFromNode(api.node.DAG, dagnode)
-	switch err {
-	case nil:
-		l, err := dir.Links(ctx)
-		if err != nil {
-			return nil, err
+	out := []*dms3ld.Link{}
+	for r := range links {
+		if r.Err != nil {
+			return nil, r.Err
 		}
-		ndlinks = l
-	case uio.ErrNotADir:
-		ndlinks = dagnode.Links()
-	default:
+		out = append(out, r.Link)
+	}
+
+	// the channel is closed without an error when the context is cancelled
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	links := make([]*dms3ld.Link, len(ndlinks))
-	for i, l := range ndlinks {
-		links[i] = &dms3ld.Link{Name: l.Name, Size: l.Size, Cid: l.Cid}
+	return out, nil
+}
+
+// LsAsync streams the links of the object at path p. Links of sharded
+// directories are sent as the shards are fetched.
+func (api *UnixfsAPI) LsAsync(ctx context.Context, p coreiface.Path) (<-chan coreiface.UnixfsLsResult, error) {
+	dagnode, err := api.core().ResolveNode(ctx, p)
+	if err != nil {
+		return nil, err
 	}
-	return links, nil
+
+	dir, err := uio.NewDirectoryFromNode(api.node.DAG, dagnode)
+	if err != nil && err != uio.ErrNotADir {
+		return nil, err
+	}
+
+	out := make(chan coreiface.UnixfsLsResult)
+	go func() {
+		defer close(out)
+
+		send := func(l *dms3ld.Link) error {
+			select {
+			case out <- coreiface.UnixfsLsResult{Link: &dms3ld.Link{Name: l.Name, Size: l.Size, Cid: l.Cid}}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if dir == nil {
+			for _, l := range dagnode.Links() {
+				if send(l) != nil {
+					return
+				}
+			}
+			return
+		}
+
+		err := dir.ForEachLink(ctx, send)
+		if err != nil && err != ctx.Err() {
+			select {
+			case out <- coreiface.UnixfsLsResult{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return out, nil
 }
 
 func (api *UnixfsAPI) core() coreiface.CoreAPI {
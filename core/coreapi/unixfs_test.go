@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"strings"
 	"testing"
@@ -14,17 +16,23 @@ import (
 	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
 	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
 	coreunix "github.com/dms3-fs/go-dms3-fs/core/coreunix"
+	mock "github.com/dms3-fs/go-dms3-fs/core/mock"
 	keystore "github.com/dms3-fs/go-dms3-fs/keystore"
 	repo "github.com/dms3-fs/go-dms3-fs/repo"
 
+	cid "github.com/dms3-fs/go-cid"
 	datastore "github.com/dms3-fs/go-datastore"
 	syncds "github.com/dms3-fs/go-datastore/sync"
+	files "github.com/dms3-fs/go-fs-cmdkit/files"
 	config "github.com/dms3-fs/go-fs-config"
 	cbor "github.com/dms3-fs/go-ld-cbor"
 	mdag "github.com/dms3-fs/go-merkledag"
 	unixfs "github.com/dms3-fs/go-unixfs"
+	mh "github.com/dms3-mft/go-multihash"
 	ci "github.com/dms3-p2p/go-p2p-crypto"
 	peer "github.com/dms3-p2p/go-p2p-peer"
+	pstore "github.com/dms3-p2p/go-p2p-peerstore"
+	mocknet "github.com/dms3-p2p/go-p2p/p2p/net/mock"
 )
 
 const testPeerID = "QmTFauExutTsy4XP6JbMFcw2Wa9645HJt2bTqL6qYDCKfe"
@@ -83,6 +91,78 @@ func makeAPI(ctx context.Context) (*core.Dms3FsNode, coreiface.CoreAPI, error) {
 	return makeAPIIdent(ctx, false)
 }
 
+// makeAPISwarm creates n online nodes linked together on a mock network and
+// bootstrapped to the first one
+func makeAPISwarm(ctx context.Context, n int) ([]*core.Dms3FsNode, []coreiface.CoreAPI, error) {
+	mn := mocknet.New(ctx)
+
+	nodes := make([]*core.Dms3FsNode, n)
+	apis := make([]coreiface.CoreAPI, n)
+
+	for i := 0; i < n; i++ {
+		sk, pk, err := ci.GenerateKeyPair(ci.RSA, 512)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		id, err := peer.IDFromPublicKey(pk)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		kbytes, err := sk.Bytes()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		c := config.Config{}
+		c.Addresses.Swarm = []string{fmt.Sprintf("/ip4/127.0.%d.1/tcp/4001", i)}
+		c.Identity = config.Identity{
+			PeerID:  id.Pretty(),
+			PrivKey: base64.StdEncoding.EncodeToString(kbytes),
+		}
+
+		r := &repo.Mock{
+			C: c,
+			D: syncds.MutexWrap(datastore.NewMapDatastore()),
+			K: keystore.NewMemKeystore(),
+		}
+
+		node, err := core.NewNode(ctx, &core.BuildCfg{
+			Repo:   r,
+			Host:   mock.MockHostOption(mn),
+			Online: true,
+			ExtraOpts: map[string]bool{
+				"pubsub": true,
+			},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		nodes[i] = node
+		apis[i] = coreapi.NewCoreAPI(node)
+	}
+
+	err := mn.LinkAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bsinf := core.BootstrapConfigWithPeers(
+		[]pstore.PeerInfo{
+			nodes[0].Peerstore.PeerInfo(nodes[0].Identity),
+		},
+	)
+
+	for _, n := range nodes[1:] {
+		if err := n.Bootstrap(bsinf); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return nodes, apis, nil
+}
+
 func TestAdd(t *testing.T) {
 	ctx := context.Background()
 	_, api, err := makeAPI(ctx)
@@ -90,8 +170,7 @@ func TestAdd(t *testing.T) {
 		t.Error(err)
 	}
 
-	str := strings.NewReader(helloStr)
-	p, err := api.Unixfs().Add(ctx, str)
+	p, err := api.Unixfs().Add(ctx, strFile(helloStr)())
 	if err != nil {
 		t.Error(err)
 	}
@@ -122,8 +201,7 @@ func TestAddEmptyFile(t *testing.T) {
 		t.Error(err)
 	}
 
-	str := strings.NewReader("")
-	p, err := api.Unixfs().Add(ctx, str)
+	p, err := api.Unixfs().Add(ctx, strFile("")())
 	if err != nil {
 		t.Error(err)
 	}
@@ -133,6 +211,201 @@ func TestAddEmptyFile(t *testing.T) {
 	}
 }
 
+func TestAddOptions(t *testing.T) {
+	ctx := context.Background()
+	node, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := api.Unixfs().Add(ctx, strFile(helloStr)(), options.Unixfs.CidVersion(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Cid().Prefix().Version != 1 {
+		t.Errorf("expected cid version 1, got %d", p.Cid().Prefix().Version)
+	}
+
+	if p.Cid().Prefix().Codec != cid.Raw {
+		t.Errorf("expected raw leaf with cidv1, got codec %d", p.Cid().Prefix().Codec)
+	}
+
+	p, err = api.Unixfs().Add(ctx, strFile(helloStr)(), options.Unixfs.Hash(mh.SHA3_256))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Cid().Prefix().Version != 1 || p.Cid().Prefix().MhType != mh.SHA3_256 {
+		t.Errorf("expected cidv1 sha3-256, got %s", p.Cid())
+	}
+
+	_, err = api.Unixfs().Add(ctx, strFile(helloStr)(), options.Unixfs.Hash(mh.SHA3_256), options.Unixfs.CidVersion(0))
+	if err == nil {
+		t.Error("expected cidv0 with sha3-256 to fail")
+	}
+
+	p, err = api.Unixfs().Add(ctx, strFile(helloStr)(), options.Unixfs.Inline(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Cid().Prefix().MhType != mh.ID {
+		t.Errorf("expected inlined cid, got %s", p.Cid())
+	}
+
+	p, err = api.Unixfs().Add(ctx, strFile(helloStr)(), options.Unixfs.Chunker("size-4"), options.Unixfs.Layout(options.TrickleLayout))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := api.Unixfs().Cat(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != helloStr {
+		t.Errorf("expected [%s], got [%s]", helloStr, string(data))
+	}
+
+	p, err = api.Unixfs().Add(ctx, strFile("only hash")(), options.Unixfs.HashOnly(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	has, err := node.Blockstore.Has(p.Cid())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if has {
+		t.Error("expected block not to be stored with HashOnly")
+	}
+}
+
+func TestAddPin(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := api.Unixfs().Add(ctx, strFile(helloStr)(), options.Unixfs.Pin(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pins, err := api.Pin().Ls(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pins) != 1 {
+		t.Fatalf("expected 1 pin, got %d", len(pins))
+	}
+
+	if pins[0].Path().Cid().String() != p.Cid().String() {
+		t.Errorf("expected pin %s, got %s", p.Cid(), pins[0].Path().Cid())
+	}
+}
+
+func TestAddDir(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := api.Unixfs().Add(ctx, testDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	links, err := api.Unixfs().Ls(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(links) != 2 || links[0].Name != "bar" || links[1].Name != "foo" {
+		t.Fatalf("expected links [bar foo], got %v", links)
+	}
+
+	p, err = api.Unixfs().Add(ctx, testDir(), options.Unixfs.Hidden(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	links, err = api.Unixfs().Ls(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(links) != 3 || links[0].Name != ".hidden" {
+		t.Fatalf("expected hidden file to be added, got %v", links)
+	}
+
+	p, err = api.Unixfs().Add(ctx, testDir(), options.Unixfs.Wrap(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	links, err = api.Unixfs().Ls(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(links) != 1 || links[0].Name != "t" {
+		t.Fatalf("expected wrapping directory with link t, got %v", links)
+	}
+}
+
+func TestAddEvents(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan interface{}, 16)
+	p, err := api.Unixfs().Add(ctx, testDir(), options.Unixfs.Events(events), options.Unixfs.Progress(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	close(events)
+
+	var added []string
+	var progress int
+	for e := range events {
+		ev, ok := e.(*coreiface.AddEvent)
+		if !ok {
+			t.Fatalf("unexpected event type %T", e)
+		}
+
+		if ev.Hash == "" {
+			progress++
+			continue
+		}
+		added = append(added, ev.Name)
+
+		if ev.Name == "t" && ev.Hash != p.Cid().String() {
+			t.Errorf("expected root hash %s, got %s", p.Cid(), ev.Hash)
+		}
+	}
+
+	if progress == 0 {
+		t.Error("expected progress events")
+	}
+
+	if len(added) != 3 {
+		t.Errorf("expected 3 added events, got %v", added)
+	}
+}
+
 func TestCatBasic(t *testing.T) {
 	ctx := context.Background()
 	node, api, err := makeAPI(ctx)
@@ -307,6 +580,44 @@ func TestLs(t *testing.T) {
 	}
 }
 
+func TestLsAsync(t *testing.T) {
+	ctx := context.Background()
+	node, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	r := strings.NewReader("content-of-file")
+	k, _, err := coreunix.AddWrapped(node, r, "name-of-file")
+	if err != nil {
+		t.Error(err)
+	}
+	p, err := coreiface.ParsePath("/dms3fs/" + strings.Split(k, "/")[0])
+	if err != nil {
+		t.Error(err)
+	}
+
+	res, err := api.Unixfs().LsAsync(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for r := range res {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		names = append(names, r.Link.Name)
+	}
+
+	if len(names) != 1 {
+		t.Fatalf("expected 1 link, got %d", len(names))
+	}
+	if names[0] != "name-of-file" {
+		t.Fatalf("expected name = name-of-file, got %s", names[0])
+	}
+}
+
 func TestLsEmptyDir(t *testing.T) {
 	ctx := context.Background()
 	node, api, err := makeAPI(ctx)
@@ -361,3 +672,17 @@ func TestLsNonUnixfs(t *testing.T) {
 		t.Fatalf("expected 0 links, got %d", len(links))
 	}
 }
+
+func strFile(data string) func() files.File {
+	return func() files.File {
+		return files.NewReaderFile("", "", ioutil.NopCloser(strings.NewReader(data)), nil)
+	}
+}
+
+func testDir() files.File {
+	return files.NewSliceFile("t", "t", []files.File{
+		files.NewReaderFile("t/foo", "t/foo", ioutil.NopCloser(strings.NewReader("foo")), nil),
+		files.NewReaderFile("t/bar", "t/bar", ioutil.NopCloser(strings.NewReader("bar")), nil),
+		files.NewReaderFile("t/.hidden", "t/.hidden", ioutil.NopCloser(strings.NewReader("hidden")), nil),
+	})
+}
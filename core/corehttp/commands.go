@@ -134,11 +134,17 @@ func commandsOption(cctx oldcmds.Context, command *cmds.Command) ServeOption {
 		patchCORSVars(cfg, l.Addr())
 
 		cmdHandler := cmdsHttp.NewHandler(&cctx, command, cfg)
-		mux.Handle(APIPath+"/", cmdHandler)
+		mux.Handle(APIPath+"/", tracedHandler(commandSpanName, cmdHandler))
 		return mux, nil
 	}
 }
 
+// commandSpanName names the span of an API request after its command, as
+// in "api.name.resolve".
+func commandSpanName(r *http.Request) string {
+	return "api" + strings.Replace(strings.TrimSuffix(r.URL.Path[len(APIPath):], "/"), "/", ".", -1)
+}
+
 // CommandsOption constructs a ServerOption for hooking the commands into the
 // HTTP server.
 func CommandsOption(cctx oldcmds.Context) ServeOption {
@@ -16,9 +16,23 @@ type GatewayConfig struct {
 	Headers      map[string][]string
 	Writable     bool
 	PathPrefixes []string
+
+	// ACL restricts the clients allowed to write to a writable gateway. Every
+	// client is allowed when it is nil.
+	ACL *GatewayACL
 }
 
 func GatewayOption(writable bool, paths ...string) ServeOption {
+	return gatewayOption(writable, nil, paths...)
+}
+
+// WritableGatewayOption serves a writable gateway on the given paths, which
+// only the clients allowed by acl may write to.
+func WritableGatewayOption(acl *GatewayACL, paths ...string) ServeOption {
+	return gatewayOption(true, acl, paths...)
+}
+
+func gatewayOption(writable bool, acl *GatewayACL, paths ...string) ServeOption {
 	return func(n *core.Dms3FsNode, _ net.Listener, mux *http.ServeMux) (*http.ServeMux, error) {
 		cfg, err := n.Repo.Config()
 		if err != nil {
@@ -29,6 +43,7 @@ func GatewayOption(writable bool, paths ...string) ServeOption {
 			Headers:      cfg.Gateway.HTTPHeaders,
 			Writable:     writable,
 			PathPrefixes: cfg.Gateway.PathPrefixes,
+			ACL:          acl,
 		}, coreapi.NewCoreAPI(n))
 
 		for _, p := range paths {
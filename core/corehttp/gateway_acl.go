@@ -0,0 +1,156 @@
+package corehttp
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	gopath "path"
+	"strings"
+)
+
+// GatewayACL restricts the clients allowed to write to a writable gateway.
+// A request is allowed when a rule matching its credentials grants its method
+// on its path.
+type GatewayACL struct {
+	Rules []GatewayACLRule
+}
+
+// GatewayACLRule grants the clients presenting its credentials, either a
+// bearer token or HTTP basic credentials, some methods on the paths under its
+// prefixes and on its DMS3NS names.
+type GatewayACLRule struct {
+	Token    string `json:",omitempty"`
+	Username string `json:",omitempty"`
+	Password string `json:",omitempty"`
+
+	// Methods lists the allowed methods, among POST, PUT and DELETE
+	Methods []string
+
+	// Paths lists the allowed path prefixes, e.g. "/dms3fs" for every object
+	Paths []string `json:",omitempty"`
+
+	// Names lists the DMS3NS names which may be written to, e.g. "example.com"
+	// for the paths under /dms3ns/example.com
+	Names []string `json:",omitempty"`
+}
+
+// LoadGatewayACL reads a JSON encoded GatewayACL from the file at path.
+func LoadGatewayACL(path string) (*GatewayACL, error) {
+	acl := new(GatewayACL)
+	if err := loadGatewayFile(path, "gateway ACL", acl); err != nil {
+		return nil, err
+	}
+	return acl, nil
+}
+
+func (acl *GatewayACL) validate() error {
+	for i, rule := range acl.Rules {
+		if rule.Token == "" && rule.Username == "" {
+			return fmt.Errorf("rule %d has no credentials", i)
+		}
+	}
+	return nil
+}
+
+// Check returns the HTTP status with which r is denied, and the reason, or
+// 0 when r is allowed. Requests without matching credentials are denied with
+// 401, the others with 403.
+func (acl *GatewayACL) Check(r *http.Request) (int, string) {
+	p := gopath.Clean(r.URL.Path)
+
+	authenticated := false
+	for _, rule := range acl.Rules {
+		if !rule.authenticates(r) {
+			continue
+		}
+		authenticated = true
+
+		if rule.allows(r.Method, p) {
+			return 0, ""
+		}
+	}
+
+	if !authenticated {
+		return http.StatusUnauthorized, "no valid credentials"
+	}
+	return http.StatusForbidden, fmt.Sprintf("%s not allowed on %s", r.Method, p)
+}
+
+// authenticates reports whether r presents the credentials of the rule
+func (rule *GatewayACLRule) authenticates(r *http.Request) bool {
+	if rule.Token != "" {
+		auth := r.Header.Get("Authorization")
+		if strings.HasPrefix(auth, "Bearer ") && secureEqual(strings.TrimPrefix(auth, "Bearer "), rule.Token) {
+			return true
+		}
+	}
+
+	if rule.Username != "" {
+		user, pass, ok := r.BasicAuth()
+		if ok && secureEqual(user, rule.Username) && secureEqual(pass, rule.Password) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allows reports whether the rule grants method on the path p
+func (rule *GatewayACLRule) allows(method string, p string) bool {
+	allowed := false
+	for _, m := range rule.Methods {
+		if strings.EqualFold(m, method) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return false
+	}
+
+	for _, prefix := range rule.Paths {
+		if hasPathPrefix(p, prefix) {
+			return true
+		}
+	}
+
+	for _, name := range rule.Names {
+		if hasPathPrefix(p, dms3nsPathPrefix+name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasPathPrefix reports whether p is prefix or a path below it
+func hasPathPrefix(p string, prefix string) bool {
+	prefix = gopath.Clean("/" + prefix)
+	return p == prefix || strings.HasPrefix(p, strings.TrimSuffix(prefix, "/")+"/")
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// checkACL reports whether the write request r is allowed by the ACL of the
+// gateway, writing the error response and logging the denial if not
+func (i *gatewayHandler) checkACL(w http.ResponseWriter, r *http.Request) bool {
+	if i.config.ACL == nil {
+		return true
+	}
+
+	code, reason := i.config.ACL.Check(r)
+	if code == 0 {
+		return true
+	}
+
+	log.Warningf("gateway: denied %s %s from %s: %s", r.Method, r.URL.Path, r.RemoteAddr, reason)
+
+	if code == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Basic realm="dms3fs gateway"`)
+	}
+	webErrorWithCode(w, "WritableGateway: access denied", errors.New(reason), code)
+	return false
+}
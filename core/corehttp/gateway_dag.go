@@ -0,0 +1,203 @@
+package corehttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	gopath "path"
+	"sort"
+	"strings"
+	"time"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+
+	cid "github.com/dms3-fs/go-cid"
+	dms3ld "github.com/dms3-fs/go-ld-format"
+)
+
+// serveDagNode renders the node at the resolved path, or the field of it
+// named by the remainder of the path, as JSON. Browsers get an HTML page in
+// which links to other nodes and the fields of maps can be followed.
+func (i *gatewayHandler) serveDagNode(ctx context.Context, w http.ResponseWriter, r *http.Request, urlPath string, originalUrlPath string, prefix string, rp coreiface.ResolvedPath) {
+	nd, err := i.api.ResolveNode(ctx, rp)
+	if err != nil {
+		webError(w, "dms3fs dag get "+rp.Cid().String(), err, http.StatusNotFound)
+		return
+	}
+
+	var fields []string
+	if rp.Remainder() != "" {
+		fields = strings.Split(rp.Remainder(), "/")
+	}
+
+	v, err := dagNodeValue(nd, fields)
+	if err != nil {
+		webError(w, "dms3fs dag get "+r.URL.EscapedPath(), err, http.StatusNotFound)
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		internalWebError(w, err)
+		return
+	}
+
+	asHTML := strings.Contains(r.Header.Get("Accept"), "text/html")
+
+	// the JSON depends on the path within the node, the HTML on the whole
+	// requested path through its links
+	etag := generatedEtag("DagJSON", rp.Cid(), rp.Remainder())
+	if asHTML {
+		etag = generatedEtag("DagHTML", rp.Cid(), originalUrlPath)
+	}
+
+	i.addUserHeaders(w) // ok, _now_ write user's headers.
+	w.Header().Set("X-DMS3FS-Path", urlPath)
+	w.Header().Set("Etag", etag)
+
+	var modtime time.Time
+	if strings.HasPrefix(urlPath, dms3fsPathPrefix) {
+		modtime = time.Unix(1, 0)
+	}
+
+	if !asHTML {
+		w.Header().Set("Content-Type", "application/json")
+		http.ServeContent(w, r, "", modtime, bytes.NewReader(data))
+		return
+	}
+
+	// re-decode the value, so that links are rendered the same way whatever
+	// the node format
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		internalWebError(w, err)
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	title := html.EscapeString(originalUrlPath)
+	fmt.Fprintf(buf, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n<pre>", title, title)
+	writeHTMLValue(buf, tree, originalUrlPath, prefix, "")
+	buf.WriteString("</pre>\n</body>\n</html>\n")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	http.ServeContent(w, r, "", modtime, bytes.NewReader(buf.Bytes()))
+}
+
+// dagNodeValue returns the value of the field of nd at the given path, or the
+// whole node when the path is empty
+func dagNodeValue(nd dms3ld.Node, fields []string) (interface{}, error) {
+	if len(fields) > 0 {
+		v, rest, err := nd.Resolve(fields)
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) > 0 {
+			return nil, fmt.Errorf("no field named %q", strings.Join(rest, "/"))
+		}
+		return v, nil
+	}
+
+	// CBOR nodes resolve the empty path to their whole object. Other formats,
+	// such as git, don't, so the node is put together from its top level
+	// fields.
+	if nd.Cid().Type() == cid.DagCBOR {
+		v, _, err := nd.Resolve(nil)
+		return v, err
+	}
+
+	out := make(map[string]interface{})
+	for _, f := range nd.Tree("", 1) {
+		v, _, err := nd.Resolve([]string{f})
+		if err != nil {
+			return nil, err
+		}
+		out[f] = v
+	}
+	return out, nil
+}
+
+// writeHTMLValue writes the decoded JSON value v as indented JSON, with the
+// links to other nodes pointing to their gateway path, and the keys of maps
+// to the path of their field below p
+func writeHTMLValue(buf *bytes.Buffer, v interface{}, p string, prefix string, indent string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if c, ok := linkValue(v); ok {
+			fmt.Fprintf(buf, "{\"/\": <a href=\"%s\">\"%s\"</a>}", htmlHref(prefix+dms3fsPathPrefix+c.String()), c.String())
+			return
+		}
+
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		buf.WriteString("{\n")
+		for n, k := range keys {
+			key, _ := json.Marshal(k)
+			fp := gopath.Join(p, k)
+			fmt.Fprintf(buf, "%s  <a href=\"%s\">%s</a>: ", indent, htmlHref(fp), html.EscapeString(string(key)))
+			writeHTMLValue(buf, v[k], fp, prefix, indent+"  ")
+			if n < len(keys)-1 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "}")
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+
+		buf.WriteString("[\n")
+		for n, e := range v {
+			buf.WriteString(indent + "  ")
+			writeHTMLValue(buf, e, gopath.Join(p, fmt.Sprint(n)), prefix, indent+"  ")
+			if n < len(v)-1 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "]")
+	default:
+		data, _ := json.Marshal(v)
+		buf.WriteString(html.EscapeString(string(data)))
+	}
+}
+
+// linkValue returns the cid of a link encoded as JSON, {"/": "<cid>"}
+func linkValue(v map[string]interface{}) (*cid.Cid, bool) {
+	if len(v) != 1 {
+		return nil, false
+	}
+
+	s, ok := v["/"].(string)
+	if !ok {
+		return nil, false
+	}
+
+	c, err := cid.Decode(s)
+	if err != nil {
+		return nil, false
+	}
+	return c, true
+}
+
+// htmlHref escapes the path p, including '#' and '?', for use in an href
+// attribute
+func htmlHref(p string) string {
+	u := url.URL{Path: p}
+	return html.EscapeString(u.String())
+}
@@ -0,0 +1,42 @@
+package corehttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// The gateway settings which do not fit in the Gateway section of the config
+// are loaded from JSON files in the repo root, see docs/gateway.md. The
+// gateway runs without them when the files do not exist.
+const (
+	// GatewayACLFile holds the GatewayACL of the writable gateway
+	GatewayACLFile = "gateway-acl.json"
+
+	// GatewayRateLimitFile holds the RateLimitConfig of the gateway
+	GatewayRateLimitFile = "gateway-ratelimit.json"
+)
+
+// gatewayFile is a gateway setting loaded from a JSON file
+type gatewayFile interface {
+	// validate returns an error when the decoded setting is invalid
+	validate() error
+}
+
+// loadGatewayFile decodes the JSON file at path into v, and validates it. The
+// error satisfies os.IsNotExist when the file does not exist.
+func loadGatewayFile(path string, what string, v gatewayFile) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("invalid %s %s: %s", what, path, err)
+	}
+	if err := v.validate(); err != nil {
+		return fmt.Errorf("invalid %s %s: %s", what, path, err)
+	}
+	return nil
+}
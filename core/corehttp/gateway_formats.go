@@ -0,0 +1,150 @@
+package corehttp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	"github.com/dms3-fs/go-dms3-fs/dagutils"
+
+	cid "github.com/dms3-fs/go-cid"
+)
+
+// response formats other than the default UnixFS rendering, selected with
+// the format query parameter or the Accept header. The json format only
+// changes the rendering of directory listings.
+const (
+	formatRaw  = "raw"
+	formatCar  = "car"
+	formatJSON = "json"
+
+	rawContentType  = "application/vnd.dms3ld.raw"
+	carContentType  = "application/vnd.dms3ld.car"
+	jsonContentType = "application/json"
+)
+
+// responseFormat returns the format requested by r, or "" for the default
+// rendering
+func responseFormat(r *http.Request) (string, error) {
+	if f := r.URL.Query().Get("format"); f != "" {
+		switch f {
+		case formatRaw, formatCar, formatJSON:
+			return f, nil
+		default:
+			return "", fmt.Errorf("unsupported format %q", f)
+		}
+	}
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+
+		switch mt {
+		case rawContentType:
+			return formatRaw, nil
+		case carContentType:
+			return formatCar, nil
+		case jsonContentType:
+			return formatJSON, nil
+		}
+	}
+
+	return "", nil
+}
+
+// setFormatHeaders sets the headers common to the responses of the raw and
+// car formats
+func (i *gatewayHandler) setFormatHeaders(w http.ResponseWriter, urlPath string, contentType string, filename string) {
+	i.addUserHeaders(w) // ok, _now_ write user's headers.
+	w.Header().Set("X-DMS3FS-Path", urlPath)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if strings.HasPrefix(urlPath, dms3fsPathPrefix) {
+		w.Header().Set("Cache-Control", "public, max-age=29030400, immutable")
+	}
+}
+
+// serveRawBlock writes the exact bytes of the block at the resolved path
+func (i *gatewayHandler) serveRawBlock(ctx context.Context, w http.ResponseWriter, r *http.Request, urlPath string, rp coreiface.ResolvedPath) {
+	br, err := i.api.Block().Get(ctx, rp)
+	if err != nil {
+		webError(w, "dms3fs block get "+rp.Cid().String(), err, http.StatusNotFound)
+		return
+	}
+
+	data, err := ioutil.ReadAll(br)
+	if err != nil {
+		internalWebError(w, err)
+		return
+	}
+
+	i.setFormatHeaders(w, urlPath, rawContentType, rp.Cid().String()+".bin")
+	w.Header().Set("Etag", "\""+rp.Cid().String()+".raw\"")
+
+	var modtime time.Time
+	if strings.HasPrefix(urlPath, dms3fsPathPrefix) {
+		modtime = time.Unix(1, 0)
+	}
+
+	// the content type is set, so ServeContent won't sniff it
+	http.ServeContent(w, r, "", modtime, bytes.NewReader(data))
+}
+
+// serveCar streams the DAG under the resolved path as a CAR archive
+func (i *gatewayHandler) serveCar(ctx context.Context, w http.ResponseWriter, r *http.Request, urlPath string, rp coreiface.ResolvedPath) {
+	// the traversal order is deterministic, so is the archive
+	etag := "\"" + rp.Cid().String() + ".car\""
+
+	i.setFormatHeaders(w, urlPath, carContentType+"; version=1", rp.Cid().String()+".car")
+	w.Header().Set("Etag", etag)
+
+	if checkNotModified(w, r, etag, time.Time{}) {
+		return
+	}
+
+	if r.Method == "HEAD" {
+		return
+	}
+
+	// The archive is buffered, and the status is only sent once the buffer is
+	// first flushed: errors met before can still be returned, errors met
+	// after it can only abort the response.
+	sw := &sentWriter{Writer: w}
+	err := dagutils.WriteCar(ctx, i.node.DAG, []*cid.Cid{rp.Cid()}, sw)
+	if err == nil {
+		return
+	}
+	if !sw.sent {
+		for _, h := range []string{"Content-Disposition", "Cache-Control", "Etag"} {
+			w.Header().Del(h)
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		webError(w, "dms3fs car export "+rp.Cid().String(), err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Errorf("car export of %s failed: %s", rp.Cid(), err)
+	panic(http.ErrAbortHandler)
+}
+
+// sentWriter records whether anything was written to the writer it wraps
+type sentWriter struct {
+	io.Writer
+	sent bool
+}
+
+func (w *sentWriter) Write(p []byte) (int, error) {
+	w.sent = true
+	return w.Writer.Write(p)
+}
@@ -4,18 +4,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
 	gopath "path"
 	"runtime/debug"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	version "github.com/dms3-fs/go-dms3-fs"
 	core "github.com/dms3-fs/go-dms3-fs/core"
 	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+	options "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface/options"
 	"github.com/dms3-fs/go-dms3-fs/dagutils"
+	namesys "github.com/dms3-fs/go-dms3-fs/namesys"
+	nsopts "github.com/dms3-fs/go-dms3-fs/namesys/opts"
 	dag "github.com/dms3-fs/go-merkledag"
 	path "github.com/dms3-fs/go-path"
 	resolver "github.com/dms3-fs/go-path/resolver"
@@ -24,11 +31,14 @@ import (
 	uio "github.com/dms3-fs/go-unixfs/io"
 
 	humanize "github.com/dustin/go-humanize"
+	isd "github.com/jbenet/go-is-domain"
 	cid "github.com/dms3-fs/go-cid"
 	chunker "github.com/dms3-fs/go-fs-chunker"
+	files "github.com/dms3-fs/go-fs-cmdkit/files"
 	dms3ld "github.com/dms3-fs/go-ld-format"
 	routing "github.com/dms3-p2p/go-p2p-routing"
 	multibase "github.com/dms3-mft/go-multibase"
+	opentracing "github.com/opentracing/opentracing-go"
 )
 
 const (
@@ -42,6 +52,8 @@ type gatewayHandler struct {
 	node   *core.Dms3FsNode
 	config GatewayConfig
 	api    coreiface.CoreAPI
+
+	nameLocks nameLocks
 }
 
 func newGatewayHandler(n *core.Dms3FsNode, c GatewayConfig, api coreiface.CoreAPI) *gatewayHandler {
@@ -64,7 +76,16 @@ func (i *gatewayHandler) newDagFromReader(r io.Reader) (dms3ld.Node, error) {
 
 // TODO(btc): break this apart into separate handlers using a more expressive muxer
 func (i *gatewayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(i.node.Context(), time.Hour)
+	sr := &statusRecorder{ResponseWriter: w}
+	w = sr
+
+	span := startRequestSpan(r, "gateway."+gatewayHandlerName(r.Method))
+	defer func() {
+		gatewayResponses.WithLabelValues(gatewayHandlerName(r.Method), strconv.Itoa(sr.status())).Inc()
+		finishRequestSpan(span, sr.status())
+	}()
+
+	ctx, cancel := context.WithTimeout(opentracing.ContextWithSpan(i.node.Context(), span), time.Hour)
 	// the hour is a hard fallback, we don't expect it to happen, but just in case
 	defer cancel()
 
@@ -81,6 +102,11 @@ func (i *gatewayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	defer func() {
 		if r := recover(); r != nil {
+			if r == http.ErrAbortHandler {
+				// let the server abort the response
+				panic(r)
+			}
+
 			log.Error("A panic occurred in the gateway handler!")
 			log.Error(r)
 			debug.PrintStack()
@@ -88,15 +114,22 @@ func (i *gatewayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	if i.config.Writable {
+		switch r.Method {
+		case "POST", "PUT", "DELETE":
+			if !i.checkACL(w, r) {
+				return
+			}
+		}
+
 		switch r.Method {
 		case "POST":
 			i.postHandler(ctx, w, r)
 			return
 		case "PUT":
-			i.putHandler(w, r)
+			i.putHandler(ctx, w, r)
 			return
 		case "DELETE":
-			i.deleteHandler(w, r)
+			i.deleteHandler(ctx, w, r)
 			return
 		}
 	}
@@ -172,10 +205,38 @@ func (i *gatewayHandler) getOrHeadHandler(ctx context.Context, w http.ResponseWr
 		webError(w, "dms3fs resolve -r "+escapedURLPath, err, http.StatusServiceUnavailable)
 		return
 	} else if err != nil {
+		if isNotFound(err) && i.serveNotFound(ctx, w, r, urlPath, originalUrlPath) {
+			return
+		}
 		webError(w, "dms3fs resolve -r "+escapedURLPath, err, http.StatusNotFound)
 		return
 	}
 
+	// the same path has other representations, selected by the Accept header
+	w.Header().Add("Vary", "Accept")
+
+	format, err := responseFormat(r)
+	if err != nil {
+		webError(w, "invalid format", err, http.StatusBadRequest)
+		return
+	}
+
+	switch format {
+	case formatRaw:
+		i.serveRawBlock(ctx, w, r, urlPath, resolvedPath)
+		return
+	case formatCar:
+		i.serveCar(ctx, w, r, urlPath, resolvedPath)
+		return
+	}
+
+	// nodes other than UnixFS ones, such as CBOR or git objects, are
+	// rendered as JSON
+	if t := resolvedPath.Cid().Type(); t != cid.DagProtobuf && t != cid.Raw {
+		i.serveDagNode(ctx, w, r, urlPath, originalUrlPath, prefix, resolvedPath)
+		return
+	}
+
 	dr, err := i.api.Unixfs().Cat(ctx, resolvedPath)
 	dir := false
 	switch err {
@@ -189,12 +250,9 @@ func (i *gatewayHandler) getOrHeadHandler(ctx context.Context, w http.ResponseWr
 		return
 	}
 
-	// Check etag send back to us
+	// The etag of the content. Conditional requests for files are checked by
+	// http.ServeContent, directory listings get their own etag below.
 	etag := "\"" + resolvedPath.Cid().String() + "\""
-	if r.Header.Get("If-None-Match") == etag || r.Header.Get("If-None-Match") == "W/"+etag {
-		w.WriteHeader(http.StatusNotModified)
-		return
-	}
 
 	i.addUserHeaders(w) // ok, _now_ write user's headers.
 	w.Header().Set("X-DMS3FS-Path", urlPath)
@@ -250,13 +308,19 @@ func (i *gatewayHandler) getOrHeadHandler(ctx context.Context, w http.ResponseWr
 	// and dont want the client to cache a 500 response...
 	// and only if it's /dms3fs!
 	// TODO: break this out when we split /dms3fs /dms3ns routes.
-	modtime := time.Now()
-
-	if strings.HasPrefix(urlPath, dms3fsPathPrefix) && !dir {
-		w.Header().Set("Cache-Control", "public, max-age=29030400, immutable")
+	//
+	// The content of a /dms3ns path may change at any time, so it has no
+	// modtime and If-Modified-Since is left to the etag.
+	var modtime time.Time
 
-		// set modtime to a really long time ago, since files are immutable and should stay cached
+	if strings.HasPrefix(urlPath, dms3fsPathPrefix) {
+		// set modtime to a really long time ago, since the content is
+		// immutable and should stay cached
 		modtime = time.Unix(1, 0)
+
+		if !dir {
+			w.Header().Set("Cache-Control", "public, max-age=29030400, immutable")
+		}
 	}
 
 	if !dir {
@@ -284,6 +348,11 @@ func (i *gatewayHandler) getOrHeadHandler(ctx context.Context, w http.ResponseWr
 		return
 	}
 
+	if format == formatJSON {
+		i.serveDirListingJSON(ctx, w, r, originalUrlPath, resolvedPath.Cid(), dirr)
+		return
+	}
+
 	ixnd, err := dirr.Find(ctx, "index.html")
 	switch {
 	case err == nil:
@@ -303,7 +372,7 @@ func (i *gatewayHandler) getOrHeadHandler(ctx context.Context, w http.ResponseWr
 		defer dr.Close()
 
 		// write to request
-		http.ServeContent(w, r, "index.html", modtime, dr)
+		i.serveFile(w, r, "index.html", modtime, dr)
 		return
 	default:
 		internalWebError(w, err)
@@ -311,18 +380,41 @@ func (i *gatewayHandler) getOrHeadHandler(ctx context.Context, w http.ResponseWr
 	case os.IsNotExist(err):
 	}
 
+	offset, limit, err := listingRange(r)
+	if err != nil {
+		webError(w, "invalid listing range", err, http.StatusBadRequest)
+		return
+	}
+
+	// The listing depends on the requested path and page and the gateway
+	// version as well as the directory, and changes with them. Its modtime
+	// is unknown.
+	etag = dirListingEtag(resolvedPath.Cid(), listingPageURL(r, originalUrlPath, offset, limit))
+	w.Header().Set("Etag", etag)
+	if checkNotModified(w, r, etag, time.Time{}) {
+		return
+	}
+
 	if r.Method == "HEAD" {
 		return
 	}
 
+	// only the links of the requested page are enumerated, large
+	// directories are listed over several pages
+	links, more, err := listingPage(ctx, dirr, offset, limit)
+	if err != nil {
+		internalWebError(w, err)
+		return
+	}
+	prevPage, nextPage := setListingLinks(w, r, originalUrlPath, offset, limit, more)
+
 	// storage for directory listing
 	var dirListing []directoryItem
-	dirr.ForEachLink(ctx, func(link *dms3ld.Link) error {
+	for _, link := range links {
 		// See comment above where originalUrlPath is declared.
 		di := directoryItem{humanize.Bytes(link.Size), link.Name, gopath.Join(originalUrlPath, link.Name)}
 		dirListing = append(dirListing, di)
-		return nil
-	})
+	}
 
 	// construct the correct back link
 	// https://github.com/dms3-fs/go-dms3-fs/issues/1365
@@ -361,6 +453,8 @@ func (i *gatewayHandler) getOrHeadHandler(ctx context.Context, w http.ResponseWr
 		Listing:  dirListing,
 		Path:     originalUrlPath,
 		BackLink: backLink,
+		Prev:     prevPage,
+		Next:     nextPage,
 	}
 	err = listingTemplate.Execute(w, tplData)
 	if err != nil {
@@ -397,8 +491,73 @@ func (i *gatewayHandler) serveFile(w http.ResponseWriter, req *http.Request, nam
 	http.ServeContent(w, req, name, modtime, content)
 }
 
+// dirListingEtag returns the strong etag of the listing of directory c
+// served at urlPath
+func dirListingEtag(c *cid.Cid, urlPath string) string {
+	return generatedEtag("DirIndex", c, urlPath)
+}
+
+// generatedEtag returns the strong etag of a page of the given kind which the
+// gateway generates for node c, served at urlPath
+func generatedEtag(kind string, c *cid.Cid, urlPath string) string {
+	h := fnv.New64a()
+	io.WriteString(h, version.CurrentVersionNumber)
+	io.WriteString(h, version.CurrentCommit)
+	io.WriteString(h, urlPath)
+
+	return fmt.Sprintf("\"%s-%x_CID-%s\"", kind, h.Sum64(), c.String())
+}
+
+// etagMatch reports whether etag is in the list of etags of an If-None-Match
+// header, using the weak comparison of RFC 7232
+func etagMatch(header string, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, t := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(t), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNotModified writes a 304 response and returns true if the conditional
+// headers of a GET or HEAD request show that the client has the current
+// representation. If-Modified-Since is only checked when If-None-Match is not
+// set and modtime is known, as with http.ServeContent.
+func checkNotModified(w http.ResponseWriter, r *http.Request, etag string, modtime time.Time) bool {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if !etagMatch(inm, etag) {
+			return false
+		}
+	} else {
+		ims := r.Header.Get("If-Modified-Since")
+		if ims == "" || modtime.IsZero() {
+			return false
+		}
+
+		t, err := http.ParseTime(ims)
+		if err != nil || modtime.Truncate(time.Second).After(t) {
+			return false
+		}
+	}
+
+	h := w.Header()
+	delete(h, "Content-Type")
+	delete(h, "Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
 func (i *gatewayHandler) postHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
-	p, err := i.api.Unixfs().Add(ctx, r.Body)
+	p, err := i.api.Unixfs().Add(ctx, files.NewReaderFile("", "", r.Body, nil))
 	if err != nil {
 		internalWebError(w, err)
 		return
@@ -409,11 +568,7 @@ func (i *gatewayHandler) postHandler(ctx context.Context, w http.ResponseWriter,
 	http.Redirect(w, r, p.String(), http.StatusCreated)
 }
 
-func (i *gatewayHandler) putHandler(w http.ResponseWriter, r *http.Request) {
-	// TODO(cryptix): move me to ServeHTTP and pass into all handlers
-	ctx, cancel := context.WithCancel(i.node.Context())
-	defer cancel()
-
+func (i *gatewayHandler) putHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 	rootPath, err := path.ParsePath(r.URL.Path)
 	if err != nil {
 		webError(w, "putHandler: DMS3FS path not valid", err, http.StatusBadRequest)
@@ -421,21 +576,12 @@ func (i *gatewayHandler) putHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rsegs := rootPath.Segments()
-	if rsegs[0] == dms3nsPathPrefix {
-		webError(w, "putHandler: updating named entries not supported", errors.New("WritableGateway: dms3ns put not supported"), http.StatusBadRequest)
-		return
-	}
 
-	var newnode dms3ld.Node
-	if rsegs[len(rsegs)-1] == "QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn" {
-		newnode = ft.EmptyDirNode()
-	} else {
-		putNode, err := i.newDagFromReader(r.Body)
-		if err != nil {
-			webError(w, "putHandler: Could not create DAG from request", err, http.StatusInternalServerError)
-			return
+	newNode := func() (dms3ld.Node, error) {
+		if rsegs[len(rsegs)-1] == "QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn" {
+			return ft.EmptyDirNode(), nil
 		}
-		newnode = putNode
+		return i.newDagFromReader(r.Body)
 	}
 
 	var newPath string
@@ -443,6 +589,39 @@ func (i *gatewayHandler) putHandler(w http.ResponseWriter, r *http.Request) {
 		newPath = path.Join(rsegs[2:])
 	}
 
+	if rsegs[0] == "dms3ns" {
+		// the body is only imported once the name is known to belong to a
+		// key of this node
+		i.editNamedHandler(ctx, w, r, rsegs[1], newPath, func(root *dag.ProtoNode) (*dag.ProtoNode, error) {
+			newnode, err := newNode()
+			if err != nil {
+				return nil, err
+			}
+
+			if newPath == "" {
+				// replace the whole tree the name points to
+				pbnewnode, ok := newnode.(*dag.ProtoNode)
+				if !ok {
+					return nil, dag.ErrNotProtobuf
+				}
+				return pbnewnode, i.node.DAG.Add(ctx, pbnewnode)
+			}
+
+			e := dagutils.NewDagEditor(root, i.node.DAG)
+			if err := e.InsertNodeAtPath(ctx, newPath, newnode, ft.EmptyDirNode); err != nil {
+				return nil, err
+			}
+			return e.Finalize(ctx, i.node.DAG)
+		})
+		return
+	}
+
+	newnode, err := newNode()
+	if err != nil {
+		webError(w, "putHandler: Could not create DAG from request", err, http.StatusInternalServerError)
+		return
+	}
+
 	var newcid *cid.Cid
 	rnode, err := core.Resolve(ctx, i.node.Namesys, i.node.Resolver, rootPath)
 	switch ev := err.(type) {
@@ -516,10 +695,8 @@ func (i *gatewayHandler) putHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, gopath.Join(dms3fsPathPrefix, newcid.String(), newPath), http.StatusCreated)
 }
 
-func (i *gatewayHandler) deleteHandler(w http.ResponseWriter, r *http.Request) {
+func (i *gatewayHandler) deleteHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 	urlPath := r.URL.Path
-	ctx, cancel := context.WithCancel(i.node.Context())
-	defer cancel()
 
 	p, err := path.ParsePath(urlPath)
 	if err != nil {
@@ -527,6 +704,22 @@ func (i *gatewayHandler) deleteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if segs := p.Segments(); segs[0] == "dms3ns" {
+		if len(segs) < 3 {
+			webError(w, "Could not delete link", errors.New("WritableGateway: cannot delete the root of a name"), http.StatusBadRequest)
+			return
+		}
+		rmPath := path.Join(segs[2:])
+		i.editNamedHandler(ctx, w, r, segs[1], gopath.Dir(rmPath), func(root *dag.ProtoNode) (*dag.ProtoNode, error) {
+			e := dagutils.NewDagEditor(root, i.node.DAG)
+			if err := e.RmLink(ctx, rmPath); err != nil {
+				return nil, err
+			}
+			return e.Finalize(ctx, i.node.DAG)
+		})
+		return
+	}
+
 	c, components, err := path.SplitAbsPath(p)
 	if err != nil {
 		webError(w, "Could not split path", err, http.StatusInternalServerError)
@@ -593,6 +786,175 @@ func (i *gatewayHandler) deleteHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, gopath.Join(dms3fsPathPrefix+ncid.String(), path.Join(components[:len(components)-1])), http.StatusCreated)
 }
 
+// editNamedHandler applies edit to the current root of the DMS3NS name and
+// republishes the result with the keystore key the name belongs to. Only the
+// names of the keys of this node can be edited: the ID or the name of a key,
+// or a DNSLink name pointing to the ID of a key.
+func (i *gatewayHandler) editNamedHandler(ctx context.Context, w http.ResponseWriter, r *http.Request, name string, redirectPath string, edit func(root *dag.ProtoNode) (*dag.ProtoNode, error)) {
+	key, redirectName, err := i.nameKey(ctx, name)
+	if err != nil {
+		webError(w, "WritableGateway: could not resolve "+name, err, http.StatusInternalServerError)
+		return
+	}
+	if key == nil {
+		webErrorWithCode(w, "WritableGateway: cannot publish "+name, errors.New("name does not belong to a key of this node"), http.StatusForbidden)
+		return
+	}
+
+	// the edits of a name are serialized, so that none is lost
+	unlock := i.nameLocks.lock(key.ID().Pretty())
+	defer unlock()
+
+	root, err := i.namedRoot(ctx, key.ID().Pretty())
+	if err != nil {
+		webError(w, "WritableGateway: could not resolve "+name, err, http.StatusInternalServerError)
+		return
+	}
+
+	nnode, err := edit(root)
+	if err != nil {
+		// only the errors of the edited path are the client's
+		code := http.StatusInternalServerError
+		if err == dag.ErrLinkNotFound || err == dag.ErrNotProtobuf {
+			code = http.StatusBadRequest
+		}
+		webError(w, "WritableGateway: could not edit "+name, err, code)
+		return
+	}
+
+	entry, err := i.api.Name().Publish(ctx, coreiface.Dms3FsPath(nnode.Cid()), options.Name.Key(key.Name()))
+	if err != nil {
+		webError(w, "WritableGateway: could not publish "+name, err, http.StatusInternalServerError)
+		return
+	}
+	if redirectName == "" {
+		redirectName = entry.Name()
+	}
+
+	i.addUserHeaders(w) // ok, _now_ write user's headers.
+	w.Header().Set("DMS3FS-Hash", nnode.Cid().String())
+	w.Header().Set("DMS3NS-Name", entry.Name())
+	http.Redirect(w, r, gopath.Join(dms3nsPathPrefix, redirectName, redirectPath), http.StatusCreated)
+}
+
+// nameKey returns the key of this node the DMS3NS name is published with, or
+// nil if there is none. For a DNSLink name, it also returns the name, which
+// the edited content is redirected to.
+func (i *gatewayHandler) nameKey(ctx context.Context, name string) (coreiface.Key, string, error) {
+	keys, err := i.api.Key().List(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	id, redirectName := name, ""
+	for _, k := range keys {
+		if k.Name() == name {
+			return k, "", nil
+		}
+	}
+
+	if isd.IsDomain(name) {
+		if i.node.Namesys == nil {
+			return nil, "", coreiface.ErrOffline
+		}
+
+		// only follow the DNSLink, not the name it points to
+		p, err := i.node.Namesys.Resolve(ctx, dms3nsPathPrefix+name, nsopts.Depth(1))
+		switch err {
+		case nil, namesys.ErrResolveRecursion:
+		case namesys.ErrResolveFailed:
+			return nil, "", nil
+		default:
+			return nil, "", err
+		}
+
+		segs := p.Segments()
+		if len(segs) != 2 || segs[0] != "dms3ns" {
+			return nil, "", nil
+		}
+		id, redirectName = segs[1], name
+	}
+
+	for _, k := range keys {
+		if k.ID().Pretty() == id {
+			return k, redirectName, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// namedRoot returns the node the DMS3NS name of the given key ID currently
+// points to, or a new empty directory if it has not been published yet.
+func (i *gatewayHandler) namedRoot(ctx context.Context, id string) (*dag.ProtoNode, error) {
+	if i.node.Namesys == nil {
+		return nil, coreiface.ErrOffline
+	}
+
+	// the name is resolved with the name system directly, which returns
+	// ErrResolveFailed as is
+	p, err := i.node.Namesys.Resolve(ctx, dms3nsPathPrefix+id)
+	switch err {
+	case nil:
+	case namesys.ErrResolveFailed:
+		return ft.EmptyDirNode(), nil
+	default:
+		return nil, err
+	}
+
+	ip, err := coreiface.ParsePath(p.String())
+	if err != nil {
+		return nil, err
+	}
+	nd, err := i.api.ResolveNode(ctx, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	pbnd, ok := nd.(*dag.ProtoNode)
+	if !ok {
+		return nil, dag.ErrNotProtobuf
+	}
+	return pbnd, nil
+}
+
+// nameLocks serializes the edits of each DMS3NS name
+type nameLocks struct {
+	mu    sync.Mutex
+	locks map[string]*nameLock
+}
+
+type nameLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks the given name, and returns the function unlocking it
+func (l *nameLocks) lock(name string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*nameLock)
+	}
+	nl, ok := l.locks[name]
+	if !ok {
+		nl = new(nameLock)
+		l.locks[name] = nl
+	}
+	nl.refs++
+	l.mu.Unlock()
+
+	nl.Lock()
+	return func() {
+		nl.Unlock()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		nl.refs--
+		if nl.refs == 0 {
+			delete(l.locks, name)
+		}
+	}
+}
+
 func (i *gatewayHandler) addUserHeaders(w http.ResponseWriter) {
 	for k, v := range i.config.Headers {
 		w.Header()[k] = v
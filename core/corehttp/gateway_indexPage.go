@@ -14,6 +14,10 @@ type listingTemplateData struct {
 	Listing  []directoryItem
 	Path     string
 	BackLink string
+
+	// links to the other pages of a paginated directory listing
+	Prev string
+	Next string
 }
 
 type directoryItem struct {
@@ -24,6 +28,11 @@ type directoryItem struct {
 
 var listingTemplate *template.Template
 
+// listingPagesNav is inserted at the end of the body of the listing template,
+// and links to the other pages of a directory which does not fit on one page
+const listingPagesNav = `{{if or .Prev .Next}}<nav class="pages">{{if .Prev}}<a rel="prev" href="{{.Prev}}">&larr; Previous</a> {{end}}{{if .Next}}<a rel="next" href="{{.Next}}">Next &rarr;</a>{{end}}</nav>{{end}}
+`
+
 func init() {
 	knownIconsBytes, err := assets.Asset("dir-index-html/knownIcons.txt")
 	if err != nil {
@@ -57,8 +66,15 @@ func init() {
 		panic(err)
 	}
 
+	dirIndex := string(dirIndexBytes)
+	if i := strings.LastIndex(dirIndex, "</body>"); i >= 0 {
+		dirIndex = dirIndex[:i] + listingPagesNav + dirIndex[i:]
+	} else {
+		dirIndex += listingPagesNav
+	}
+
 	listingTemplate = template.Must(template.New("dir").Funcs(template.FuncMap{
 		"iconFromExt": iconFromExt,
 		"urlEscape":   urlEscape,
-	}).Parse(string(dirIndexBytes)))
+	}).Parse(dirIndex))
 }
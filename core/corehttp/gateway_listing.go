@@ -0,0 +1,213 @@
+package corehttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	dag "github.com/dms3-fs/go-merkledag"
+	ft "github.com/dms3-fs/go-unixfs"
+	uio "github.com/dms3-fs/go-unixfs/io"
+
+	cid "github.com/dms3-fs/go-cid"
+	dms3ld "github.com/dms3-fs/go-ld-format"
+)
+
+// defaultListingLimit is the number of entries of a directory listing page
+// when the request does not set a limit.
+const defaultListingLimit = 1000
+
+// errListingPageFull stops the enumeration of a directory once a listing
+// page is complete.
+var errListingPageFull = errors.New("listing page is full")
+
+// directoryListing is the JSON rendering of a page of a directory listing
+type directoryListing struct {
+	Path    string
+	Cid     string
+	Entries []directoryEntry
+	Offset  int
+	Limit   int
+	Next    string `json:",omitempty"`
+}
+
+type directoryEntry struct {
+	Name string
+	Cid  string
+	Size uint64
+
+	// Type is only known without fetching the entry for raw leaves, the
+	// other entries are fetched for their type when the request sets types
+	Type string
+}
+
+// listingRange returns the offset and limit of the listing page requested
+// by r
+func listingRange(r *http.Request) (int, int, error) {
+	offset, limit := 0, defaultListingLimit
+
+	q := r.URL.Query()
+	if s := q.Get("offset"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			return 0, 0, fmt.Errorf("invalid offset %q", s)
+		}
+		offset = v
+	}
+	if s := q.Get("limit"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v <= 0 {
+			return 0, 0, fmt.Errorf("invalid limit %q", s)
+		}
+		limit = v
+	}
+	return offset, limit, nil
+}
+
+// listingPage returns up to limit links of dir, starting at offset, and
+// whether more links follow. Links are enumerated as they are needed, so
+// the shards of a sharded directory past the page are never fetched.
+func listingPage(ctx context.Context, dir uio.Directory, offset, limit int) ([]*dms3ld.Link, bool, error) {
+	var links []*dms3ld.Link
+	more := false
+	skipped := 0
+	err := dir.ForEachLink(ctx, func(l *dms3ld.Link) error {
+		if skipped < offset {
+			skipped++
+			return nil
+		}
+		if len(links) == limit {
+			more = true
+			return errListingPageFull
+		}
+		links = append(links, l)
+		return nil
+	})
+	if err != nil && err != errListingPageFull {
+		return nil, false, err
+	}
+	return links, more, nil
+}
+
+// listingPageURL returns the URL of the listing page of the directory at
+// urlPath starting at offset, keeping the other query parameters of r
+func listingPageURL(r *http.Request, urlPath string, offset, limit int) string {
+	q := r.URL.Query()
+	q.Set("offset", strconv.Itoa(offset))
+	q.Set("limit", strconv.Itoa(limit))
+
+	u := url.URL{Path: urlPath, RawQuery: q.Encode()}
+	return u.String()
+}
+
+// setListingLinks sets the Link headers of the pages before and after the
+// listing page
+func setListingLinks(w http.ResponseWriter, r *http.Request, urlPath string, offset, limit int, more bool) (prev, next string) {
+	if offset > 0 {
+		prevOffset := offset - limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		prev = listingPageURL(r, urlPath, prevOffset, limit)
+		w.Header().Add("Link", "<"+prev+">; rel=\"prev\"")
+	}
+	if more {
+		next = listingPageURL(r, urlPath, offset+limit, limit)
+		w.Header().Add("Link", "<"+next+">; rel=\"next\"")
+	}
+	return prev, next
+}
+
+// serveDirListingJSON writes a page of the listing of the directory at the
+// resolved path as JSON
+func (i *gatewayHandler) serveDirListingJSON(ctx context.Context, w http.ResponseWriter, r *http.Request, originalUrlPath string, c *cid.Cid, dir uio.Directory) {
+	offset, limit, err := listingRange(r)
+	if err != nil {
+		webError(w, "invalid listing range", err, http.StatusBadRequest)
+		return
+	}
+
+	etag := generatedEtag("DirJSON", c, listingPageURL(r, originalUrlPath, offset, limit))
+	w.Header().Set("Etag", etag)
+	if checkNotModified(w, r, etag, time.Time{}) {
+		return
+	}
+
+	// fetching the entries for their types can be expensive, clients have
+	// to ask for it
+	fetchTypes, _ := strconv.ParseBool(r.URL.Query().Get("types"))
+
+	links, more, err := listingPage(ctx, dir, offset, limit)
+	if err != nil {
+		internalWebError(w, err)
+		return
+	}
+
+	listing := directoryListing{
+		Path:    originalUrlPath,
+		Cid:     c.String(),
+		Entries: make([]directoryEntry, 0, len(links)),
+		Offset:  offset,
+		Limit:   limit,
+	}
+	for _, l := range links {
+		listing.Entries = append(listing.Entries, directoryEntry{
+			Name: l.Name,
+			Cid:  l.Cid.String(),
+			Size: l.Size,
+			Type: i.linkType(ctx, l, fetchTypes),
+		})
+	}
+	_, listing.Next = setListingLinks(w, r, originalUrlPath, offset, limit, more)
+
+	w.Header().Set("Content-Type", jsonContentType)
+	if r.Method == "HEAD" {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(listing); err != nil {
+		log.Debugf("failed to write directory listing: %s", err)
+	}
+}
+
+// linkType returns the type of the node l points to: "directory", "file",
+// "symlink" or "unknown". Nodes other than raw leaves are only fetched for
+// their type when fetch is set, and are "unknown" otherwise.
+func (i *gatewayHandler) linkType(ctx context.Context, l *dms3ld.Link, fetch bool) string {
+	switch {
+	case l.Cid.Type() == cid.Raw:
+		return "file"
+	case !fetch || l.Cid.Type() != cid.DagProtobuf:
+		return "unknown"
+	}
+
+	nd, err := l.GetNode(ctx, i.node.DAG)
+	if err != nil {
+		return "unknown"
+	}
+
+	pbnd, ok := nd.(*dag.ProtoNode)
+	if !ok {
+		return "unknown"
+	}
+
+	d, err := ft.FromBytes(pbnd.Data())
+	if err != nil {
+		return "unknown"
+	}
+
+	switch d.GetType() {
+	case ft.TDirectory, ft.THAMTShard:
+		return "directory"
+	case ft.TFile, ft.TMetadata, ft.TRaw:
+		return "file"
+	case ft.TSymlink:
+		return "symlink"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,257 @@
+package corehttp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	gopath "path"
+	"strconv"
+	"strings"
+	"time"
+
+	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
+
+	resolver "github.com/dms3-fs/go-path/resolver"
+)
+
+const (
+	// redirectsFile is the file at the root of a served DAG holding the
+	// redirect and rewrite rules of its missing paths, one per line:
+	//
+	//	/from /to [status]
+	//
+	// A trailing /* in from matches the rest of the path, which is
+	// substituted for :splat in to, and :name segments match a single
+	// segment which is substituted for :name. The status defaults to 301.
+	// 301, 302, 303, 307 and 308 redirect the client to the target, 200,
+	// 404 and 410 serve the target with that status.
+	redirectsFile = "_redirects"
+
+	// notFoundFile is the page served for a missing path when no rule
+	// matches, looked up in its directory and then in each parent up to
+	// the root.
+	notFoundFile = "404.html"
+
+	// maxRedirectsSize limits the size of the redirects file.
+	maxRedirectsSize = 64 << 10
+)
+
+type redirectRule struct {
+	from   []string
+	to     string
+	status int
+}
+
+// parseRedirects parses the rules of a redirects file.
+func parseRedirects(r io.Reader) ([]redirectRule, error) {
+	var rules []redirectRule
+
+	s := bufio.NewScanner(io.LimitReader(r, maxRedirectsSize))
+	for n := 1; s.Scan(); n++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("%s line %d: expected \"from to [status]\"", redirectsFile, n)
+		}
+
+		rule := redirectRule{
+			from:   strings.Split(strings.Trim(fields[0], "/"), "/"),
+			to:     fields[1],
+			status: http.StatusMovedPermanently,
+		}
+		if !strings.HasPrefix(fields[0], "/") {
+			return nil, fmt.Errorf("%s line %d: %q is not an absolute path", redirectsFile, n, fields[0])
+		}
+
+		if len(fields) == 3 {
+			status, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("%s line %d: invalid status %q", redirectsFile, n, fields[2])
+			}
+			rule.status = status
+		}
+
+		switch {
+		case isRedirectStatus(rule.status):
+		case isRewriteStatus(rule.status):
+			if !strings.HasPrefix(rule.to, "/") {
+				return nil, fmt.Errorf("%s line %d: rewrite target %q is not an absolute path", redirectsFile, n, rule.to)
+			}
+		default:
+			return nil, fmt.Errorf("%s line %d: unsupported status %d", redirectsFile, n, rule.status)
+		}
+
+		rules = append(rules, rule)
+	}
+	return rules, s.Err()
+}
+
+func isRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+func isRewriteStatus(status int) bool {
+	switch status {
+	case http.StatusOK, http.StatusNotFound, http.StatusGone:
+		return true
+	}
+	return false
+}
+
+// match returns the target of the rule for path p, relative to the root of
+// the DAG, and whether the rule applies to it.
+func (rule redirectRule) match(p string) (string, bool) {
+	segs := strings.Split(strings.Trim(p, "/"), "/")
+
+	var replacer []string
+	for j, f := range rule.from {
+		if f == "*" && j == len(rule.from)-1 {
+			replacer = append(replacer, ":splat", strings.Join(segs[j:], "/"))
+			segs = nil
+			break
+		}
+		if j >= len(segs) {
+			return "", false
+		}
+
+		switch {
+		case strings.HasPrefix(f, ":"):
+			replacer = append(replacer, f, segs[j])
+		case f != segs[j]:
+			return "", false
+		}
+	}
+	if len(segs) > len(rule.from) {
+		return "", false
+	}
+
+	return strings.NewReplacer(replacer...).Replace(rule.to), true
+}
+
+// isNotFound reports whether err means that a path does not exist.
+func isNotFound(err error) bool {
+	_, ok := err.(resolver.ErrNoLink)
+	return ok
+}
+
+// serveNotFound handles a request for urlPath, which does not exist, with
+// the redirects file and 404 pages of the DAG the path is in. It returns
+// false if neither applies, leaving the response to the caller.
+func (i *gatewayHandler) serveNotFound(ctx context.Context, w http.ResponseWriter, r *http.Request, urlPath string, originalUrlPath string) bool {
+	segs := strings.SplitN(strings.TrimPrefix(urlPath, "/"), "/", 3)
+	if len(segs) < 2 {
+		return false
+	}
+
+	root := "/" + segs[0] + "/" + segs[1]
+	rest := "/"
+	if len(segs) == 3 {
+		rest += segs[2]
+	}
+
+	// base is the path the root is reachable at from the client, which is
+	// empty if DMS3NSHostnameOption rewrote the request.
+	base := strings.TrimSuffix(strings.TrimSuffix(originalUrlPath, "/"), strings.TrimSuffix(rest, "/"))
+
+	rules, err := i.redirectRules(ctx, root)
+	if err != nil {
+		webError(w, "invalid "+redirectsFile+" in "+root, err, http.StatusInternalServerError)
+		return true
+	}
+
+	for _, rule := range rules {
+		to, ok := rule.match(rest)
+		if !ok {
+			continue
+		}
+
+		if isRedirectStatus(rule.status) {
+			if strings.HasPrefix(to, "/") {
+				to = base + to
+			}
+			i.addUserHeaders(w)
+			http.Redirect(w, r, to, rule.status)
+			return true
+		}
+
+		if i.serveFileAt(ctx, w, r, root, to, rule.status) {
+			return true
+		}
+	}
+
+	for dir := gopath.Dir(gopath.Clean(rest)); ; dir = gopath.Dir(dir) {
+		if i.serveFileAt(ctx, w, r, root, gopath.Join(dir, notFoundFile), http.StatusNotFound) {
+			return true
+		}
+		if dir == "/" {
+			return false
+		}
+	}
+}
+
+// redirectRules returns the rules of the redirects file at root, if any.
+func (i *gatewayHandler) redirectRules(ctx context.Context, root string) ([]redirectRule, error) {
+	p, err := coreiface.ParsePath(gopath.Join(root, redirectsFile))
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := i.api.Unixfs().Cat(ctx, p)
+	if err != nil {
+		// a missing or unreadable redirects file has no rules
+		return nil, nil
+	}
+	defer f.Close()
+
+	return parseRedirects(f)
+}
+
+// serveFileAt serves the file at p below root with the given status, or the
+// index.html of the directory at p. It returns false if there is no such
+// file.
+func (i *gatewayHandler) serveFileAt(ctx context.Context, w http.ResponseWriter, r *http.Request, root string, p string, status int) bool {
+	name := gopath.Join(root, p)
+	fp, err := coreiface.ParsePath(name)
+	if err != nil {
+		return false
+	}
+
+	f, err := i.api.Unixfs().Cat(ctx, fp)
+	if err == coreiface.ErrIsDir {
+		name = gopath.Join(name, "index.html")
+		if fp, err = coreiface.ParsePath(name); err == nil {
+			f, err = i.api.Unixfs().Cat(ctx, fp)
+		}
+	}
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	i.addUserHeaders(w) // ok, _now_ write user's headers.
+	w.Header().Set("X-DMS3FS-Path", name)
+
+	if status == http.StatusOK {
+		i.serveFile(w, r, gopath.Base(name), time.Time{}, f)
+		return true
+	}
+
+	ctype := mime.TypeByExtension(gopath.Ext(name))
+	if ctype == "" {
+		ctype = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", ctype)
+	w.WriteHeader(status)
+	io.Copy(w, f)
+	return true
+}
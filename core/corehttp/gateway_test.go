@@ -1,29 +1,43 @@
 package corehttp
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"math"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	version "github.com/dms3-fs/go-dms3-fs"
 	core "github.com/dms3-fs/go-dms3-fs/core"
+	coreapi "github.com/dms3-fs/go-dms3-fs/core/coreapi"
 	coreunix "github.com/dms3-fs/go-dms3-fs/core/coreunix"
+	dagutils "github.com/dms3-fs/go-dms3-fs/dagutils"
+	keystore "github.com/dms3-fs/go-dms3-fs/keystore"
 	namesys "github.com/dms3-fs/go-dms3-fs/namesys"
 	nsopts "github.com/dms3-fs/go-dms3-fs/namesys/opts"
 	repo "github.com/dms3-fs/go-dms3-fs/repo"
 
+	cid "github.com/dms3-fs/go-cid"
 	datastore "github.com/dms3-fs/go-datastore"
 	syncds "github.com/dms3-fs/go-datastore/sync"
 	config "github.com/dms3-fs/go-fs-config"
+	cbor "github.com/dms3-fs/go-ld-cbor"
 	dag "github.com/dms3-fs/go-merkledag"
 	path "github.com/dms3-fs/go-path"
+	ft "github.com/dms3-fs/go-unixfs"
+	multibase "github.com/dms3-mft/go-multibase"
 	ci "github.com/dms3-p2p/go-p2p-crypto"
+	peer "github.com/dms3-p2p/go-p2p-peer"
 	id "github.com/dms3-p2p/go-p2p/p2p/protocol/identify"
 )
 
@@ -58,11 +72,16 @@ func (m mockNamesys) Resolve(ctx context.Context, name string, opts ...nsopts.Re
 }
 
 func (m mockNamesys) Publish(ctx context.Context, name ci.PrivKey, value path.Path) error {
-	return errors.New("not implemented for mockNamesys")
+	pid, err := peer.IDFromPrivateKey(name)
+	if err != nil {
+		return err
+	}
+	m["/dms3ns/"+pid.Pretty()] = value
+	return nil
 }
 
 func (m mockNamesys) PublishWithEOL(ctx context.Context, name ci.PrivKey, value path.Path, _ time.Time) error {
-	return errors.New("not implemented for mockNamesys")
+	return m.Publish(ctx, name, value)
 }
 
 func (m mockNamesys) GetResolver(subs string) (namesys.Resolver, bool) {
@@ -78,6 +97,7 @@ func newNodeWithMockNamesys(ns mockNamesys) (*core.Dms3FsNode, error) {
 	r := &repo.Mock{
 		C: c,
 		D: syncds.MutexWrap(datastore.NewMapDatastore()),
+		K: keystore.NewMemKeystore(),
 	}
 	n, err := core.NewNode(context.Background(), &core.BuildCfg{Repo: r})
 	if err != nil {
@@ -203,6 +223,27 @@ func TestGatewayGet(t *testing.T) {
 			continue
 		}
 	}
+
+	// a client can not skip the rewrite of DNSLink hostnames with the header
+	// set on rewritten requests
+	r, err := http.NewRequest("GET", ts.URL+"/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Host = "working.example.com"
+	r.Header.Set("X-Dms3Ns-Original-Path", "/")
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "fnord" {
+		t.Errorf("expected the request with a forged X-Dms3Ns-Original-Path to be rewritten, got %d %q", resp.StatusCode, body)
+	}
 }
 
 func TestDMS3NSHostnameRedirect(t *testing.T) {
@@ -524,36 +565,254 @@ func TestCacheControlImmutable(t *testing.T) {
 	}
 }
 
-func TestGoGetSupport(t *testing.T) {
-	ts, _ := newTestServerAndNode(t, nil)
-	t.Logf("test server url: %s", ts.URL)
+func TestGatewayRange(t *testing.T) {
+	ts, n := newTestServerAndNode(t, nil)
 	defer ts.Close()
 
-	// mimic go-get
-	req, err := http.NewRequest("GET", ts.URL+emptyDir+"?go-get=1", nil)
+	k, err := coreunix.Add(n, strings.NewReader("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest("GET", ts.URL+"/dms3fs/"+k, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
+	req.Header.Set("Range", "bytes=10-")
 
 	res, err := doWithoutRedirect(req)
 	if err != nil {
 		t.Fatal(err)
 	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	if res.StatusCode != 200 {
+	if res.StatusCode != http.StatusPartialContent {
+		t.Fatalf("status is %d, expected 206", res.StatusCode)
+	}
+	if cr := res.Header.Get("Content-Range"); cr != "bytes 10-15/16" {
+		t.Errorf("unexpected Content-Range: %s", cr)
+	}
+	if string(body) != "abcdef" {
+		t.Errorf("unexpected body: %q", body)
+	}
+
+	// multiple ranges are sent as a multipart response
+	req.Header.Set("Range", "bytes=0-1,-2")
+	res, err = doWithoutRedirect(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusPartialContent {
+		t.Fatalf("status is %d, expected 206", res.StatusCode)
+	}
+	mt, params, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil || mt != "multipart/byteranges" {
+		t.Fatalf("unexpected Content-Type: %s", res.Header.Get("Content-Type"))
+	}
+
+	var parts []string
+	mr := multipart.NewReader(res.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		data, err := ioutil.ReadAll(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, string(data))
+	}
+	if len(parts) != 2 || parts[0] != "01" || parts[1] != "ef" {
+		t.Errorf("unexpected parts: %q", parts)
+	}
+}
+
+func TestGatewayConditionalGet(t *testing.T) {
+	ns := mockNamesys{}
+	ts, n := newTestServerAndNode(t, ns)
+	defer ts.Close()
+
+	k, err := coreunix.Add(n, strings.NewReader("fnord"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns["/dms3ns/example.com"] = path.FromString("/dms3fs/" + k)
+	etag := "\"" + k + "\""
+
+	for _, test := range []struct {
+		path   string
+		header string
+		value  string
+		status int
+	}{
+		{"/dms3fs/" + k, "", "", http.StatusOK},
+		{"/dms3fs/" + k, "If-None-Match", etag, http.StatusNotModified},
+		{"/dms3fs/" + k, "If-None-Match", "W/" + etag, http.StatusNotModified},
+		{"/dms3fs/" + k, "If-None-Match", "\"foo\", " + etag, http.StatusNotModified},
+		{"/dms3fs/" + k, "If-None-Match", "*", http.StatusNotModified},
+		{"/dms3fs/" + k, "If-None-Match", "\"foo\"", http.StatusOK},
+		{"/dms3fs/" + k, "If-Modified-Since", time.Now().UTC().Format(http.TimeFormat), http.StatusNotModified},
+		{"/dms3ns/example.com", "If-None-Match", etag, http.StatusNotModified},
+		{"/dms3ns/example.com", "If-Modified-Since", time.Now().UTC().Format(http.TimeFormat), http.StatusOK},
+	} {
+		req, err := http.NewRequest("GET", ts.URL+test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.header != "" {
+			req.Header.Set(test.header, test.value)
+		}
+
+		res, err := doWithoutRedirect(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != test.status {
+			t.Errorf("%s with %s: %s: status is %d, expected %d", test.path, test.header, test.value, res.StatusCode, test.status)
+		}
+		if res.Header.Get("Etag") != etag {
+			t.Errorf("%s: unexpected Etag: %s", test.path, res.Header.Get("Etag"))
+		}
+	}
+}
+
+func TestGatewayDirListingEtag(t *testing.T) {
+	ts, _ := newTestServerAndNode(t, nil)
+	defer ts.Close()
+
+	get := func(p string, inm string) *http.Response {
+		req, err := http.NewRequest("GET", ts.URL+p, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inm != "" {
+			req.Header.Set("If-None-Match", inm)
+		}
+
+		res, err := doWithoutRedirect(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res
+	}
+
+	res := get(emptyDir+"/", "")
+	etag := res.Header.Get("Etag")
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(etag, "\"DirIndex-") {
+		t.Fatalf("unexpected listing response: %d, Etag %s", res.StatusCode, etag)
+	}
+
+	// the cid alone doesn't identify the listing
+	if res := get(emptyDir+"/", "\""+emptyDir[len("/dms3fs/"):]+"\""); res.StatusCode != http.StatusOK {
 		t.Errorf("status is %d, expected 200", res.StatusCode)
 	}
+
+	if res := get(emptyDir+"/", etag); res.StatusCode != http.StatusNotModified {
+		t.Errorf("status is %d, expected 304", res.StatusCode)
+	}
+
+	if res := get(emptyDir, ""); res.Header.Get("Etag") == etag {
+		t.Errorf("expected a different Etag for a different path")
+	}
 }
 
-func TestVersion(t *testing.T) {
-	version.CurrentCommit = "theshortcommithash"
+func TestGatewayRawBlock(t *testing.T) {
+	ts, n := newTestServerAndNode(t, nil)
+	defer ts.Close()
 
-	ns := mockNamesys{}
-	ts, _ := newTestServerAndNode(t, ns)
-	t.Logf("test server url: %s", ts.URL)
+	k, err := coreunix.Add(n, strings.NewReader("fnord"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := cid.Decode(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nd, err := n.DAG.Get(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		query  string
+		accept string
+	}{
+		{"?format=raw", ""},
+		{"", "application/vnd.dms3ld.raw"},
+		{"", "text/html, application/vnd.dms3ld.raw;q=0.9"},
+	} {
+		req, err := http.NewRequest("GET", ts.URL+"/dms3fs/"+k+test.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+
+		res, err := doWithoutRedirect(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("status is %d, expected 200", res.StatusCode)
+		}
+		if ct := res.Header.Get("Content-Type"); ct != "application/vnd.dms3ld.raw" {
+			t.Errorf("unexpected Content-Type: %s", ct)
+		}
+		if et := res.Header.Get("Etag"); et != "\""+k+".raw\"" {
+			t.Errorf("unexpected Etag: %s", et)
+		}
+		if !bytes.Equal(body, nd.RawData()) {
+			t.Errorf("unexpected block data: %q", body)
+		}
+	}
+
+	req, err := http.NewRequest("GET", ts.URL+"/dms3fs/"+k+"?format=foo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := doWithoutRedirect(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("status is %d, expected 400", res.StatusCode)
+	}
+}
+
+func TestGatewayCar(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts, n := newTestServerAndNode(t, nil)
 	defer ts.Close()
 
-	req, err := http.NewRequest("GET", ts.URL+"/version", nil)
+	_, dagn, err := coreunix.AddWrapped(n, strings.NewReader("fnord"), "file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := new(bytes.Buffer)
+	err = dagutils.WriteCar(ctx, n.DAG, []*cid.Cid{dagn.Cid()}, expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest("GET", ts.URL+"/dms3fs/"+dagn.Cid().String()+"?format=car", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -564,19 +823,749 @@ func TestVersion(t *testing.T) {
 	}
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		t.Fatalf("error reading response: %s", err)
+		t.Fatal(err)
 	}
-	s := string(body)
 
-	if !strings.Contains(s, "Commit: theshortcommithash") {
-		t.Fatalf("response doesn't contain commit:\n%s", s)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status is %d, expected 200", res.StatusCode)
+	}
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/vnd.dms3ld.car") {
+		t.Errorf("unexpected Content-Type: %s", ct)
+	}
+	if !bytes.Equal(body, expected.Bytes()) {
+		t.Error("unexpected car archive")
 	}
 
-	if !strings.Contains(s, "Client Version: "+id.ClientVersion) {
-		t.Fatalf("response doesn't contain client version:\n%s", s)
+	req.Header.Set("If-None-Match", res.Header.Get("Etag"))
+	res, err = doWithoutRedirect(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusNotModified {
+		t.Errorf("status is %d, expected 304", res.StatusCode)
 	}
+}
 
-	if !strings.Contains(s, "Protocol Version: "+id.LibP2PVersion) {
-		t.Fatalf("response doesn't contain protocol version:\n%s", s)
+func TestGatewayCarMissingBlock(t *testing.T) {
+	ts, n := newTestServerAndNode(t, nil)
+	defer ts.Close()
+
+	// the child is never added, so the export fails before anything is sent
+	child := dag.NodeWithData([]byte("missing"))
+	root := dag.NodeWithData([]byte("root"))
+	if err := root.AddNodeLink("child", child); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.DAG.Add(context.Background(), root); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest("GET", ts.URL+"/dms3fs/"+root.Cid().String()+"?format=car", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := doWithoutRedirect(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status is %d, expected 500", res.StatusCode)
+	}
+	if res.Header.Get("Content-Disposition") != "" {
+		t.Error("error response has a Content-Disposition header")
+	}
+}
+
+func TestGatewayDagNode(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts, n := newTestServerAndNode(t, nil)
+	defer ts.Close()
+
+	k, err := coreunix.Add(n, strings.NewReader("fnord"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fc, err := cid.Decode(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nd, err := cbor.WrapObject(map[string]interface{}{
+		"foo":  map[string]interface{}{"bar": 42},
+		"file": fc,
+	}, math.MaxUint64, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.DAG.Add(ctx, nd); err != nil {
+		t.Fatal(err)
+	}
+	base := "/dms3fs/" + nd.Cid().String()
+
+	get := func(p string, accept string) (*http.Response, string) {
+		req, err := http.NewRequest("GET", ts.URL+p, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+
+		res, err := doWithoutRedirect(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, string(body)
+	}
+
+	res, body := get(base, "")
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected response: %d %s", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &out); err != nil {
+		t.Fatal(err)
+	}
+	if link, ok := out["file"].(map[string]interface{}); !ok || link["/"] != k {
+		t.Errorf("unexpected file link: %v", out["file"])
+	}
+
+	// traverse the fields of the node
+	res, body = get(base+"/foo/bar", "")
+	if res.StatusCode != http.StatusOK || body != "42" {
+		t.Errorf("unexpected field value: %d %q", res.StatusCode, body)
+	}
+
+	res, _ = get(base+"/foo/baz", "")
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("status is %d, expected 404", res.StatusCode)
+	}
+
+	// and through its links
+	res, body = get(base+"/file", "")
+	if res.StatusCode != http.StatusOK || body != "fnord" {
+		t.Errorf("unexpected linked file: %d %q", res.StatusCode, body)
+	}
+
+	res, body = get(base, "text/html")
+	if !strings.HasPrefix(res.Header.Get("Content-Type"), "text/html") {
+		t.Fatalf("unexpected Content-Type: %s", res.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(body, "<a href=\"/dms3fs/"+k+"\">") {
+		t.Errorf("expected a link to the file:\n%s", body)
+	}
+	if !strings.Contains(body, "<a href=\""+base+"/foo\">") {
+		t.Errorf("expected a link to the foo field:\n%s", body)
+	}
+}
+
+func TestGatewayACL(t *testing.T) {
+	n, err := newNodeWithMockNamesys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acl := &GatewayACL{
+		Rules: []GatewayACLRule{
+			{Token: "secret", Methods: []string{"POST"}, Paths: []string{"/dms3fs"}},
+			{Username: "alice", Password: "pass", Methods: []string{"PUT", "DELETE"}, Names: []string{"example.com"}},
+		},
+	}
+
+	dh := &delegatedHandler{}
+	ts := httptest.NewServer(dh)
+	defer ts.Close()
+
+	dh.Handler, err = makeHandler(n, ts.Listener, WritableGatewayOption(acl, "/dms3fs", "/dms3ns"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		method string
+		path   string
+		auth   func(*http.Request)
+		status int
+	}{
+		{"POST", "/dms3fs/", nil, http.StatusUnauthorized},
+		{"POST", "/dms3fs/", func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") }, http.StatusUnauthorized},
+		{"POST", "/dms3fs/", func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") }, http.StatusCreated},
+		{"PUT", emptyDir + "/foo", func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") }, http.StatusForbidden},
+		{"PUT", emptyDir + "/foo", func(r *http.Request) { r.SetBasicAuth("alice", "pass") }, http.StatusForbidden},
+		{"DELETE", "/dms3ns/other.com/foo", func(r *http.Request) { r.SetBasicAuth("alice", "pass") }, http.StatusForbidden},
+		{"DELETE", "/dms3ns/example.com/foo", func(r *http.Request) { r.SetBasicAuth("alice", "wrong") }, http.StatusUnauthorized},
+	} {
+		req, err := http.NewRequest(test.method, ts.URL+test.path, strings.NewReader("fnord"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.auth != nil {
+			test.auth(req)
+		}
+
+		res, err := doWithoutRedirect(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != test.status {
+			t.Errorf("%s %s: status is %d, expected %d", test.method, test.path, res.StatusCode, test.status)
+		}
+		if test.status == http.StatusUnauthorized && res.Header.Get("WWW-Authenticate") == "" {
+			t.Errorf("%s %s: expected a WWW-Authenticate header", test.method, test.path)
+		}
+	}
+
+	// names are writable targets
+	req := httptest.NewRequest("PUT", "/dms3ns/example.com/foo/bar", nil)
+	req.SetBasicAuth("alice", "pass")
+	if code, reason := acl.Check(req); code != 0 {
+		t.Errorf("expected PUT on a listed name to be allowed, got %d: %s", code, reason)
+	}
+
+	req = httptest.NewRequest("PUT", "/dms3ns/example.community/foo", nil)
+	req.SetBasicAuth("alice", "pass")
+	if code, _ := acl.Check(req); code != http.StatusForbidden {
+		t.Errorf("expected PUT on another name to be denied, got %d", code)
+	}
+}
+
+func TestGoGetSupport(t *testing.T) {
+	ts, _ := newTestServerAndNode(t, nil)
+	t.Logf("test server url: %s", ts.URL)
+	defer ts.Close()
+
+	// mimic go-get
+	req, err := http.NewRequest("GET", ts.URL+emptyDir+"?go-get=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := doWithoutRedirect(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != 200 {
+		t.Errorf("status is %d, expected 200", res.StatusCode)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	version.CurrentCommit = "theshortcommithash"
+
+	ns := mockNamesys{}
+	ts, _ := newTestServerAndNode(t, ns)
+	t.Logf("test server url: %s", ts.URL)
+	defer ts.Close()
+
+	req, err := http.NewRequest("GET", ts.URL+"/version", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := doWithoutRedirect(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("error reading response: %s", err)
+	}
+	s := string(body)
+
+	if !strings.Contains(s, "Commit: theshortcommithash") {
+		t.Fatalf("response doesn't contain commit:\n%s", s)
+	}
+
+	if !strings.Contains(s, "Client Version: "+id.ClientVersion) {
+		t.Fatalf("response doesn't contain client version:\n%s", s)
+	}
+
+	if !strings.Contains(s, "Protocol Version: "+id.LibP2PVersion) {
+		t.Fatalf("response doesn't contain protocol version:\n%s", s)
+	}
+}
+
+func TestGatewayWritableName(t *testing.T) {
+	ns := mockNamesys{}
+	n, err := newNodeWithMockNamesys(ns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := coreapi.NewCoreAPI(n)
+	key, err := api.Key().Generate(n.Context(), "site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := key.ID().Pretty()
+
+	dh := &delegatedHandler{}
+	ts := httptest.NewServer(dh)
+	defer ts.Close()
+
+	dh.Handler, err = makeHandler(n, ts.Listener, WritableGatewayOption(nil, "/dms3fs", "/dms3ns"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	do := func(method, p, body string) *http.Response {
+		req, err := http.NewRequest(method, ts.URL+p, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := doWithoutRedirect(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	// the name has not been published yet, so the edit starts from an
+	// empty directory
+	res := do("PUT", "/dms3ns/"+name+"/dir/index.html", "fnord")
+	res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("PUT: status is %d, expected %d", res.StatusCode, http.StatusCreated)
+	}
+	if res.Header.Get("DMS3NS-Name") != name {
+		t.Errorf("PUT: DMS3NS-Name is %q, expected %q", res.Header.Get("DMS3NS-Name"), name)
+	}
+	if loc := res.Header.Get("Location"); loc != "/dms3ns/"+name+"/dir/index.html" {
+		t.Errorf("PUT: unexpected location %q", loc)
+	}
+
+	hash := res.Header.Get("DMS3FS-Hash")
+	if ns["/dms3ns/"+name].String() != "/dms3fs/"+hash {
+		t.Fatalf("expected %s to be published as /dms3fs/%s, got %s", name, hash, ns["/dms3ns/"+name])
+	}
+
+	res = do("GET", "/dms3ns/"+name+"/dir/index.html", "")
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "fnord" {
+		t.Fatalf("GET: unexpected content %q", body)
+	}
+
+	res = do("DELETE", "/dms3ns/"+name+"/dir/index.html", "")
+	res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("DELETE: status is %d, expected %d", res.StatusCode, http.StatusCreated)
+	}
+	if loc := res.Header.Get("Location"); loc != "/dms3ns/"+name+"/dir" {
+		t.Errorf("DELETE: unexpected location %q", loc)
+	}
+
+	res = do("GET", "/dms3ns/"+name+"/dir/index.html", "")
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("GET after DELETE: status is %d, expected %d", res.StatusCode, http.StatusNotFound)
+	}
+
+	// removing a missing link is the client's error
+	res = do("DELETE", "/dms3ns/"+name+"/dir/index.html", "")
+	res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("DELETE of a missing link: status is %d, expected %d", res.StatusCode, http.StatusBadRequest)
+	}
+
+	// names of other nodes can not be edited
+	res = do("PUT", "/dms3ns/example.com/foo", "fnord")
+	res.Body.Close()
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("PUT on a foreign name: status is %d, expected %d", res.StatusCode, http.StatusForbidden)
+	}
+
+	// but DNSLink names pointing to a key of the node can
+	ns["/dms3ns/site.example.com"] = path.FromString("/dms3ns/" + name)
+	res = do("PUT", "/dms3ns/site.example.com/other.html", "fnord")
+	res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("PUT on a DNSLink name: status is %d, expected %d", res.StatusCode, http.StatusCreated)
+	}
+	if res.Header.Get("DMS3NS-Name") != name {
+		t.Errorf("PUT on a DNSLink name: DMS3NS-Name is %q, expected %q", res.Header.Get("DMS3NS-Name"), name)
+	}
+	if loc := res.Header.Get("Location"); loc != "/dms3ns/site.example.com/other.html" {
+		t.Errorf("PUT on a DNSLink name: unexpected location %q", loc)
+	}
+
+	// and so can key names
+	res = do("PUT", "/dms3ns/site/dir/index.html", "fnord")
+	res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("PUT on a key name: status is %d, expected %d", res.StatusCode, http.StatusCreated)
+	}
+	if loc := res.Header.Get("Location"); loc != "/dms3ns/"+name+"/dir/index.html" {
+		t.Errorf("PUT on a key name: unexpected location %q", loc)
+	}
+
+	// concurrent edits of a name are not lost
+	var wg sync.WaitGroup
+	codes := make(chan int, 8)
+	for j := 0; j < 8; j++ {
+		wg.Add(1)
+		go func(j int) {
+			defer wg.Done()
+			req, err := http.NewRequest("PUT", ts.URL+"/dms3ns/"+name+"/concurrent/"+strconv.Itoa(j), strings.NewReader("fnord"))
+			if err != nil {
+				codes <- 0
+				return
+			}
+			res, err := doWithoutRedirect(req)
+			if err != nil {
+				codes <- 0
+				return
+			}
+			res.Body.Close()
+			codes <- res.StatusCode
+		}(j)
+	}
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusCreated {
+			t.Fatalf("concurrent PUT: status is %d, expected %d", code, http.StatusCreated)
+		}
+	}
+
+	for _, p := range []string{"/other.html", "/dir/index.html"} {
+		res = do("GET", "/dms3ns/"+name+p, "")
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("GET %s after the edits: status is %d, expected %d", p, res.StatusCode, http.StatusOK)
+		}
+	}
+	for j := 0; j < 8; j++ {
+		res = do("GET", "/dms3ns/"+name+"/concurrent/"+strconv.Itoa(j), "")
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("GET of the concurrent edit %d: status is %d, expected %d", j, res.StatusCode, http.StatusOK)
+		}
+	}
+}
+
+func TestSubdomainGateway(t *testing.T) {
+	ns := mockNamesys{}
+	n, err := newNodeWithMockNamesys(ns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dh := &delegatedHandler{}
+	ts := httptest.NewServer(dh)
+	defer ts.Close()
+
+	dh.Handler, err = makeHandler(n,
+		ts.Listener,
+		SubdomainGatewayOption("example.net"),
+		DMS3NSHostnameOption(),
+		GatewayOption(false, "/dms3fs", "/dms3ns"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := coreunix.Add(n, strings.NewReader("fnord"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := cid.Decode(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cidLabel, err := multibase.Encode(multibase.Base32, cid.NewCidV1(c.Type(), c.Hash()).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pid, err := peer.IDB58Decode("QmTFauExutTsy4XP6JbMFcw2Wa9645HJt2bTqL6qYDCKfe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyLabel, err := multibase.Encode(multibase.Base32, cid.NewCidV1(dms3p2pKeyCodec, []byte(pid)).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ns["/dms3ns/"+pid.Pretty()] = path.FromString("/dms3fs/" + k)
+	ns["/dms3ns/my-site.example.com"] = path.FromString("/dms3fs/" + k)
+
+	for _, test := range []struct {
+		host     string
+		path     string
+		status   int
+		location string
+		text     string
+	}{
+		{"example.net", "/dms3fs/" + k + "?x=1", http.StatusMovedPermanently, "http://" + cidLabel + ".dms3fs.example.net/?x=1", ""},
+		{"example.net", "/dms3ns/" + pid.Pretty(), http.StatusMovedPermanently, "http://" + keyLabel + ".dms3ns.example.net/", ""},
+		{"example.net", "/dms3ns/my-site.example.com/foo", http.StatusMovedPermanently, "http://my--site-example-com.dms3ns.example.net/foo", ""},
+		{cidLabel + ".dms3fs.example.net", "/", http.StatusOK, "", "fnord"},
+		{keyLabel + ".dms3ns.example.net", "/", http.StatusOK, "", "fnord"},
+		{"my--site-example-com.dms3ns.example.net", "/", http.StatusOK, "", "fnord"},
+		{"notacid.dms3fs.example.net", "/", http.StatusBadRequest, "", ""},
+		// other hosts are served path-style
+		{"localhost", "/dms3fs/" + k, http.StatusOK, "", "fnord"},
+	} {
+		req, err := http.NewRequest("GET", ts.URL+test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Host = test.host
+
+		res, err := doWithoutRedirect(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.StatusCode != test.status {
+			t.Errorf("%s%s: status is %d, expected %d", test.host, test.path, res.StatusCode, test.status)
+			continue
+		}
+		if loc := res.Header.Get("Location"); loc != test.location {
+			t.Errorf("%s%s: location is %q, expected %q", test.host, test.path, loc, test.location)
+		}
+		if test.text != "" && string(body) != test.text {
+			t.Errorf("%s%s: unexpected content %q", test.host, test.path, body)
+		}
+	}
+}
+
+func TestGatewayNotFound(t *testing.T) {
+	ns := mockNamesys{}
+	ts, n := newTestServerAndNode(t, ns)
+	defer ts.Close()
+
+	ctx := n.Context()
+
+	makeDir := func(files map[string]string) string {
+		e := dagutils.NewDagEditor(ft.EmptyDirNode(), n.DAG)
+		for p, content := range files {
+			k, err := coreunix.Add(n, strings.NewReader(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := cid.Decode(k)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nd, err := n.DAG.Get(ctx, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := e.InsertNodeAtPath(ctx, p, nd, ft.EmptyDirNode); err != nil {
+				t.Fatal(err)
+			}
+		}
+		root, err := e.Finalize(ctx, n.DAG)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return root.Cid().String()
+	}
+
+	k := makeDir(map[string]string{
+		"_redirects":     "# single page app\n/app/* /app/index.html 200\n/old/:slug /new/:slug 302\n/gone /gone.html 410\n",
+		"app/index.html": "spa",
+		"gone.html":      "gone",
+		"404.html":       "root 404",
+		"docs/404.html":  "docs 404",
+		"docs/page.html": "page",
+	})
+	bad := makeDir(map[string]string{
+		"_redirects": "/a /b 999\n",
+	})
+	ns["/dms3ns/example.net"] = path.FromString("/dms3fs/" + k)
+
+	for _, test := range []struct {
+		host     string
+		path     string
+		status   int
+		location string
+		text     string
+	}{
+		{"localhost:5001", "/dms3fs/" + k + "/app/some/route", http.StatusOK, "", "spa"},
+		{"localhost:5001", "/dms3fs/" + k + "/old/post", http.StatusFound, "/dms3fs/" + k + "/new/post", ""},
+		{"localhost:5001", "/dms3fs/" + k + "/gone", http.StatusGone, "", "gone"},
+		{"localhost:5001", "/dms3fs/" + k + "/docs/missing", http.StatusNotFound, "", "docs 404"},
+		{"localhost:5001", "/dms3fs/" + k + "/docs/deeper/missing", http.StatusNotFound, "", "docs 404"},
+		{"localhost:5001", "/dms3fs/" + k + "/missing", http.StatusNotFound, "", "root 404"},
+		{"localhost:5001", "/dms3fs/" + k + "/docs/page.html", http.StatusOK, "", "page"},
+		{"example.net", "/old/post", http.StatusFound, "/new/post", ""},
+		{"example.net", "/app/deep/link", http.StatusOK, "", "spa"},
+		{"example.net", "/missing", http.StatusNotFound, "", "root 404"},
+		{"localhost:5001", "/dms3fs/" + bad + "/missing", http.StatusInternalServerError, "", ""},
+	} {
+		req, err := http.NewRequest("GET", ts.URL+test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Host = test.host
+
+		res, err := doWithoutRedirect(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.StatusCode != test.status {
+			t.Errorf("%s%s: status is %d, expected %d", test.host, test.path, res.StatusCode, test.status)
+			continue
+		}
+		if loc := res.Header.Get("Location"); loc != test.location {
+			t.Errorf("%s%s: location is %q, expected %q", test.host, test.path, loc, test.location)
+		}
+		if test.text != "" && string(body) != test.text {
+			t.Errorf("%s%s: unexpected content %q", test.host, test.path, body)
+		}
+	}
+}
+
+func TestGatewayDirListingPages(t *testing.T) {
+	ns := mockNamesys{}
+	ts, n := newTestServerAndNode(t, ns)
+	defer ts.Close()
+
+	ctx := n.Context()
+
+	e := dagutils.NewDagEditor(ft.EmptyDirNode(), n.DAG)
+	for _, p := range []string{"a", "b", "c", "d/x", "e"} {
+		k, err := coreunix.Add(n, strings.NewReader(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := cid.Decode(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nd, err := n.DAG.Get(ctx, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := e.InsertNodeAtPath(ctx, p, nd, ft.EmptyDirNode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root, err := e.Finalize(ctx, n.DAG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirPath := "/dms3fs/" + root.Cid().String() + "/"
+
+	getListing := func(query string, header string) (*http.Response, directoryListing) {
+		req, err := http.NewRequest("GET", ts.URL+dirPath+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header != "" {
+			req.Header.Set("Accept", header)
+		}
+		res, err := doWithoutRedirect(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status is %d", query, res.StatusCode)
+		}
+		if ct := res.Header.Get("Content-Type"); ct != "application/json" {
+			t.Fatalf("%s: content type is %q", query, ct)
+		}
+
+		var listing directoryListing
+		if err := json.NewDecoder(res.Body).Decode(&listing); err != nil {
+			t.Fatal(err)
+		}
+		return res, listing
+	}
+
+	// walk the listing two entries at a time
+	types := map[string]string{}
+	next := "?format=json&limit=2&types=1"
+	for pages := 0; next != ""; pages++ {
+		if pages == 3 {
+			t.Fatal("expected the listing to end after three pages")
+		}
+
+		res, listing := getListing(next, "")
+		if len(listing.Entries) == 0 || len(listing.Entries) > 2 {
+			t.Fatalf("unexpected page size %d", len(listing.Entries))
+		}
+		for _, entry := range listing.Entries {
+			types[entry.Name] = entry.Type
+		}
+
+		if listing.Next != "" && !strings.Contains(res.Header.Get("Link"), `rel="next"`) {
+			t.Errorf("expected a Link header to the next page")
+		}
+		next = strings.TrimPrefix(listing.Next, dirPath)
+	}
+
+	if len(types) != 5 || types["a"] != "file" || types["d"] != "directory" {
+		t.Fatalf("unexpected listing: %v", types)
+	}
+
+	_, listing := getListing("?offset=4&limit=2", "application/json")
+	if len(listing.Entries) != 1 || listing.Next != "" || listing.Offset != 4 {
+		t.Fatalf("unexpected last page: %+v", listing)
+	}
+
+	// without types, the entries are not fetched for their type
+	_, listing = getListing("?format=json", "")
+	for _, entry := range listing.Entries {
+		if entry.Type != "unknown" {
+			t.Errorf("expected the type of %s to be unknown without types, got %s", entry.Name, entry.Type)
+		}
+	}
+
+	// the HTML listing links to the next page
+	res, err := http.Get(ts.URL + dirPath + "?limit=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nav := strings.Index(string(body), `rel="next" href="`+dirPath+`?limit=2&amp;offset=2"`)
+	if nav < 0 {
+		t.Fatalf("expected a link to the next page in the listing:\n%s", body)
+	}
+	if end := strings.Index(string(body), "</body>"); end >= 0 && nav > end {
+		t.Fatalf("expected the link to the next page inside the body of the listing:\n%s", body)
+	}
+
+	res, err = http.Get(ts.URL + dirPath + "?limit=-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("invalid limit: status is %d, expected %d", res.StatusCode, http.StatusBadRequest)
 	}
 }
@@ -23,8 +23,10 @@ func DMS3NSHostnameOption() ServeOption {
 			ctx, cancel := context.WithCancel(n.Context())
 			defer cancel()
 
+			// requests already rewritten by SubdomainGatewayOption are left
+			// alone
 			host := strings.SplitN(r.Host, ":", 2)[0]
-			if len(host) > 0 && isd.IsDomain(host) {
+			if len(host) > 0 && isd.IsDomain(host) && !subdomainRewritten(r) {
 				name := "/dms3ns/" + host
 				_, err := n.Namesys.Resolve(ctx, name, nsopts.Depth(1))
 				if err == nil || err == namesys.ErrResolveRecursion {
@@ -3,9 +3,13 @@ package corehttp
 import (
 	"net"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	core "github.com/dms3-fs/go-dms3-fs/core"
 
+	bitswap "github.com/dms3-fs/go-bitswap"
 	prometheus "github.com/gxed/client_golang/prometheus"
 )
 
@@ -30,14 +34,107 @@ var (
 	peersTotalMetric = prometheus.NewDesc(
 		prometheus.BuildFQName("dms3fs", "p2p", "peers_total"),
 		"Number of connected peers", []string{"transport"}, nil)
+
+	blockstoreSizeMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("dms3fs", "blockstore", "size_bytes"),
+		"Size of the repo storage in bytes", nil, nil)
+	blockstoreObjectsMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("dms3fs", "blockstore", "objects"),
+		"Number of blocks in the blockstore", nil, nil)
+
+	bitswapBlocksMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("dms3fs", "bitswap", "blocks_total"),
+		"Number of blocks exchanged through bitswap", []string{"direction"}, nil)
+	bitswapBytesMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("dms3fs", "bitswap", "bytes_total"),
+		"Number of block bytes exchanged through bitswap", []string{"direction"}, nil)
+	bitswapWantlistMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("dms3fs", "bitswap", "wantlist_length"),
+		"Number of blocks wanted through bitswap", nil, nil)
+
+	pinsMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("dms3fs", "pin", "pins"),
+		"Number of pins by type", []string{"type"}, nil)
 )
 
+var gatewayResponses = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "dms3fs",
+	Subsystem: "http",
+	Name:      "gateway_responses_total",
+	Help:      "Number of gateway responses by handler and status code",
+}, []string{"handler", "code"})
+
+func init() {
+	prometheus.MustRegister(gatewayResponses)
+}
+
+// gatewayHandlerName returns the name of the gateway handler serving method.
+func gatewayHandlerName(method string) string {
+	switch method {
+	case "GET", "HEAD":
+		return "get"
+	case "POST", "PUT", "DELETE", "OPTIONS":
+		return strings.ToLower(method)
+	default:
+		return "unsupported"
+	}
+}
+
+// statusRecorder remembers the status code of the response written through
+// it.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	if sr.code == 0 {
+		sr.code = code
+	}
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+func (sr *statusRecorder) Write(p []byte) (int, error) {
+	if sr.code == 0 {
+		sr.code = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(p)
+}
+
+// status returns the status code of the response, which is 200 when the
+// handler wrote nothing.
+func (sr *statusRecorder) status() int {
+	if sr.code == 0 {
+		return http.StatusOK
+	}
+	return sr.code
+}
+
+func (sr *statusRecorder) Flush() {
+	if f, ok := sr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (sr *statusRecorder) CloseNotify() <-chan bool {
+	if cn, ok := sr.ResponseWriter.(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+	return nil
+}
+
 type Dms3FsNodeCollector struct {
 	Node *core.Dms3FsNode
 }
 
 func (_ Dms3FsNodeCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- peersTotalMetric
+	ch <- blockstoreSizeMetric
+	ch <- blockstoreObjectsMetric
+	ch <- bitswapBlocksMetric
+	ch <- bitswapBytesMetric
+	ch <- bitswapWantlistMetric
+	ch <- pinsMetric
 }
 
 func (c Dms3FsNodeCollector) Collect(ch chan<- prometheus.Metric) {
@@ -49,6 +146,132 @@ func (c Dms3FsNodeCollector) Collect(ch chan<- prometheus.Metric) {
 			tr,
 		)
 	}
+
+	c.collectBlockstore(ch)
+	c.collectBitswap(ch)
+	c.collectPins(ch)
+}
+
+// blockCountPeriod is how often the number of blocks of a node is counted
+// again, since counting them takes a walk over the whole blockstore.
+var blockCountPeriod = 5 * time.Minute
+
+// blockCount caches the number of blocks of a node between the scrapes
+type blockCount struct {
+	mu       sync.Mutex
+	count    int
+	counted  bool
+	counting bool
+	last     time.Time
+}
+
+var blockCounts = struct {
+	sync.Mutex
+	m map[*core.Dms3FsNode]*blockCount
+}{m: make(map[*core.Dms3FsNode]*blockCount)}
+
+// cachedBlockCount returns the last number of blocks counted in the
+// blockstore of n, and whether there is one. It starts counting them again
+// in the background once the count is older than blockCountPeriod.
+func cachedBlockCount(n *core.Dms3FsNode) (int, bool) {
+	blockCounts.Lock()
+	bc, ok := blockCounts.m[n]
+	if !ok {
+		bc = new(blockCount)
+		blockCounts.m[n] = bc
+		go func() {
+			<-n.Context().Done()
+			blockCounts.Lock()
+			delete(blockCounts.m, n)
+			blockCounts.Unlock()
+		}()
+	}
+	blockCounts.Unlock()
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if !bc.counting && time.Since(bc.last) >= blockCountPeriod {
+		bc.counting = true
+		bc.last = time.Now()
+		go bc.recount(n)
+	}
+	return bc.count, bc.counted
+}
+
+func (bc *blockCount) recount(n *core.Dms3FsNode) {
+	count, err := countBlocks(n)
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.counting = false
+	if err != nil {
+		log.Debugf("metrics: failed to list the blockstore: %s", err)
+		return
+	}
+	bc.count, bc.counted = count, true
+}
+
+func countBlocks(n *core.Dms3FsNode) (int, error) {
+	keys, err := n.Blockstore.AllKeysChan(n.Context())
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for range keys {
+		count++
+	}
+	return count, n.Context().Err()
+}
+
+// collectBlockstore reports the size of the repo and the number of blocks
+// it holds. The blocks are counted in the background, and are not reported
+// until the first count is done.
+func (c Dms3FsNodeCollector) collectBlockstore(ch chan<- prometheus.Metric) {
+	if size, err := c.Node.Repo.GetStorageUsage(); err == nil {
+		ch <- prometheus.MustNewConstMetric(blockstoreSizeMetric, prometheus.GaugeValue, float64(size))
+	} else {
+		log.Debugf("metrics: failed to get the storage usage: %s", err)
+	}
+
+	if count, ok := cachedBlockCount(c.Node); ok {
+		ch <- prometheus.MustNewConstMetric(blockstoreObjectsMetric, prometheus.GaugeValue, float64(count))
+	}
+}
+
+// collectBitswap reports the bitswap counters of online nodes.
+func (c Dms3FsNodeCollector) collectBitswap(ch chan<- prometheus.Metric) {
+	bs, ok := c.Node.Exchange.(*bitswap.Bitswap)
+	if !ok {
+		return
+	}
+	st, err := bs.Stat()
+	if err != nil {
+		log.Debugf("metrics: failed to get the bitswap stats: %s", err)
+		return
+	}
+
+	for _, v := range []struct {
+		direction     string
+		blocks, bytes uint64
+	}{
+		{"sent", st.BlocksSent, st.DataSent},
+		{"received", st.BlocksReceived, st.DataReceived},
+		{"duplicate", st.DupBlksReceived, st.DupDataReceived},
+	} {
+		ch <- prometheus.MustNewConstMetric(bitswapBlocksMetric, prometheus.CounterValue, float64(v.blocks), v.direction)
+		ch <- prometheus.MustNewConstMetric(bitswapBytesMetric, prometheus.CounterValue, float64(v.bytes), v.direction)
+	}
+	ch <- prometheus.MustNewConstMetric(bitswapWantlistMetric, prometheus.GaugeValue, float64(len(st.Wantlist)))
+}
+
+// collectPins reports the number of direct and recursive pins. Indirect pins
+// would take a walk over every recursively pinned DAG, and are left out.
+func (c Dms3FsNodeCollector) collectPins(ch chan<- prometheus.Metric) {
+	if c.Node.Pinning == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(pinsMetric, prometheus.GaugeValue, float64(len(c.Node.Pinning.DirectKeys())), "direct")
+	ch <- prometheus.MustNewConstMetric(pinsMetric, prometheus.GaugeValue, float64(len(c.Node.Pinning.RecursiveKeys())), "recursive")
 }
 
 func (c Dms3FsNodeCollector) PeersTotalValues() map[string]float64 {
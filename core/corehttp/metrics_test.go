@@ -2,14 +2,21 @@ package corehttp
 
 import (
 	"context"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	core "github.com/dms3-fs/go-dms3-fs/core"
+	coreunix "github.com/dms3-fs/go-dms3-fs/core/coreunix"
+	coremock "github.com/dms3-fs/go-dms3-fs/core/mock"
+	pin "github.com/dms3-fs/go-dms3-fs/pin"
 
+	cid "github.com/dms3-fs/go-cid"
 	inet "github.com/dms3-p2p/go-p2p-net"
 	swarmt "github.com/dms3-p2p/go-p2p-swarm/testing"
 	bhost "github.com/dms3-p2p/go-p2p/p2p/host/basic"
+	prometheus "github.com/gxed/client_golang/prometheus"
 )
 
 // This test is based on go-p2p/p2p/net/swarm.TestConnectednessCorrect
@@ -48,3 +55,126 @@ func TestPeersTotal(t *testing.T) {
 		t.Fatalf("expected 3 peers, got %f", actual["/ip4/tcp"])
 	}
 }
+
+// gatheredValue returns the value of the metric called name with the given
+// label name and value pairs, as gathered from g, and whether it was found.
+func gatheredValue(t *testing.T, g prometheus.Gatherer, name string, labels ...string) (float64, bool) {
+	t.Helper()
+
+	mfs, err := g.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range mf.GetMetric() {
+			for j := 0; j+1 < len(labels); j += 2 {
+				found := false
+				for _, l := range m.GetLabel() {
+					if l.GetName() == labels[j] && l.GetValue() == labels[j+1] {
+						found = true
+					}
+				}
+				if !found {
+					continue metrics
+				}
+			}
+			return m.GetCounter().GetValue() + m.GetGauge().GetValue(), true
+		}
+	}
+	return 0, false
+}
+
+func TestNodeCollector(t *testing.T) {
+	n, err := coremock.NewMockNode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close()
+
+	k, err := coreunix.Add(n, strings.NewReader("fnord"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := cid.Decode(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Pinning.PinWithMode(c, pin.Direct)
+	if err := n.Pinning.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(Dms3FsNodeCollector{Node: n})
+
+	// the blocks are counted in the background from the first scrape on
+	for i := 0; ; i++ {
+		if _, ok := gatheredValue(t, reg, "dms3fs_blockstore_objects"); ok {
+			break
+		}
+		if i == 50 {
+			t.Fatal("the blocks were never counted")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	for _, test := range []struct {
+		name   string
+		labels []string
+		min    float64
+	}{
+		{"dms3fs_blockstore_size_bytes", nil, 0},
+		{"dms3fs_blockstore_objects", nil, 1},
+		{"dms3fs_bitswap_blocks_total", []string{"direction", "received"}, 0},
+		{"dms3fs_bitswap_bytes_total", []string{"direction", "sent"}, 0},
+		{"dms3fs_bitswap_blocks_total", []string{"direction", "duplicate"}, 0},
+		{"dms3fs_bitswap_wantlist_length", nil, 0},
+		{"dms3fs_pin_pins", []string{"type", "direct"}, 1},
+		{"dms3fs_pin_pins", []string{"type", "recursive"}, 0},
+	} {
+		v, ok := gatheredValue(t, reg, test.name, test.labels...)
+		if !ok {
+			t.Errorf("%s%v was not collected", test.name, test.labels)
+			continue
+		}
+		if v < test.min {
+			t.Errorf("%s%v is %v, expected at least %v", test.name, test.labels, v, test.min)
+		}
+	}
+}
+
+func TestGatewayResponsesMetric(t *testing.T) {
+	ns := mockNamesys{}
+	ts, n := newTestServerAndNode(t, ns)
+	defer ts.Close()
+
+	k, err := coreunix.Add(n, strings.NewReader("fnord"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		path string
+		code string
+	}{
+		{"/dms3fs/" + k, "200"},
+		{"/dms3ns/nxdomain.example.com", "404"},
+	} {
+		before, _ := gatheredValue(t, prometheus.DefaultGatherer, "dms3fs_http_gateway_responses_total", "handler", "get", "code", test.code)
+
+		res, err := http.Get(ts.URL + test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		after, _ := gatheredValue(t, prometheus.DefaultGatherer, "dms3fs_http_gateway_responses_total", "handler", "get", "code", test.code)
+		if after != before+1 {
+			t.Errorf("%s: expected %v responses with code %s, got %v", test.path, before+1, test.code, after)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package corehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	core "github.com/dms3-fs/go-dms3-fs/core"
+
+	prometheus "github.com/gxed/client_golang/prometheus"
+)
+
+// rateLimitIdleTimeout is how long the state of a client without requests
+// in flight is kept after its last request.
+const rateLimitIdleTimeout = time.Minute
+
+var (
+	rateLimitRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dms3fs",
+		Subsystem: "http",
+		Name:      "ratelimit_rejected_total",
+		Help:      "Number of requests rejected by the rate limiter",
+	}, []string{"reason"})
+
+	rateLimitInflight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "dms3fs",
+		Subsystem: "http",
+		Name:      "ratelimit_inflight_requests",
+		Help:      "Number of requests admitted by the rate limiter being served",
+	})
+
+	rateLimitClients = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "dms3fs",
+		Subsystem: "http",
+		Name:      "ratelimit_clients",
+		Help:      "Number of clients tracked by the rate limiter",
+	})
+
+	rateLimitThrottled = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "dms3fs",
+		Subsystem: "http",
+		Name:      "ratelimit_throttled_seconds_total",
+		Help:      "Time responses were held back to keep clients under their bandwidth limit",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(rateLimitRejected, rateLimitInflight, rateLimitClients, rateLimitThrottled)
+}
+
+// RateLimitConfig holds the limits applied to each client, identified by its
+// IP address. A zero value disables the limit.
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained request rate of a client, and Burst
+	// the number of requests it may make at once.
+	RequestsPerSecond float64
+	Burst             int
+
+	// MaxConcurrent caps the requests of a client served at the same time.
+	MaxConcurrent int
+
+	// BytesPerSecond caps the rate at which responses are sent to a client.
+	BytesPerSecond int
+}
+
+// LoadRateLimitConfig reads a JSON encoded RateLimitConfig from the file at
+// path.
+func LoadRateLimitConfig(path string) (*RateLimitConfig, error) {
+	cfg := new(RateLimitConfig)
+	if err := loadGatewayFile(path, "gateway rate limits", cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (cfg *RateLimitConfig) validate() error {
+	if cfg.RequestsPerSecond < 0 || cfg.Burst < 0 || cfg.MaxConcurrent < 0 || cfg.BytesPerSecond < 0 {
+		return errors.New("limits can not be negative")
+	}
+	return nil
+}
+
+// RateLimitOption limits the requests of each client to the handlers below
+// it. Requests over the request rate or concurrency limits are rejected with
+// 429 and a Retry-After header, responses over the bandwidth limit are slowed
+// down.
+func RateLimitOption(cfg RateLimitConfig) ServeOption {
+	return func(_ *core.Dms3FsNode, _ net.Listener, mux *http.ServeMux) (*http.ServeMux, error) {
+		childMux := http.NewServeMux()
+		mux.Handle("/", newRateLimiter(cfg, childMux))
+		return childMux, nil
+	}
+}
+
+type rateLimiter struct {
+	cfg  RateLimitConfig
+	next http.Handler
+
+	mu        sync.Mutex
+	clients   map[string]*rateLimitClient
+	lastSweep time.Time
+}
+
+type rateLimitClient struct {
+	requests *tokenBucket
+	bytes    *tokenBucket
+	inflight int
+	lastSeen time.Time
+}
+
+func newRateLimiter(cfg RateLimitConfig, next http.Handler) *rateLimiter {
+	// without a burst, a client may make one second worth of requests at once
+	if cfg.Burst < 1 {
+		cfg.Burst = int(math.Ceil(cfg.RequestsPerSecond))
+	}
+
+	return &rateLimiter{
+		cfg:       cfg,
+		next:      next,
+		clients:   make(map[string]*rateLimitClient),
+		lastSweep: time.Now(),
+	}
+}
+
+func (rl *rateLimiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+
+	c, retry, reason := rl.acquire(ip)
+	if c == nil {
+		rateLimitRejected.WithLabelValues(reason).Inc()
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+		webErrorWithCode(w, "rate limited", fmt.Errorf("%s exceeded its %s limit", ip, reason), http.StatusTooManyRequests)
+		return
+	}
+	defer rl.release(c)
+
+	rateLimitInflight.Inc()
+	defer rateLimitInflight.Dec()
+
+	if c.bytes != nil {
+		w = &throttledWriter{ResponseWriter: w, rl: rl, client: c, ctx: r.Context()}
+	}
+	rl.next.ServeHTTP(w, r)
+}
+
+// acquire admits a request of the client at ip, or returns the reason it is
+// rejected and when it may be retried.
+func (rl *rateLimiter) acquire(ip string) (*rateLimitClient, time.Duration, string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(rl.lastSweep) > rateLimitIdleTimeout {
+		rl.sweep(now)
+	}
+
+	c, ok := rl.clients[ip]
+	if !ok {
+		c = &rateLimitClient{}
+		if rl.cfg.RequestsPerSecond > 0 {
+			c.requests = newTokenBucket(rl.cfg.RequestsPerSecond, float64(rl.cfg.Burst), now)
+		}
+		if rl.cfg.BytesPerSecond > 0 {
+			c.bytes = newTokenBucket(float64(rl.cfg.BytesPerSecond), float64(rl.cfg.BytesPerSecond), now)
+		}
+		rl.clients[ip] = c
+		rateLimitClients.Inc()
+	}
+	c.lastSeen = now
+
+	if rl.cfg.MaxConcurrent > 0 && c.inflight >= rl.cfg.MaxConcurrent {
+		return nil, time.Second, "concurrency"
+	}
+	if c.requests != nil {
+		if wait := c.requests.take(now, 1); wait > 0 {
+			return nil, wait, "rate"
+		}
+	}
+
+	c.inflight++
+	return c, 0, ""
+}
+
+func (rl *rateLimiter) release(c *rateLimitClient) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	c.inflight--
+	c.lastSeen = time.Now()
+}
+
+// sweep forgets the clients idle for longer than rateLimitIdleTimeout.
+func (rl *rateLimiter) sweep(now time.Time) {
+	for ip, c := range rl.clients {
+		if c.inflight == 0 && now.Sub(c.lastSeen) > rateLimitIdleTimeout {
+			delete(rl.clients, ip)
+			rateLimitClients.Dec()
+		}
+	}
+	rl.lastSweep = now
+}
+
+// reserveBytes takes n bytes from the bandwidth of the client, and returns
+// how long the client has to wait before they may be sent.
+func (rl *rateLimiter) reserveBytes(c *rateLimitClient, n int) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	return c.bytes.reserve(time.Now(), float64(n))
+}
+
+// tokenBucket holds up to burst tokens, refilled at rate tokens per second.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate, burst float64, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// take removes n tokens if the bucket holds them, and otherwise returns how
+// long it takes until it does.
+func (b *tokenBucket) take(now time.Time, n float64) time.Duration {
+	b.refill(now)
+	if b.tokens >= n {
+		b.tokens -= n
+		return 0
+	}
+	return time.Duration((n - b.tokens) / b.rate * float64(time.Second))
+}
+
+// reserve removes n tokens, going into debt if the bucket does not hold them,
+// and returns how long it takes until the debt is repaid.
+func (b *tokenBucket) reserve(now time.Time, n float64) time.Duration {
+	b.refill(now)
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// throttledWriter keeps the responses of a client under its bandwidth limit.
+type throttledWriter struct {
+	http.ResponseWriter
+	rl     *rateLimiter
+	client *rateLimitClient
+	ctx    context.Context
+}
+
+func (tw *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if max := tw.rl.cfg.BytesPerSecond; len(chunk) > max {
+			chunk = chunk[:max]
+		}
+
+		if wait := tw.rl.reserveBytes(tw.client, len(chunk)); wait > 0 {
+			rateLimitThrottled.Add(wait.Seconds())
+
+			t := time.NewTimer(wait)
+			select {
+			case <-t.C:
+			case <-tw.ctx.Done():
+				t.Stop()
+				return written, tw.ctx.Err()
+			}
+		}
+
+		n, err := tw.ResponseWriter.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+func (tw *throttledWriter) Flush() {
+	if f, ok := tw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (tw *throttledWriter) CloseNotify() <-chan bool {
+	if cn, ok := tw.ResponseWriter.(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package corehttp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func rateLimitedRequest(h http.Handler, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/dms3fs/", nil)
+	req.RemoteAddr = ip + ":1234"
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimitRequests(t *testing.T) {
+	rl := newRateLimiter(RateLimitConfig{RequestsPerSecond: 0.5, Burst: 2}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for i := 0; i < 2; i++ {
+		if rec := rateLimitedRequest(rl, "10.0.0.1"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status is %d, expected %d", i, rec.Code, http.StatusOK)
+		}
+	}
+
+	rec := rateLimitedRequest(rl, "10.0.0.1")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status is %d, expected %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if ra := rec.Header().Get("Retry-After"); ra != "2" {
+		t.Fatalf("Retry-After is %q, expected \"2\"", ra)
+	}
+
+	// the limits are per client
+	if rec := rateLimitedRequest(rl, "10.0.0.2"); rec.Code != http.StatusOK {
+		t.Fatalf("other client: status is %d, expected %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimitConcurrency(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	rl := newRateLimiter(RateLimitConfig{MaxConcurrent: 1}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+
+	done := make(chan int)
+	go func() {
+		done <- rateLimitedRequest(rl, "10.0.0.1").Code
+	}()
+	<-started
+
+	rec := rateLimitedRequest(rl, "10.0.0.1")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("status is %d, expected %d with a Retry-After header", rec.Code, http.StatusTooManyRequests)
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusOK {
+		t.Fatalf("first request: status is %d, expected %d", code, http.StatusOK)
+	}
+
+	go func() { <-started }()
+	if rec := rateLimitedRequest(rl, "10.0.0.1"); rec.Code != http.StatusOK {
+		t.Fatalf("after the first request: status is %d, expected %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimitBandwidth(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 1500)
+	rl := newRateLimiter(RateLimitConfig{BytesPerSecond: 1000}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+
+	start := time.Now()
+	rec := rateLimitedRequest(rl, "10.0.0.1")
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Fatalf("1500 bytes were sent in %s at 1000 bytes per second", elapsed)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), data) {
+		t.Fatal("response was not sent in full")
+	}
+}
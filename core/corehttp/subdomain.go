@@ -0,0 +1,207 @@
+package corehttp
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	core "github.com/dms3-fs/go-dms3-fs/core"
+
+	cid "github.com/dms3-fs/go-cid"
+	multibase "github.com/dms3-mft/go-multibase"
+	peer "github.com/dms3-p2p/go-p2p-peer"
+	isd "github.com/jbenet/go-is-domain"
+)
+
+// dms3p2pKeyCodec is the multicodec of the CIDs peer IDs are encoded as in
+// DMS3NS subdomains, since base58 peer IDs do not survive the lowercasing of
+// hostnames.
+const dms3p2pKeyCodec = 0x72
+
+// maxLabelLen is the maximum length of a DNS label.
+const maxLabelLen = 63
+
+// subdomainRewrittenKey marks, in their context, the requests rewritten by
+// SubdomainGatewayOption.
+type subdomainRewrittenKey struct{}
+
+// subdomainRewritten reports whether r was rewritten by
+// SubdomainGatewayOption.
+func subdomainRewritten(r *http.Request) bool {
+	rewritten, _ := r.Context().Value(subdomainRewrittenKey{}).(bool)
+	return rewritten
+}
+
+// SubdomainGatewayOption serves DMS3FS and DMS3NS content from per-root
+// origins below the given gateway hostnames, so that sites can not read each
+// other's cookies or storage:
+//
+//	http://<cidv1-base32>.dms3fs.<hostname>/<path> serves /dms3fs/<cid>/<path>
+//	http://<name>.dms3ns.<hostname>/<path> serves /dms3ns/<name>/<path>
+//
+// Path-style requests for /dms3fs/ and /dms3ns/ on the gateway hostnames are
+// redirected to their subdomain form. Peer IDs are encoded as CIDv1 and
+// DNSLink names have their dashes doubled and their dots turned into dashes.
+func SubdomainGatewayOption(hostnames ...string) ServeOption {
+	return func(n *core.Dms3FsNode, _ net.Listener, mux *http.ServeMux) (*http.ServeMux, error) {
+		childMux := http.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			host := strings.SplitN(r.Host, ":", 2)[0]
+			for _, gw := range hostnames {
+				if host == gw {
+					if to, ok := subdomainURL(r, gw, r.URL.Path); ok {
+						http.Redirect(w, r, to, http.StatusMovedPermanently)
+						return
+					}
+					break
+				}
+
+				ns, label, ok := splitSubdomainHost(host, gw)
+				if !ok {
+					continue
+				}
+
+				root, err := subdomainRoot(ns, label)
+				if err != nil {
+					webError(w, "invalid subdomain "+label, err, http.StatusBadRequest)
+					return
+				}
+
+				// other encodings of the same root are redirected to the
+				// canonical origin
+				if canon, ok := subdomainLabel(ns, root); ok && canon != label {
+					to, _ := subdomainURL(r, gw, "/"+ns+"/"+root+r.URL.Path)
+					http.Redirect(w, r, to, http.StatusMovedPermanently)
+					return
+				}
+
+				r.Header.Set("X-Dms3Ns-Original-Path", r.URL.Path)
+				r.URL.Path = "/" + ns + "/" + root + r.URL.Path
+				r = r.WithContext(context.WithValue(r.Context(), subdomainRewrittenKey{}, true))
+				break
+			}
+			childMux.ServeHTTP(w, r)
+		})
+		return childMux, nil
+	}
+}
+
+// splitSubdomainHost splits host of the form <label>.<ns>.<gw> into its
+// namespace and label.
+func splitSubdomainHost(host, gw string) (string, string, bool) {
+	for _, ns := range []string{"dms3fs", "dms3ns"} {
+		suffix := "." + ns + "." + gw
+		if strings.HasSuffix(host, suffix) {
+			label := strings.TrimSuffix(host, suffix)
+			if label == "" || strings.Contains(label, ".") {
+				return "", "", false
+			}
+			return ns, label, true
+		}
+	}
+	return "", "", false
+}
+
+// subdomainRoot returns the CID or DMS3NS name a subdomain label stands for.
+func subdomainRoot(ns, label string) (string, error) {
+	c, err := cid.Decode(label)
+	switch {
+	case ns == "dms3fs" && err != nil:
+		return "", err
+	case ns == "dms3fs":
+		return c.String(), nil
+	case err == nil && c.Type() == dms3p2pKeyCodec:
+		return peer.ID(c.Hash()).Pretty(), nil
+	default:
+		return decodeDNSLinkLabel(label), nil
+	}
+}
+
+// subdomainURL returns the subdomain URL of the /dms3fs/ or /dms3ns/ path p
+// below the gateway hostname gw.
+func subdomainURL(r *http.Request, gw string, p string) (string, bool) {
+	parts := strings.SplitN(p, "/", 4)
+	if len(parts) < 3 || parts[0] != "" || parts[2] == "" {
+		return "", false
+	}
+
+	label, ok := subdomainLabel(parts[1], parts[2])
+	if !ok {
+		return "", false
+	}
+
+	host := label + "." + parts[1] + "." + gw
+	if _, port, err := net.SplitHostPort(r.Host); err == nil {
+		host = net.JoinHostPort(host, port)
+	}
+
+	u := requestScheme(r) + "://" + host + "/"
+	if len(parts) == 4 {
+		u += parts[3]
+	}
+	if r.URL.RawQuery != "" {
+		u += "?" + r.URL.RawQuery
+	}
+	return u, true
+}
+
+// subdomainLabel returns the canonical subdomain label of the root of a
+// /dms3fs/ or /dms3ns/ path: CIDv1 in base32 for CIDs and peer IDs, and the
+// encoded name for DNSLink names.
+func subdomainLabel(ns, root string) (string, bool) {
+	var c *cid.Cid
+	switch ns {
+	case "dms3fs":
+		dc, err := cid.Decode(root)
+		if err != nil {
+			return "", false
+		}
+		c = cid.NewCidV1(dc.Type(), dc.Hash())
+	case "dms3ns":
+		id, err := peer.IDB58Decode(root)
+		if err != nil {
+			if !isd.IsDomain(root) {
+				return "", false
+			}
+			label := encodeDNSLinkLabel(root)
+			return label, len(label) <= maxLabelLen
+		}
+		c = cid.NewCidV1(dms3p2pKeyCodec, []byte(id))
+	default:
+		return "", false
+	}
+
+	label, err := multibase.Encode(multibase.Base32, c.Bytes())
+	if err != nil {
+		return "", false
+	}
+	return label, len(label) <= maxLabelLen
+}
+
+// requestScheme returns the scheme the client used to reach the gateway,
+// which may be terminated by a proxy.
+func requestScheme(r *http.Request) string {
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		return proto
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// encodeDNSLinkLabel turns a DNSLink name into a single DNS label: dashes
+// are doubled and dots become dashes.
+func encodeDNSLinkLabel(name string) string {
+	return strings.Replace(strings.Replace(name, "-", "--", -1), ".", "-", -1)
+}
+
+// decodeDNSLinkLabel reverses encodeDNSLinkLabel.
+func decodeDNSLinkLabel(label string) string {
+	parts := strings.Split(label, "--")
+	for i, p := range parts {
+		parts[i] = strings.Replace(p, "-", ".", -1)
+	}
+	return strings.Join(parts, "-")
+}
@@ -0,0 +1,53 @@
+package corehttp
+
+import (
+	"net/http"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	ext "github.com/opentracing/opentracing-go/ext"
+)
+
+// startRequestSpan starts the span of a request to the HTTP server, as a
+// child of the span the client propagated in the request headers, if any.
+// The span is created by the global tracer, which a tracer plugin may set.
+func startRequestSpan(r *http.Request, operation string) opentracing.Span {
+	tracer := opentracing.GlobalTracer()
+
+	parent, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header))
+	if err != nil {
+		if err != opentracing.ErrSpanContextNotFound {
+			log.Debugf("invalid span context in the headers of %s: %s", r.URL.Path, err)
+		}
+		parent = nil
+	}
+
+	span := tracer.StartSpan(operation, ext.RPCServerOption(parent))
+	ext.HTTPMethod.Set(span, r.Method)
+	ext.HTTPUrl.Set(span, r.URL.String())
+	return span
+}
+
+// finishRequestSpan records the status of the response in the span of a
+// request and finishes it.
+func finishRequestSpan(span opentracing.Span, status int) {
+	ext.HTTPStatusCode.Set(span, uint16(status))
+	if status >= http.StatusInternalServerError {
+		ext.Error.Set(span, true)
+	}
+	span.Finish()
+}
+
+// tracedHandler serves each request with next in a span named by operation,
+// carried by the context of the request so that the spans of the work done
+// for it are its children.
+func tracedHandler(operation func(r *http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := startRequestSpan(r, operation(r))
+		sr := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			finishRequestSpan(span, sr.status())
+		}()
+
+		next.ServeHTTP(sr, r.WithContext(opentracing.ContextWithSpan(r.Context(), span)))
+	})
+}
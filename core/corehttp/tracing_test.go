@@ -0,0 +1,162 @@
+package corehttp
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	oldcmds "github.com/dms3-fs/go-dms3-fs/commands"
+	core "github.com/dms3-fs/go-dms3-fs/core"
+	coreunix "github.com/dms3-fs/go-dms3-fs/core/coreunix"
+
+	cmdkit "github.com/dms3-fs/go-fs-cmdkit"
+	cmds "github.com/dms3-fs/go-fs-cmds"
+	opentracing "github.com/opentracing/opentracing-go"
+	mocktracer "github.com/opentracing/opentracing-go/mocktracer"
+)
+
+// useMockTracer makes a mock tracer the global tracer until the returned
+// function is called.
+func useMockTracer() (*mocktracer.MockTracer, func()) {
+	prev := opentracing.GlobalTracer()
+	tracer := mocktracer.New()
+	opentracing.SetGlobalTracer(tracer)
+	return tracer, func() {
+		opentracing.SetGlobalTracer(prev)
+	}
+}
+
+func finishedSpan(t *testing.T, tracer *mocktracer.MockTracer, operation string) *mocktracer.MockSpan {
+	for _, s := range tracer.FinishedSpans() {
+		if s.OperationName == operation {
+			return s
+		}
+	}
+	t.Fatalf("no finished span %s", operation)
+	return nil
+}
+
+func TestGatewayTracing(t *testing.T) {
+	ns := mockNamesys{}
+	ts, n := newTestServerAndNode(t, ns)
+	defer ts.Close()
+
+	k, err := coreunix.Add(n, strings.NewReader("fnord"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracer, restore := useMockTracer()
+	defer restore()
+
+	client := tracer.StartSpan("client")
+	req, err := http.NewRequest("GET", ts.URL+"/dms3fs/"+k, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracer.Inject(client.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	client.Finish()
+
+	clientCtx := client.Context().(mocktracer.MockSpanContext)
+
+	gw := finishedSpan(t, tracer, "gateway.get")
+	if gw.ParentID != clientCtx.SpanID || gw.SpanContext.TraceID != clientCtx.TraceID {
+		t.Errorf("gateway span is not a child of the client span")
+	}
+	if code := gw.Tag("http.status_code"); code != uint16(http.StatusOK) {
+		t.Errorf("expected status code 200 on the gateway span, got %v", code)
+	}
+
+	resolve := finishedSpan(t, tracer, "coreapi.ResolvePath")
+	if resolve.ParentID != gw.SpanContext.SpanID {
+		t.Errorf("path resolution span is not a child of the gateway span")
+	}
+	if p := resolve.Tag("path"); p != "/dms3fs/"+k {
+		t.Errorf("expected path /dms3fs/%s on the resolution span, got %v", k, p)
+	}
+}
+
+func TestCommandsTracing(t *testing.T) {
+	n, err := newNodeWithMockNamesys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a failing command opening a span from the context of its request
+	root := &cmds.Command{
+		Subcommands: map[string]*cmds.Command{
+			"traced": {
+				Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) {
+					span, _ := opentracing.StartSpanFromContext(req.Context, "command")
+					span.Finish()
+					res.SetError(errors.New("traced command failed"), cmdkit.ErrNormal)
+				},
+			},
+		},
+	}
+
+	ts := httptest.NewUnstartedServer(nil)
+	cctx := oldcmds.Context{
+		ReqLog: &oldcmds.ReqLog{},
+		ConstructNode: func() (*core.Dms3FsNode, error) {
+			return n, nil
+		},
+	}
+	mux, err := commandsOption(cctx, root)(n, ts.Listener, http.NewServeMux())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.Config.Handler = mux
+	ts.Start()
+	defer ts.Close()
+
+	tracer, restore := useMockTracer()
+	defer restore()
+
+	client := tracer.StartSpan("client")
+	req, err := http.NewRequest("POST", ts.URL+APIPath+"/traced", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tracer.Inject(client.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	client.Finish()
+
+	clientCtx := client.Context().(mocktracer.MockSpanContext)
+
+	api := finishedSpan(t, tracer, "api.traced")
+	if api.ParentID != clientCtx.SpanID {
+		t.Errorf("api span is not a child of the client span")
+	}
+	if api.Tag("error") != true {
+		t.Errorf("expected the api span of a failed request to be tagged as an error")
+	}
+
+	// the command only sees the api span if the request context of the
+	// command is built from the context of the http request
+	cmd := finishedSpan(t, tracer, "command")
+	if cmd.ParentID != api.SpanContext.SpanID {
+		t.Errorf("command span is not a child of the api span")
+	}
+}
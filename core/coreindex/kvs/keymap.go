@@ -6,6 +6,7 @@ import (
     "path"
     "strconv"
 
+    cid "github.com/dms3-fs/go-cid"
     ds "github.com/dms3-fs/go-datastore"
 )
 
@@ -17,9 +18,15 @@ const rootPrefix = "/index/reposet"
 //
 // corpus key convention
 // 	  - <index>/reposet/<type>/<kind>/<name>/<reponame>/corpus
+// 	  - <index>/reposet/<type>/<kind>/<name>/corpus/<cid>
 //
 const corpusDocPrefix = "/corpus"
 
+// GetRepoSetPrefix returns the key prefix shared by all reposet keys.
+func GetRepoSetPrefix() ds.Key {
+    return ds.NewKey(rootPrefix)
+}
+
 func GetRepoSetKey(t, k, n string) (ds.Key, error) {
     key := ds.NewKey(path.Join(rootPrefix, t, k, n))
     return key, nil
@@ -64,3 +71,18 @@ func GetDocKey(rc string, rn string, ri int64, di int64) (ds.Key, error) {
     key := ds.NewKey(path.Join(rootPrefix, rc, rn, strconv.FormatInt(ri, 10), corpusDocPrefix, strconv.FormatInt(di, 10)))
     return key, nil
 }
+
+// GetCorpusPrefix returns the key prefix of the documents added to a reposet.
+func GetCorpusPrefix(t, k, n string) (ds.Key, error) {
+    key := ds.NewKey(path.Join(rootPrefix, t, k, n, corpusDocPrefix))
+    return key, nil
+}
+
+// GetCorpusKey returns the key tracking document c in a reposet.
+func GetCorpusKey(t, k, n string, c *cid.Cid) (ds.Key, error) {
+    if c == nil {
+        return ds.Key{}, errors.New("document cid must not be nil")
+    }
+    key := ds.NewKey(path.Join(rootPrefix, t, k, n, corpusDocPrefix, c.String()))
+    return key, nil
+}
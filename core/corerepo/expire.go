@@ -0,0 +1,67 @@
+package corerepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/dms3-fs/go-dms3-fs/core"
+	pin "github.com/dms3-fs/go-dms3-fs/pin"
+
+	cid "github.com/dms3-fs/go-cid"
+)
+
+// PinExpiryPeriod is how often PeriodicUnpinExpired looks for expired pins
+var PinExpiryPeriod = time.Minute
+
+// UnpinExpired releases the references to pins which expired, and returns
+// the cids of the pins removed because they had no reference left. The pin
+// lock is held from the first release until the pins are flushed, so that a
+// garbage collection runs either before or after all of them are removed.
+func UnpinExpired(n *core.Dms3FsNode, ctx context.Context) ([]*cid.Cid, error) {
+	defer n.Blockstore.PinLock().Unlock()
+
+	expired := n.Pinning.Expired(time.Now())
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	var unpinned []*cid.Cid
+	for _, ref := range expired {
+		released, err := n.Pinning.Release(ctx, ref.Cid, ref.Owner, true)
+		switch {
+		case err == pin.ErrNotPinned:
+		case err != nil:
+			log.Errorf("releasing expired pin %s: %s", ref.Cid, err)
+		case released:
+			unpinned = append(unpinned, ref.Cid)
+		}
+	}
+
+	if err := n.Pinning.Flush(); err != nil {
+		return nil, err
+	}
+	return unpinned, nil
+}
+
+// PeriodicUnpinExpired releases the expired pins every PinExpiryPeriod until
+// the context is done.
+func PeriodicUnpinExpired(ctx context.Context, node *core.Dms3FsNode) error {
+	ticker := time.NewTicker(PinExpiryPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			unpinned, err := UnpinExpired(node, ctx)
+			if err != nil {
+				log.Error(err)
+				continue
+			}
+			for _, c := range unpinned {
+				log.Infof("unpinned expired %s", c)
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package corerepo
+
+import (
+	"os"
+	"path/filepath"
+
+	fsrepo "github.com/dms3-fs/go-dms3-fs/repo/fsrepo"
+
+	config "github.com/dms3-fs/go-fs-config"
+)
+
+// Fsck removes the repo and datastore lockfiles, as well as the api file, of
+// the repo at configRoot. It must only be called when no daemon is using the
+// repo.
+func Fsck(configRoot string) error {
+	dsPath, err := config.DataStorePath(configRoot)
+	if err != nil {
+		return err
+	}
+
+	dsLockFile := filepath.Join(dsPath, "LOCK") // TODO: get this lockfile programmatically
+	repoLockFile := filepath.Join(configRoot, fsrepo.LockFile)
+	apiFile := filepath.Join(configRoot, "api") // TODO: get this programmatically
+
+	log.Infof("Removing repo lockfile: %s", repoLockFile)
+	log.Infof("Removing datastore lockfile: %s", dsLockFile)
+	log.Infof("Removing api file: %s", apiFile)
+
+	for _, f := range []string{repoLockFile, dsLockFile, apiFile} {
+		err := os.Remove(f)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
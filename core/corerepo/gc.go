@@ -148,7 +148,7 @@ func (e *MultiError) Error() string {
 func GarbageCollectAsync(n *core.Dms3FsNode, ctx context.Context) <-chan gc.Result {
 	roots, err := BestEffortRoots(n.FilesRoot)
 	if err != nil {
-		out := make(chan gc.Result)
+		out := make(chan gc.Result, 1)
 		out <- gc.Result{Error: err}
 		close(out)
 		return out
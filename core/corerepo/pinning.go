@@ -16,8 +16,10 @@ package corerepo
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/dms3-fs/go-dms3-fs/core"
+	pin "github.com/dms3-fs/go-dms3-fs/pin"
 	path "github.com/dms3-fs/go-path"
 	resolver "github.com/dms3-fs/go-path/resolver"
 	uio "github.com/dms3-fs/go-unixfs/io"
@@ -26,6 +28,15 @@ import (
 )
 
 func Pin(n *core.Dms3FsNode, ctx context.Context, paths []string, recursive bool) ([]*cid.Cid, error) {
+	return PinWithInfo(n, ctx, paths, recursive, pin.Info{})
+}
+
+// PinWithInfo pins the given paths like Pin, and attaches info to their pins
+// if it has a name, metadata or an expiry time. The info is merged into the
+// info of the pins which already existed. The pins are held by a reference of
+// each owner of info, or by a reference without an owner if it has none,
+// which expires at the expiry time of info.
+func PinWithInfo(n *core.Dms3FsNode, ctx context.Context, paths []string, recursive bool, info pin.Info) ([]*cid.Cid, error) {
 	out := make([]*cid.Cid, len(paths))
 
 	r := &resolver.Resolver{
@@ -43,10 +54,25 @@ func Pin(n *core.Dms3FsNode, ctx context.Context, paths []string, recursive bool
 		if err != nil {
 			return nil, fmt.Errorf("pin: %s", err)
 		}
+		existed, err := isPinned(n, dagnode.Cid())
+		if err != nil {
+			return nil, fmt.Errorf("pin: %s", err)
+		}
+		old, hasInfo := n.Pinning.Info(dagnode.Cid())
 		err = n.Pinning.Pin(ctx, dagnode, recursive)
 		if err != nil {
 			return nil, fmt.Errorf("pin: %s", err)
 		}
+		if hasInfo || existed && len(info.Owners) > 0 || info.Name != "" || len(info.Meta) > 0 || !info.Expires.IsZero() {
+			if err := n.Pinning.SetInfo(dagnode.Cid(), mergePinInfo(old, existed, info)); err != nil {
+				return nil, fmt.Errorf("pin: %s", err)
+			}
+		}
+		for _, owner := range info.Owners {
+			if err := n.Pinning.AddOwner(dagnode.Cid(), owner, info.Expires); err != nil {
+				return nil, fmt.Errorf("pin: %s", err)
+			}
+		}
 		out[i] = dagnode.Cid()
 	}
 
@@ -58,15 +84,81 @@ func Pin(n *core.Dms3FsNode, ctx context.Context, paths []string, recursive bool
 	return out, nil
 }
 
+// isPinned reports whether c is pinned directly or recursively
+func isPinned(n *core.Dms3FsNode, c *cid.Cid) (bool, error) {
+	for _, mode := range []pin.Mode{pin.Direct, pin.Recursive} {
+		_, pinned, err := n.Pinning.IsPinnedWithType(c, mode)
+		if err != nil || pinned {
+			return pinned, err
+		}
+	}
+	return false, nil
+}
+
+// mergePinInfo merges info into the info old of a pin, which existed before
+// if existed is set. The name and the metadata of info replace the ones of
+// old, and its creation time is kept. The reference holding the pin without
+// an owner is only added or renewed when info has no owners, and the expiry
+// of a reference which existed is never brought forward: it is only pushed
+// back, or removed when info does not expire.
+func mergePinInfo(old pin.Info, existed bool, info pin.Info) pin.Info {
+	unowned := len(info.Owners) == 0
+	info.Owners = nil
+	if !existed {
+		if !unowned {
+			info.Expires = time.Time{}
+		}
+		return info
+	}
+
+	merged := old
+	if info.Name != "" {
+		merged.Name = info.Name
+	}
+	if len(info.Meta) > 0 {
+		merged.Meta = make(map[string]string, len(old.Meta)+len(info.Meta))
+		for k, v := range old.Meta {
+			merged.Meta[k] = v
+		}
+		for k, v := range info.Meta {
+			merged.Meta[k] = v
+		}
+	}
+	if merged.Created.IsZero() {
+		merged.Created = info.Created
+	}
+
+	// a pin without owners was held without an owner
+	held := old.Unowned || len(old.Owners) == 0
+	merged.Unowned = held || unowned
+	switch {
+	case !unowned:
+	case !held:
+		merged.Expires = info.Expires
+	case old.Expires.IsZero(), info.Expires.IsZero():
+		merged.Expires = time.Time{}
+	case info.Expires.After(old.Expires):
+		merged.Expires = info.Expires
+	}
+	return merged
+}
+
 func Unpin(n *core.Dms3FsNode, ctx context.Context, paths []string, recursive bool) ([]*cid.Cid, error) {
-	unpinned := make([]*cid.Cid, len(paths))
+	return UnpinOwner(n, ctx, paths, recursive, "")
+}
+
+// UnpinOwner releases the pins of the given paths on behalf of owner, and
+// returns the cids which were unpinned because owner was their last owner.
+// Without an owner, the paths are unpinned like Unpin, for all their owners.
+func UnpinOwner(n *core.Dms3FsNode, ctx context.Context, paths []string, recursive bool, owner string) ([]*cid.Cid, error) {
+	unpinned := make([]*cid.Cid, 0, len(paths))
 
 	r := &resolver.Resolver{
 		DAG:         n.DAG,
 		ResolveOnce: uio.ResolveUnixfsOnce,
 	}
 
-	for i, p := range paths {
+	for _, p := range paths {
 		p, err := path.ParsePath(p)
 		if err != nil {
 			return nil, err
@@ -77,11 +169,22 @@ func Unpin(n *core.Dms3FsNode, ctx context.Context, paths []string, recursive bo
 			return nil, err
 		}
 
-		err = n.Pinning.Unpin(ctx, k, recursive)
+		if owner == "" {
+			err = n.Pinning.Unpin(ctx, k, recursive)
+			if err != nil {
+				return nil, err
+			}
+			unpinned = append(unpinned, k)
+			continue
+		}
+
+		released, err := n.Pinning.Release(ctx, k, owner, recursive)
 		if err != nil {
 			return nil, err
 		}
-		unpinned[i] = k
+		if released {
+			unpinned = append(unpinned, k)
+		}
 	}
 
 	err := n.Pinning.Flush()
@@ -26,6 +26,7 @@ import (
 	dms3ld "github.com/dms3-fs/go-ld-format"
 	logging "github.com/dms3-fs/go-log"
 	mfs "github.com/dms3-fs/go-mfs"
+	opentracing "github.com/opentracing/opentracing-go"
 )
 
 var log = logging.Logger("coreunix")
@@ -164,9 +165,25 @@ func (adder *Adder) RootNode() (dms3ld.Node, error) {
 	return root, err
 }
 
+// startSpan starts a span below the context of the adder, which the adder
+// uses instead until the returned function finishes the span, so that the
+// spans of the blocks added and fetched meanwhile are its children.
+func (adder *Adder) startSpan(operation string) (opentracing.Span, func()) {
+	parent := adder.ctx
+	span, ctx := opentracing.StartSpanFromContext(parent, operation)
+	adder.ctx = ctx
+	return span, func() {
+		adder.ctx = parent
+		span.Finish()
+	}
+}
+
 // Recursively pins the root node of Adder and
 // writes the pin state to the backing datastore.
 func (adder *Adder) PinRoot() error {
+	_, finish := adder.startSpan("coreunix.PinRoot")
+	defer finish()
+
 	root, err := adder.RootNode()
 	if err != nil {
 		return err
@@ -196,6 +213,9 @@ func (adder *Adder) PinRoot() error {
 
 // Finalize flushes the mfs root directory and returns the mfs root node.
 func (adder *Adder) Finalize() (dms3ld.Node, error) {
+	_, finish := adder.startSpan("coreunix.Finalize")
+	defer finish()
+
 	mr, err := adder.mfsRoot()
 	if err != nil {
 		return nil, err
@@ -399,6 +419,10 @@ func (adder *Adder) addNode(node dms3ld.Node, path string) error {
 
 // AddFile adds the given file while respecting the adder.
 func (adder *Adder) AddFile(file files.File) error {
+	span, finish := adder.startSpan("coreunix.AddFile")
+	defer finish()
+	span.SetTag("name", file.FileName())
+
 	if adder.Pin {
 		adder.unlocker = adder.blockstore.PinLock()
 	}
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -23,7 +24,10 @@ import (
 	files "github.com/dms3-fs/go-fs-cmdkit/files"
 	config "github.com/dms3-fs/go-fs-config"
 	pi "github.com/dms3-fs/go-fs-posinfo"
+	dms3ld "github.com/dms3-fs/go-ld-format"
 	dag "github.com/dms3-fs/go-merkledag"
+	opentracing "github.com/opentracing/opentracing-go"
+	mocktracer "github.com/opentracing/opentracing-go/mocktracer"
 )
 
 const testPeerID = "QmTFauExutTsy4XP6JbMFcw2Wa9645HJt2bTqL6qYDCKfe"
@@ -48,6 +52,90 @@ func TestAddRecursive(t *testing.T) {
 	}
 }
 
+// spanRecordingDAG records the spans of the contexts nodes are added with
+type spanRecordingDAG struct {
+	dms3ld.DAGService
+
+	mu    sync.Mutex
+	spans []*mocktracer.MockSpan
+}
+
+func (d *spanRecordingDAG) Add(ctx context.Context, nd dms3ld.Node) error {
+	if span, ok := opentracing.SpanFromContext(ctx).(*mocktracer.MockSpan); ok {
+		d.mu.Lock()
+		d.spans = append(d.spans, span)
+		d.mu.Unlock()
+	}
+	return d.DAGService.Add(ctx, nd)
+}
+
+func TestAddTracing(t *testing.T) {
+	r := &repo.Mock{
+		C: config.Config{
+			Identity: config.Identity{
+				PeerID: testPeerID, // required by offline node
+			},
+		},
+		D: syncds.MutexWrap(datastore.NewMapDatastore()),
+	}
+	node, err := core.NewNode(context.Background(), &core.BuildCfg{Repo: r})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prev := opentracing.GlobalTracer()
+	tracer := mocktracer.New()
+	opentracing.SetGlobalTracer(tracer)
+	defer opentracing.SetGlobalTracer(prev)
+
+	parent := tracer.StartSpan("add")
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+
+	dserv := &spanRecordingDAG{DAGService: node.DAG}
+	adder, err := NewAdder(ctx, node.Pinning, node.Blockstore, dserv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := ioutil.NopCloser(bytes.NewBufferString("testfile"))
+	if err := adder.AddFile(files.NewReaderFile("a", "a", data, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := adder.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := adder.PinRoot(); err != nil {
+		t.Fatal(err)
+	}
+	parent.Finish()
+
+	var pinRoot *mocktracer.MockSpan
+	for _, span := range tracer.FinishedSpans() {
+		if span.OperationName == "coreunix.PinRoot" {
+			pinRoot = span
+		}
+	}
+	if pinRoot == nil {
+		t.Fatal("no coreunix.PinRoot span")
+	}
+	if pinRoot.ParentID != parent.(*mocktracer.MockSpan).SpanContext.SpanID {
+		t.Error("the coreunix.PinRoot span is not a child of the span of the add")
+	}
+
+	found := false
+	for _, span := range dserv.spans {
+		if span == pinRoot {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("the root was not added within the coreunix.PinRoot span")
+	}
+	if adder.ctx != ctx {
+		t.Error("the adder kept the context of a finished span")
+	}
+}
+
 func TestAddGCLive(t *testing.T) {
 	r := &repo.Mock{
 		C: config.Config{
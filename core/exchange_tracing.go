@@ -0,0 +1,54 @@
+package core
+
+import (
+	"context"
+
+	blocks "github.com/dms3-fs/go-block-format"
+	cid "github.com/dms3-fs/go-cid"
+	exchange "github.com/dms3-fs/go-fs-exchange-interface"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// tracedExchange traces the blocks the blockservice fetches through the
+// exchange it wraps, which it only does for the blocks missing from the
+// blockstore. Dms3FsNode.Exchange keeps the bare exchange, since commands
+// type-assert it to bitswap.
+type tracedExchange struct {
+	exchange.Interface
+}
+
+func (e *tracedExchange) GetBlock(ctx context.Context, c *cid.Cid) (blocks.Block, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "exchange.GetBlock")
+	defer span.Finish()
+	span.SetTag("cid", c.String())
+
+	return e.Interface.GetBlock(ctx, c)
+}
+
+// GetBlocks finishes its span once all the blocks are received, when the
+// returned channel is closed.
+func (e *tracedExchange) GetBlocks(ctx context.Context, cids []*cid.Cid) (<-chan blocks.Block, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "exchange.GetBlocks")
+	span.SetTag("count", len(cids))
+
+	in, err := e.Interface.GetBlocks(ctx, cids)
+	if err != nil {
+		span.Finish()
+		return nil, err
+	}
+
+	out := make(chan blocks.Block)
+	go func() {
+		defer close(out)
+		defer span.Finish()
+
+		for b := range in {
+			select {
+			case out <- b:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
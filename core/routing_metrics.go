@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	cid "github.com/dms3-fs/go-cid"
+	ci "github.com/dms3-p2p/go-p2p-crypto"
+	peer "github.com/dms3-p2p/go-p2p-peer"
+	pstore "github.com/dms3-p2p/go-p2p-peerstore"
+	routing "github.com/dms3-p2p/go-p2p-routing"
+	ropts "github.com/dms3-p2p/go-p2p-routing/options"
+	prometheus "github.com/gxed/client_golang/prometheus"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var dhtQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "dms3fs",
+	Subsystem: "dht",
+	Name:      "query_duration_seconds",
+	Help:      "Latency of DHT queries by operation",
+	Buckets:   prometheus.ExponentialBuckets(0.01, 2, 14),
+}, []string{"operation"})
+
+func init() {
+	prometheus.MustRegister(dhtQueryDuration)
+}
+
+// instrumentedRouting records the latency of the queries made through the
+// DHT it wraps, and traces each of them in a span. It also forwards the
+// optional interfaces of the DHT which callers check for.
+type instrumentedRouting struct {
+	routing.Dms3FsRouting
+}
+
+var _ routing.PubKeyFetcher = (*instrumentedRouting)(nil)
+
+// startQuery starts the span of a query, and returns the function recording
+// its latency and finishing the span once it is done.
+func startQuery(ctx context.Context, operation string) (context.Context, func()) {
+	start := time.Now()
+	span, ctx := opentracing.StartSpanFromContext(ctx, "dht."+operation)
+	return ctx, func() {
+		dhtQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+		span.Finish()
+	}
+}
+
+func (r *instrumentedRouting) FindPeer(ctx context.Context, id peer.ID) (pstore.PeerInfo, error) {
+	ctx, done := startQuery(ctx, "find_peer")
+	defer done()
+	return r.Dms3FsRouting.FindPeer(ctx, id)
+}
+
+// FindProvidersAsync records the time until the query is done, when the
+// returned channel is closed.
+func (r *instrumentedRouting) FindProvidersAsync(ctx context.Context, c *cid.Cid, count int) <-chan pstore.PeerInfo {
+	ctx, done := startQuery(ctx, "find_providers")
+	in := r.Dms3FsRouting.FindProvidersAsync(ctx, c, count)
+
+	out := make(chan pstore.PeerInfo)
+	go func() {
+		defer close(out)
+		defer done()
+
+		for pi := range in {
+			select {
+			case out <- pi:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (r *instrumentedRouting) Provide(ctx context.Context, c *cid.Cid, announce bool) error {
+	ctx, done := startQuery(ctx, "provide")
+	defer done()
+	return r.Dms3FsRouting.Provide(ctx, c, announce)
+}
+
+func (r *instrumentedRouting) GetValue(ctx context.Context, key string, opts ...ropts.Option) ([]byte, error) {
+	ctx, done := startQuery(ctx, "get_value")
+	defer done()
+	return r.Dms3FsRouting.GetValue(ctx, key, opts...)
+}
+
+func (r *instrumentedRouting) PutValue(ctx context.Context, key string, value []byte, opts ...ropts.Option) error {
+	ctx, done := startQuery(ctx, "put_value")
+	defer done()
+	return r.Dms3FsRouting.PutValue(ctx, key, value, opts...)
+}
+
+// GetPublicKey keeps routing.GetPublicKey taking the fast path of the DHT,
+// which looks the key up from the peer before falling back to the records.
+func (r *instrumentedRouting) GetPublicKey(ctx context.Context, id peer.ID) (ci.PubKey, error) {
+	ctx, done := startQuery(ctx, "get_public_key")
+	defer done()
+	if pkf, ok := r.Dms3FsRouting.(routing.PubKeyFetcher); ok {
+		return pkf.GetPublicKey(ctx, id)
+	}
+	return routing.GetPublicKey(r.Dms3FsRouting, ctx, id)
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	cid "github.com/dms3-fs/go-cid"
+	ds "github.com/dms3-fs/go-datastore"
+	syncds "github.com/dms3-fs/go-datastore/sync"
+	offroute "github.com/dms3-fs/go-fs-routing/offline"
+	u "github.com/dms3-fs/go-fs-util"
+	peer "github.com/dms3-p2p/go-p2p-peer"
+	record "github.com/dms3-p2p/go-p2p-record"
+	prometheus "github.com/gxed/client_golang/prometheus"
+)
+
+// querySamples returns the number of latencies recorded for operation.
+func querySamples(t *testing.T, operation string) uint64 {
+	t.Helper()
+
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != "dms3fs_dht_query_duration_seconds" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "operation" && l.GetValue() == operation {
+					return m.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestInstrumentedRouting(t *testing.T) {
+	ctx := context.Background()
+	dstore := syncds.MutexWrap(ds.NewMapDatastore())
+	r := &instrumentedRouting{offroute.NewOfflineRouter(dstore, record.NamespacedValidator{})}
+
+	c := cid.NewCidV0(u.Hash([]byte("fnord")))
+	pid, err := peer.IDB58Decode("QmTFauExutTsy4XP6JbMFcw2Wa9645HJt2bTqL6qYDCKfe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		operation string
+		query     func()
+	}{
+		{"find_peer", func() { r.FindPeer(ctx, pid) }},
+		{"find_providers", func() {
+			for range r.FindProvidersAsync(ctx, c, 1) {
+			}
+		}},
+		{"provide", func() { r.Provide(ctx, c, true) }},
+		{"get_value", func() { r.GetValue(ctx, "/dms3ns/missing") }},
+		{"put_value", func() { r.PutValue(ctx, "/dms3ns/missing", []byte("fnord")) }},
+		{"get_public_key", func() { r.GetPublicKey(ctx, pid) }},
+	} {
+		before := querySamples(t, test.operation)
+		test.query()
+		if after := querySamples(t, test.operation); after != before+1 {
+			t.Errorf("%s: expected %d samples, got %d", test.operation, before+1, after)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package dagutils
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+
+	cid "github.com/dms3-fs/go-cid"
+	cbor "github.com/dms3-fs/go-ld-cbor"
+	dms3ld "github.com/dms3-fs/go-ld-format"
+)
+
+// CarVersion is the version of the CAR (content addressable archive) format
+// written by WriteCar
+const CarVersion = 1
+
+// carHeader is the DAG-CBOR encoded header of a CAR archive
+type carHeader struct {
+	Roots   []*cid.Cid `refmt:"roots"`
+	Version uint64     `refmt:"version"`
+}
+
+func init() {
+	cbor.RegisterCborType(carHeader{})
+}
+
+// WriteCar writes the DAGs under roots to w as a CAR archive: a header
+// holding the roots, followed by every block of the DAGs in depth-first
+// order. Blocks shared by several paths are written once.
+func WriteCar(ctx context.Context, ng dms3ld.NodeGetter, roots []*cid.Cid, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	hdr, err := cbor.DumpObject(&carHeader{Roots: roots, Version: CarVersion})
+	if err != nil {
+		return err
+	}
+
+	if err := writeCarSection(bw, hdr); err != nil {
+		return err
+	}
+
+	seen := cid.NewSet()
+	var walk func(c *cid.Cid) error
+	walk = func(c *cid.Cid) error {
+		if !seen.Visit(c) {
+			return nil
+		}
+
+		nd, err := ng.Get(ctx, c)
+		if err != nil {
+			return err
+		}
+
+		if err := writeCarSection(bw, c.Bytes(), nd.RawData()); err != nil {
+			return err
+		}
+
+		for _, l := range nd.Links() {
+			if err := walk(l.Cid); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, r := range roots {
+		if err := walk(r); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// writeCarSection writes the data, prefixed with its total length as an
+// unsigned varint
+func writeCarSection(w io.Writer, data ...[]byte) error {
+	var n uint64
+	for _, d := range data {
+		n += uint64(len(d))
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	if _, err := w.Write(buf[:binary.PutUvarint(buf, n)]); err != nil {
+		return err
+	}
+
+	for _, d := range data {
+		if _, err := w.Write(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package dagutils
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	dag "github.com/dms3-fs/go-merkledag"
+	mdtest "github.com/dms3-fs/go-merkledag/test"
+
+	cid "github.com/dms3-fs/go-cid"
+	cbor "github.com/dms3-fs/go-ld-cbor"
+)
+
+func TestWriteCar(t *testing.T) {
+	ctx := context.Background()
+	ds := mdtest.Mock()
+
+	root := mkTree(t, ds, map[string]interface{}{
+		"a": "same",
+		"b": "same",
+		"dir": map[string]interface{}{
+			"c": "other",
+		},
+	})
+
+	buf := new(bytes.Buffer)
+	if err := WriteCar(ctx, ds, []*cid.Cid{root.Cid()}, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var sections [][]byte
+	data := buf.Bytes()
+	for len(data) > 0 {
+		n, l := binary.Uvarint(data)
+		if l <= 0 || uint64(len(data)-l) < n {
+			t.Fatal("truncated section")
+		}
+		sections = append(sections, data[l:l+int(n)])
+		data = data[l+int(n):]
+	}
+
+	var hdr carHeader
+	if err := cbor.DecodeInto(sections[0], &hdr); err != nil {
+		t.Fatal(err)
+	}
+	if hdr.Version != CarVersion || len(hdr.Roots) != 1 || !hdr.Roots[0].Equals(root.Cid()) {
+		t.Fatalf("unexpected header: %+v", hdr)
+	}
+
+	// root, "same" once, dir and "other"
+	if len(sections) != 5 {
+		t.Fatalf("expected 4 blocks, got %d", len(sections)-1)
+	}
+
+	expected := []*dag.ProtoNode{root}
+	for _, name := range []string{"a", "dir"} {
+		nd, err := root.GetLinkedProtoNode(ctx, ds, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = append(expected, nd)
+	}
+
+	for _, nd := range expected {
+		block := append(nd.Cid().Bytes(), nd.RawData()...)
+
+		found := false
+		for _, s := range sections[1:] {
+			if bytes.Equal(s, block) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("block %s missing", nd.Cid())
+		}
+	}
+}
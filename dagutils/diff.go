@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"strings"
 
 	coreiface "github.com/dms3-fs/go-dms3-fs/core/coreapi/interface"
 
@@ -53,12 +54,7 @@ func ApplyChange(ctx context.Context, ds dms3ld.DAGService, nd *dag.ProtoNode, c
 				return nil, err
 			}
 
-			childpb, ok := child.(*dag.ProtoNode)
-			if !ok {
-				return nil, dag.ErrNotProtobuf
-			}
-
-			err = e.InsertNodeAtPath(ctx, c.Path, childpb, nil)
+			err = e.InsertNodeAtPath(ctx, c.Path, child, nil)
 			if err != nil {
 				return nil, err
 			}
@@ -79,12 +75,7 @@ func ApplyChange(ctx context.Context, ds dms3ld.DAGService, nd *dag.ProtoNode, c
 				return nil, err
 			}
 
-			childpb, ok := child.(*dag.ProtoNode)
-			if !ok {
-				return nil, dag.ErrNotProtobuf
-			}
-
-			err = e.InsertNodeAtPath(ctx, c.Path, childpb, nil)
+			err = e.InsertNodeAtPath(ctx, c.Path, child, nil)
 			if err != nil {
 				return nil, err
 			}
@@ -96,85 +87,121 @@ func ApplyChange(ctx context.Context, ds dms3ld.DAGService, nd *dag.ProtoNode, c
 
 // Diff returns a set of changes that transform node 'a' into node 'b'
 func Diff(ctx context.Context, ds dms3ld.DAGService, a, b dms3ld.Node) ([]*Change, error) {
-	// Base case where both nodes are leaves, just compare
-	// their CIDs.
-	if len(a.Links()) == 0 && len(b.Links()) == 0 {
-		if a.Cid().Equals(b.Cid()) {
-			return []*Change{}, nil
-		}
-		return []*Change{
-			&Change{
-				Type:   Mod,
-				Before: a.Cid(),
-				After:  b.Cid(),
-			},
-		}, nil
+	return DiffDepth(ctx, ds, a, b, -1)
+}
+
+// DiffDepth returns a set of changes that transform node 'a' into node 'b',
+// descending into the modified links of any DMS3LD format. Changes are
+// reported at the deepest path where they can be located: a node whose links
+// are unchanged but which differs otherwise is reported as modified. Modified
+// links deeper than depth are reported as modified without being descended
+// into, a negative depth means no limit. A node with unnamed links or several
+// links of the same name, like a chunked file, is reported as modified as a
+// whole.
+func DiffDepth(ctx context.Context, ds dms3ld.DAGService, a, b dms3ld.Node, depth int) ([]*Change, error) {
+	if a.Cid().Equals(b.Cid()) {
+		return []*Change{}, nil
 	}
 
-	var out []*Change
-	cleanA := a.Copy().(*dag.ProtoNode)
-	cleanB := b.Copy().(*dag.ProtoNode)
+	return diffNodes(ctx, ds, a, b, "", depth)
+}
+
+func diffNodes(ctx context.Context, ds dms3ld.DAGService, a, b dms3ld.Node, p string, depth int) ([]*Change, error) {
+	modified := []*Change{
+		&Change{
+			Type:   Mod,
+			Path:   p,
+			Before: a.Cid(),
+			After:  b.Cid(),
+		},
+	}
+
+	// Base case where both nodes are leaves, or the depth limit was reached:
+	// the node itself was modified.
+	if depth == 0 || (len(a.Links()) == 0 && len(b.Links()) == 0) {
+		return modified, nil
+	}
+
+	// Links can only be matched by name when their names are distinct, which
+	// is not the case of the unnamed links to the chunks of a file.
+	if !namedLinks(a.Links()) || !namedLinks(b.Links()) {
+		return modified, nil
+	}
+
+	blinks := make(map[string]*dms3ld.Link, len(b.Links()))
+	for _, lnk := range b.Links() {
+		blinks[lnk.Name] = lnk
+	}
 
-	// strip out unchanged stuff
+	var out []*Change
 	for _, lnk := range a.Links() {
-		l, _, err := b.ResolveLink([]string{lnk.Name})
-		if err == nil {
-			if l.Cid.Equals(lnk.Cid) {
-				// no change... ignore it
-			} else {
-				anode, err := lnk.GetNode(ctx, ds)
-				if err != nil {
-					return nil, err
-				}
-
-				bnode, err := l.GetNode(ctx, ds)
-				if err != nil {
-					return nil, err
-				}
-
-				anodepb, ok := anode.(*dag.ProtoNode)
-				if !ok {
-					return nil, dag.ErrNotProtobuf
-				}
-
-				bnodepb, ok := bnode.(*dag.ProtoNode)
-				if !ok {
-					return nil, dag.ErrNotProtobuf
-				}
-
-				sub, err := Diff(ctx, ds, anodepb, bnodepb)
-				if err != nil {
-					return nil, err
-				}
-
-				for _, subc := range sub {
-					subc.Path = path.Join(lnk.Name, subc.Path)
-					out = append(out, subc)
-				}
-			}
-			cleanA.RemoveNodeLink(l.Name)
-			cleanB.RemoveNodeLink(l.Name)
+		lp := path.Join(p, lnk.Name)
+
+		l, ok := blinks[lnk.Name]
+		if !ok {
+			out = append(out, &Change{
+				Type:   Remove,
+				Path:   lp,
+				Before: lnk.Cid,
+			})
+			continue
 		}
-	}
+		delete(blinks, lnk.Name)
 
-	for _, lnk := range cleanA.Links() {
-		out = append(out, &Change{
-			Type:   Remove,
-			Path:   lnk.Name,
-			Before: lnk.Cid,
-		})
+		if l.Cid.Equals(lnk.Cid) {
+			// no change... ignore it
+			continue
+		}
+
+		anode, err := lnk.GetNode(ctx, ds)
+		if err != nil {
+			return nil, err
+		}
+
+		bnode, err := l.GetNode(ctx, ds)
+		if err != nil {
+			return nil, err
+		}
+
+		sub, err := diffNodes(ctx, ds, anode, bnode, lp, depth-1)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sub...)
 	}
-	for _, lnk := range cleanB.Links() {
+
+	for _, lnk := range b.Links() {
+		if _, ok := blinks[lnk.Name]; !ok {
+			continue
+		}
+
 		out = append(out, &Change{
 			Type:  Add,
-			Path:  lnk.Name,
+			Path:  path.Join(p, lnk.Name),
 			After: lnk.Cid,
 		})
 	}
 
+	// only the data of the node changed
+	if len(out) == 0 {
+		return modified, nil
+	}
+
 	return out, nil
 }
 
+// namedLinks reports whether the links all have distinct, non empty names
+func namedLinks(links []*dms3ld.Link) bool {
+	names := make(map[string]struct{}, len(links))
+	for _, lnk := range links {
+		if _, dup := names[lnk.Name]; dup || lnk.Name == "" {
+			return false
+		}
+		names[lnk.Name] = struct{}{}
+	}
+	return true
+}
+
 // Conflict represents two incompatible changes and is returned by MergeDiffs().
 type Conflict struct {
 	A *Change
@@ -183,29 +210,84 @@ type Conflict struct {
 
 // MergeDiffs takes two slice of changes and adds them to a single slice.
 // When a Change from b happens to the same path of an existing change in a,
-// a conflict is created and b is not added to the merged slice.
+// or to a path above or below it, a conflict is created and b is not added to
+// the merged slice. Identical changes are only added once.
 // A slice of Conflicts is returned and contains pointers to the
-// Changes involved (which share the same path).
+// Changes involved.
 func MergeDiffs(a, b []*Change) ([]*Change, []Conflict) {
 	var out []*Change
 	var conflicts []Conflict
 	paths := make(map[string]*Change)
+
+	// parents maps the paths above the changes in a to one of these changes
+	parents := make(map[string]*Change)
 	for _, c := range a {
 		paths[c.Path] = c
+		for p := c.Path; p != ""; {
+			p = parentPath(p)
+			if _, ok := parents[p]; !ok {
+				parents[p] = c
+			}
+		}
 	}
 
 	for _, c := range b {
 		if ca, ok := paths[c.Path]; ok {
+			if !sameChange(ca, c) {
+				conflicts = append(conflicts, Conflict{
+					A: ca,
+					B: c,
+				})
+			}
+			continue
+		}
+
+		if ca, ok := parents[c.Path]; ok {
 			conflicts = append(conflicts, Conflict{
 				A: ca,
 				B: c,
 			})
-		} else {
-			out = append(out, c)
+			continue
+		}
+
+		var ca *Change
+		for p := c.Path; p != "" && ca == nil; {
+			p = parentPath(p)
+			ca = paths[p]
 		}
+		if ca != nil {
+			conflicts = append(conflicts, Conflict{
+				A: ca,
+				B: c,
+			})
+			continue
+		}
+
+		out = append(out, c)
 	}
 	for _, c := range paths {
 		out = append(out, c)
 	}
 	return out, conflicts
 }
+
+// parentPath returns the path of the parent of the node at path p, the root
+// path being ""
+func parentPath(p string) string {
+	i := strings.LastIndex(p, "/")
+	if i < 0 {
+		return ""
+	}
+	return p[:i]
+}
+
+func sameChange(a, b *Change) bool {
+	return a.Type == b.Type && cidEquals(a.Before, b.Before) && cidEquals(a.After, b.After)
+}
+
+func cidEquals(a, b *cid.Cid) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equals(b)
+}
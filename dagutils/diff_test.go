@@ -0,0 +1,243 @@
+package dagutils
+
+import (
+	"context"
+	"testing"
+
+	dag "github.com/dms3-fs/go-merkledag"
+	mdtest "github.com/dms3-fs/go-merkledag/test"
+
+	dms3ld "github.com/dms3-fs/go-ld-format"
+)
+
+// mkTree adds a directory-like tree to ds, where each entry of files is
+// either a string for a leaf or a map for a subtree
+func mkTree(t *testing.T, ds dms3ld.DAGService, files map[string]interface{}) *dag.ProtoNode {
+	nd := new(dag.ProtoNode)
+	for name, v := range files {
+		var child dms3ld.Node
+		switch v := v.(type) {
+		case string:
+			child = dag.NodeWithData([]byte(v))
+		case map[string]interface{}:
+			child = mkTree(t, ds, v)
+		}
+
+		if err := ds.Add(context.Background(), child); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := nd.AddNodeLink(name, child); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ds.Add(context.Background(), nd); err != nil {
+		t.Fatal(err)
+	}
+	return nd
+}
+
+func changesByPath(changes []*Change) map[string]*Change {
+	out := make(map[string]*Change, len(changes))
+	for _, c := range changes {
+		out[c.Path] = c
+	}
+	return out
+}
+
+func TestDiffRecursive(t *testing.T) {
+	ctx := context.Background()
+	ds := mdtest.Mock()
+
+	a := mkTree(t, ds, map[string]interface{}{
+		"keep": "same",
+		"gone": "removed",
+		"dir": map[string]interface{}{
+			"sub": map[string]interface{}{
+				"file": "before",
+			},
+		},
+	})
+	b := mkTree(t, ds, map[string]interface{}{
+		"keep": "same",
+		"new":  "added",
+		"dir": map[string]interface{}{
+			"sub": map[string]interface{}{
+				"file": "after",
+			},
+		},
+	})
+
+	changes, err := Diff(ctx, ds, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byPath := changesByPath(changes)
+	if len(changes) != 3 || len(byPath) != 3 {
+		t.Fatalf("unexpected changes: %v", changes)
+	}
+
+	if c := byPath["gone"]; c == nil || c.Type != Remove {
+		t.Fatal("expected gone to be removed")
+	}
+	if c := byPath["new"]; c == nil || c.Type != Add {
+		t.Fatal("expected new to be added")
+	}
+	if c := byPath["dir/sub/file"]; c == nil || c.Type != Mod {
+		t.Fatal("expected dir/sub/file to be modified")
+	}
+
+	changes, err = DiffDepth(ctx, ds, a, b, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byPath = changesByPath(changes)
+	if c := byPath["dir"]; c == nil || c.Type != Mod {
+		t.Fatalf("expected dir to be modified at depth 1, got: %v", changes)
+	}
+	if _, ok := byPath["dir/sub/file"]; ok {
+		t.Fatal("unexpected change below the depth limit")
+	}
+}
+
+func TestDiffEmptyDir(t *testing.T) {
+	ctx := context.Background()
+	ds := mdtest.Mock()
+
+	a := mkTree(t, ds, map[string]interface{}{
+		"baz": map[string]interface{}{},
+	})
+	b := mkTree(t, ds, map[string]interface{}{
+		"baz": map[string]interface{}{
+			"dog": "dog",
+		},
+	})
+
+	changes, err := Diff(ctx, ds, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Type != Add || changes[0].Path != "baz/dog" {
+		t.Fatalf("expected baz/dog to be added, got: %v", changes)
+	}
+
+	changes, err = Diff(ctx, ds, b, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Type != Remove || changes[0].Path != "baz/dog" {
+		t.Fatalf("expected baz/dog to be removed, got: %v", changes)
+	}
+}
+
+// mkFile adds a file-like node to ds, with an unnamed link to each chunk
+func mkFile(t *testing.T, ds dms3ld.DAGService, chunks ...string) *dag.ProtoNode {
+	nd := new(dag.ProtoNode)
+	for _, c := range chunks {
+		child := dag.NodeWithData([]byte(c))
+		if err := ds.Add(context.Background(), child); err != nil {
+			t.Fatal(err)
+		}
+		if err := nd.AddNodeLink("", child); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ds.Add(context.Background(), nd); err != nil {
+		t.Fatal(err)
+	}
+	return nd
+}
+
+func TestDiffChunkedFiles(t *testing.T) {
+	ctx := context.Background()
+	ds := mdtest.Mock()
+
+	a := mkFile(t, ds, "one", "two", "three")
+	b := mkFile(t, ds, "one", "2", "three")
+	ta := mkTree(t, ds, map[string]interface{}{"dir": map[string]interface{}{}})
+	tb := mkTree(t, ds, map[string]interface{}{"dir": map[string]interface{}{}})
+	if err := ta.AddNodeLink("file", a); err != nil {
+		t.Fatal(err)
+	}
+	if err := tb.AddNodeLink("file", b); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := Diff(ctx, ds, ta, tb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("expected a single change, got: %v", changes)
+	}
+
+	c := changes[0]
+	if c.Type != Mod || c.Path != "file" || !c.Before.Equals(a.Cid()) || !c.After.Equals(b.Cid()) {
+		t.Fatalf("expected the file to be modified as a whole, got: %v", c)
+	}
+}
+
+func TestMergeDiffs(t *testing.T) {
+	ctx := context.Background()
+	ds := mdtest.Mock()
+
+	base := mkTree(t, ds, map[string]interface{}{
+		"a":   "a",
+		"dir": map[string]interface{}{"x": "x"},
+	})
+	ours := mkTree(t, ds, map[string]interface{}{
+		"a":   "a2",
+		"dir": map[string]interface{}{"x": "x"},
+		"new": "new",
+	})
+	theirs := mkTree(t, ds, map[string]interface{}{
+		"a":   "a",
+		"dir": map[string]interface{}{"x": "x", "y": "y"},
+		"new": "new",
+	})
+	conflicting := mkTree(t, ds, map[string]interface{}{
+		"a":   "a3",
+		"dir": map[string]interface{}{"x": "x"},
+	})
+
+	da, err := Diff(ctx, ds, base, ours)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := Diff(ctx, ds, base, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged, conflicts := MergeDiffs(da, db)
+	if len(conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v", conflicts)
+	}
+
+	// "new" was added identically on both sides
+	byPath := changesByPath(merged)
+	if len(merged) != 3 || len(byPath) != 3 {
+		t.Fatalf("unexpected merged changes: %v", merged)
+	}
+
+	dc, err := Diff(ctx, ds, base, conflicting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, conflicts = MergeDiffs(da, dc)
+	if len(conflicts) != 1 || conflicts[0].A.Path != "a" || conflicts[0].B.Path != "a" {
+		t.Fatalf("unexpected conflicts: %v", conflicts)
+	}
+
+	// a change to a directory conflicts with the changes below it
+	_, conflicts = MergeDiffs([]*Change{{Type: Mod, Path: "dir"}}, db)
+	if len(conflicts) != 1 || conflicts[0].B.Path != "dir/y" {
+		t.Fatalf("unexpected conflicts: %v", conflicts)
+	}
+}
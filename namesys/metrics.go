@@ -0,0 +1,39 @@
+package namesys
+
+import (
+	prometheus "github.com/gxed/client_golang/prometheus"
+)
+
+var (
+	resolveCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dms3fs",
+		Subsystem: "namesys",
+		Name:      "resolve_total",
+		Help:      "Number of DMS3NS name resolutions by outcome",
+	}, []string{"outcome"})
+
+	publishCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dms3fs",
+		Subsystem: "namesys",
+		Name:      "publish_total",
+		Help:      "Number of DMS3NS record publications by outcome",
+	}, []string{"outcome"})
+)
+
+func init() {
+	prometheus.MustRegister(resolveCount, publishCount)
+}
+
+// outcome returns the label of an operation which ended with err.
+func outcome(err error) string {
+	switch err {
+	case nil:
+		return "success"
+	case ErrResolveFailed:
+		return "not_found"
+	case ErrResolveRecursion:
+		return "recursion"
+	default:
+		return "error"
+	}
+}
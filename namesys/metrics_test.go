@@ -0,0 +1,97 @@
+package namesys
+
+import (
+	"context"
+	"testing"
+
+	opts "github.com/dms3-fs/go-dms3-fs/namesys/opts"
+	path "github.com/dms3-fs/go-path"
+	"github.com/dms3-fs/go-unixfs"
+
+	ds "github.com/dms3-fs/go-datastore"
+	dssync "github.com/dms3-fs/go-datastore/sync"
+	dms3ns "github.com/dms3-fs/go-dms3ns"
+	offroute "github.com/dms3-fs/go-fs-routing/offline"
+	ci "github.com/dms3-p2p/go-p2p-crypto"
+	peer "github.com/dms3-p2p/go-p2p-peer"
+	pstore "github.com/dms3-p2p/go-p2p-peerstore"
+	prometheus "github.com/gxed/client_golang/prometheus"
+)
+
+// counterValue returns the value of the counter called name with the given
+// outcome in the default registry, or 0 if it has not been counted yet.
+func counterValue(t *testing.T, name string, outcome string) float64 {
+	t.Helper()
+
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "outcome" && l.GetValue() == outcome {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestResolveMetrics(t *testing.T) {
+	r := &mpns{
+		dms3nsResolver: mockResolverOne(),
+		dnsResolver:    mockResolverTwo(),
+	}
+
+	for _, test := range []struct {
+		name    string
+		depth   uint
+		outcome string
+	}{
+		{"/dms3ns/QmatmE9msSfkKxoffpHwNLNKgwZG8eT9Bud6YoPab52vpy", opts.DefaultDepthLimit, "success"},
+		{"/dms3ns/QmbCMUZw6JFeZ7Wp9jkzbye3Fzp2GGcPgC3nmeUjfVF87n", 1, "recursion"},
+		{"/dms3ns/nxdomain.example.com", opts.DefaultDepthLimit, "not_found"},
+	} {
+		before := counterValue(t, "dms3fs_namesys_resolve_total", test.outcome)
+		r.Resolve(context.Background(), test.name, opts.Depth(test.depth))
+		if after := counterValue(t, "dms3fs_namesys_resolve_total", test.outcome); after != before+1 {
+			t.Errorf("%s: expected the %s count to go from %v to %v, got %v", test.name, test.outcome, before, before+1, after)
+		}
+	}
+}
+
+func TestPublishMetrics(t *testing.T) {
+	dst := dssync.MutexWrap(ds.NewMapDatastore())
+	priv, _, err := ci.GenerateKeyPair(ci.RSA, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps := pstore.NewPeerstore()
+	pid, err := peer.IDFromPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ps.AddPrivKey(pid, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nsys := NewNameSystem(offroute.NewOfflineRouter(dst, dms3ns.Validator{KeyBook: ps}), dst, 0)
+	p, err := path.ParsePath(unixfs.EmptyDirNode().Cid().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := counterValue(t, "dms3fs_namesys_publish_total", "success")
+	if err := nsys.Publish(context.Background(), priv, p); err != nil {
+		t.Fatal(err)
+	}
+	if after := counterValue(t, "dms3fs_namesys_publish_total", "success"); after != before+1 {
+		t.Fatalf("expected the success count to go from %v to %v, got %v", before, before+1, after)
+	}
+}
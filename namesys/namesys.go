@@ -15,6 +15,7 @@ import (
 	peer "github.com/dms3-p2p/go-p2p-peer"
 	routing "github.com/dms3-p2p/go-p2p-routing"
 	mh "github.com/dms3-mft/go-multihash"
+	opentracing "github.com/opentracing/opentracing-go"
 )
 
 // mpns (a multi-protocol NameSystem) implements generic DMS3FS naming.
@@ -61,7 +62,14 @@ func (ns *mpns) Resolve(ctx context.Context, name string, options ...opts.Resolv
 		return path.ParsePath("/dms3fs/" + name)
 	}
 
-	return resolve(ctx, ns, name, opts.ProcessOpts(options), "/dms3ns/")
+	span, ctx := opentracing.StartSpanFromContext(ctx, "namesys.Resolve")
+	defer span.Finish()
+	span.SetTag("name", name)
+
+	p, err := resolve(ctx, ns, name, opts.ProcessOpts(options), "/dms3ns/")
+	resolveCount.WithLabelValues(outcome(err)).Inc()
+	span.SetTag("outcome", outcome(err))
+	return p, err
 }
 
 // resolveOnce implements resolver.
@@ -117,7 +125,15 @@ func (ns *mpns) PublishWithEOL(ctx context.Context, name ci.PrivKey, value path.
 	if err != nil {
 		return err
 	}
-	if err := ns.dms3nsPublisher.PublishWithEOL(ctx, name, value, eol); err != nil {
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "namesys.Publish")
+	defer span.Finish()
+	span.SetTag("name", id.Pretty())
+
+	err = ns.dms3nsPublisher.PublishWithEOL(ctx, name, value, eol)
+	publishCount.WithLabelValues(outcome(err)).Inc()
+	span.SetTag("outcome", outcome(err))
+	if err != nil {
 		return err
 	}
 	ttl := DefaultResolverCacheTTL
@@ -0,0 +1,128 @@
+package gc
+
+import (
+	"sync"
+
+	blocks "github.com/dms3-fs/go-block-format"
+	cid "github.com/dms3-fs/go-cid"
+	bstore "github.com/dms3-fs/go-fs-blockstore"
+)
+
+// BarrierBlockstore is a GCBlockstore which GC sweeps in batches while
+// blocks keep being added, instead of locking it for the whole collection.
+//
+// Once the collection marked the live blocks, a block which is not marked may
+// still become live: it may be part of a DAG being added or pinned. So while
+// a collection runs, the blockstore acts as a write barrier and records every
+// block written, read or checked for, which the collection then keeps. The
+// blockstore is only locked while a batch of blocks is deleted, so that a
+// block is never deleted between being recorded and being used.
+type BarrierBlockstore struct {
+	bstore.GCBlockstore
+
+	// gcLock serializes the collections
+	gcLock sync.Mutex
+
+	// sweepLock is held by the collection while it deletes a batch of
+	// blocks, and by the accesses to the blocks otherwise
+	sweepLock sync.RWMutex
+
+	lk sync.Mutex
+	// touched holds the multihashes of the blocks accessed while a
+	// collection runs, and is nil otherwise. Recording multihashes rather
+	// than cids keeps the blocks accessed with another version of their cid.
+	touched map[string]struct{}
+}
+
+// NewBarrierBlockstore returns a GCBlockstore letting GC sweep the given
+// blockstore concurrently with the accesses to it.
+func NewBarrierBlockstore(bs bstore.GCBlockstore) *BarrierBlockstore {
+	return &BarrierBlockstore{GCBlockstore: bs}
+}
+
+func (bs *BarrierBlockstore) touch(c *cid.Cid) {
+	bs.lk.Lock()
+	defer bs.lk.Unlock()
+	if bs.touched != nil {
+		bs.touched[string(c.Hash())] = struct{}{}
+	}
+}
+
+func (bs *BarrierBlockstore) isTouched(c *cid.Cid) bool {
+	bs.lk.Lock()
+	defer bs.lk.Unlock()
+	_, ok := bs.touched[string(c.Hash())]
+	return ok
+}
+
+// startBarrier starts recording the accessed blocks
+func (bs *BarrierBlockstore) startBarrier() {
+	bs.lk.Lock()
+	defer bs.lk.Unlock()
+	bs.touched = make(map[string]struct{})
+}
+
+// stopBarrier stops recording the accessed blocks
+func (bs *BarrierBlockstore) stopBarrier() {
+	bs.lk.Lock()
+	defer bs.lk.Unlock()
+	bs.touched = nil
+}
+
+// deleteUntouched deletes the given blocks but the ones accessed since the
+// barrier was started. It returns the blocks deleted and the errors.
+func (bs *BarrierBlockstore) deleteUntouched(keys []*cid.Cid) ([]*cid.Cid, []error) {
+	bs.sweepLock.Lock()
+	defer bs.sweepLock.Unlock()
+
+	var removed []*cid.Cid
+	var errs []error
+	for _, k := range keys {
+		if bs.isTouched(k) {
+			continue
+		}
+		if err := bs.GCBlockstore.DeleteBlock(k); err != nil {
+			errs = append(errs, &CannotDeleteBlockError{k, err})
+			continue
+		}
+		removed = append(removed, k)
+	}
+	return removed, errs
+}
+
+func (bs *BarrierBlockstore) Has(c *cid.Cid) (bool, error) {
+	bs.sweepLock.RLock()
+	defer bs.sweepLock.RUnlock()
+	bs.touch(c)
+	return bs.GCBlockstore.Has(c)
+}
+
+func (bs *BarrierBlockstore) Get(c *cid.Cid) (blocks.Block, error) {
+	bs.sweepLock.RLock()
+	defer bs.sweepLock.RUnlock()
+	bs.touch(c)
+	return bs.GCBlockstore.Get(c)
+}
+
+func (bs *BarrierBlockstore) GetSize(c *cid.Cid) (int, error) {
+	bs.sweepLock.RLock()
+	defer bs.sweepLock.RUnlock()
+	bs.touch(c)
+	return bs.GCBlockstore.GetSize(c)
+}
+
+func (bs *BarrierBlockstore) Put(b blocks.Block) error {
+	bs.sweepLock.RLock()
+	defer bs.sweepLock.RUnlock()
+	bs.touch(b.Cid())
+	return bs.GCBlockstore.Put(b)
+}
+
+func (bs *BarrierBlockstore) PutMany(blks []blocks.Block) error {
+	bs.sweepLock.RLock()
+	defer bs.sweepLock.RUnlock()
+	for _, b := range blks {
+		bs.touch(b.Cid())
+	}
+	return bs.GCBlockstore.PutMany(blks)
+}
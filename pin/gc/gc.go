@@ -29,16 +29,50 @@ type Result struct {
 	Error      error
 }
 
+// sweepBatchSize is the number of blocks deleted at once while the blockstore
+// is locked, when sweeping a BarrierBlockstore.
+const sweepBatchSize = 256
+
 // GC performs a mark and sweep garbage collection of the blocks in the blockstore
-// first, it creates a 'marked' set and adds to it the following:
+// first, it marks as live the following:
 // - all recursively pinned blocks, plus all of their descendants (recursively)
 // - bestEffortRoots, plus all of its descendants (recursively)
 // - all directly pinned blocks
 // - all blocks utilized internally by the pinner
 //
 // The routine then iterates over every block in the blockstore and
-// deletes any block that is not found in the marked set.
+// deletes any block that is not live.
+//
+// The blocks reachable from the recursive pins are kept in an Index, the one
+// of pn if it was returned by NewIndexedPinner or else one stored in dstor,
+// which is only updated with the pins added or removed since it was last
+// updated. The GCLocker is held while the blocks are marked, and
+// for the whole collection unless bs is a BarrierBlockstore. A
+// BarrierBlockstore is swept in batches while blocks keep being added, and
+// the blocks accessed during the collection are kept.
 func GC(ctx context.Context, bs bstore.GCBlockstore, dstor dstore.Datastore, pn pin.Pinner, bestEffortRoots []*cid.Cid) <-chan Result {
+	barrier, concurrent := bs.(*BarrierBlockstore)
+
+	// the collection reads the blocks without going through the barrier,
+	// it must not keep what it reads itself
+	var inner bstore.Blockstore = bs
+	if concurrent {
+		inner = barrier.GCBlockstore
+	}
+	bsrv := bserv.New(inner, offline.Exchange(inner))
+	ds := dag.NewDAGService(bsrv)
+	idx := pinnerIndex(pn, dstor)
+
+	if concurrent {
+		barrier.gcLock.Lock()
+	}
+
+	// catch up with the pins changed since the previous collection before
+	// locking, so that only the pins changed in the meantime are walked
+	// while the blockstore is locked
+	if err := idx.Sync(ctx, pn, ds); err != nil {
+		log.Debugf("updating the reachability index before locking: %s", err)
+	}
 
 	elock := log.EventBegin(ctx, "GC.lockWait")
 	unlocker := bs.GCLock()
@@ -46,25 +80,56 @@ func GC(ctx context.Context, bs bstore.GCBlockstore, dstor dstore.Datastore, pn
 	elock = log.EventBegin(ctx, "GC.locked")
 	emark := log.EventBegin(ctx, "GC.mark")
 
-	bsrv := bserv.New(bs, offline.Exchange(bs))
-	ds := dag.NewDAGService(bsrv)
+	if concurrent {
+		barrier.startBarrier()
+	}
 
 	output := make(chan Result, 128)
 
 	go func() {
 		defer close(output)
-		defer unlocker.Unlock()
-		defer elock.Done()
+		if concurrent {
+			defer barrier.gcLock.Unlock()
+			defer barrier.stopBarrier()
+		}
+
+		locked := true
+		unlock := func() {
+			if locked {
+				unlocker.Unlock()
+				elock.Done()
+				locked = false
+			}
+		}
+		defer unlock()
 
-		gcs, err := ColoredSet(ctx, pn, ds, bestEffortRoots, output)
+		err := idx.Sync(ctx, pn, ds)
+		if err != nil {
+			output <- Result{Error: err}
+			if _, ok := err.(*CannotFetchLinksError); ok {
+				output <- Result{Error: ErrCannotFetchAllLinks}
+			}
+			return
+		}
+
+		live, err := liveSet(ctx, pn, ds, bestEffortRoots, output)
+		if err != nil {
+			output <- Result{Error: err}
+			return
+		}
+		reachable, err := idx.ReachableSet()
 		if err != nil {
 			output <- Result{Error: err}
 			return
 		}
 		emark.Append(logging.LoggableMap{
-			"blackSetSize": fmt.Sprintf("%d", gcs.Len()),
+			"blackSetSize": fmt.Sprintf("%d", live.Len()),
 		})
 		emark.Done()
+
+		if concurrent {
+			unlock()
+		}
 		esweep := log.EventBegin(ctx, "GC.sweep")
 
 		keychan, err := bs.AllKeysChan(ctx)
@@ -76,6 +141,39 @@ func GC(ctx context.Context, bs bstore.GCBlockstore, dstor dstore.Datastore, pn
 		errors := false
 		var removed uint64
 
+		// sweep deletes a batch of blocks which were not live when marked
+		sweep := func(keys []*cid.Cid) bool {
+			var rmed []*cid.Cid
+			var errs []error
+			if concurrent {
+				rmed, errs = barrier.deleteUntouched(keys)
+			} else {
+				for _, k := range keys {
+					if err := bs.DeleteBlock(k); err != nil {
+						errs = append(errs, &CannotDeleteBlockError{k, err})
+						continue
+					}
+					rmed = append(rmed, k)
+				}
+			}
+
+			removed += uint64(len(rmed))
+			for _, err := range errs {
+				errors = true
+				// continue as error is non-fatal
+				output <- Result{Error: err}
+			}
+			for _, k := range rmed {
+				select {
+				case output <- Result{KeyRemoved: k}:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		batch := make([]*cid.Cid, 0, sweepBatchSize)
 	loop:
 		for {
 			select {
@@ -83,26 +181,24 @@ func GC(ctx context.Context, bs bstore.GCBlockstore, dstor dstore.Datastore, pn
 				if !ok {
 					break loop
 				}
-				if !gcs.Has(k) {
-					err := bs.DeleteBlock(k)
-					removed++
-					if err != nil {
-						errors = true
-						output <- Result{Error: &CannotDeleteBlockError{k, err}}
-						//log.Errorf("Error removing key from blockstore: %s", err)
-						// continue as error is non-fatal
-						continue loop
-					}
-					select {
-					case output <- Result{KeyRemoved: k}:
-					case <-ctx.Done():
+				if live.Has(k) || reachable.Has(k) {
+					continue
+				}
+
+				batch = append(batch, k)
+				if len(batch) == sweepBatchSize {
+					if !sweep(batch) {
 						break loop
 					}
+					batch = batch[:0]
 				}
 			case <-ctx.Done():
 				break loop
 			}
 		}
+		if len(batch) > 0 && ctx.Err() == nil {
+			sweep(batch)
+		}
 		esweep.Append(logging.LoggableMap{
 			"whiteSetSize": fmt.Sprintf("%d", removed),
 		})
@@ -187,6 +283,40 @@ func ColoredSet(ctx context.Context, pn pin.Pinner, ng dms3ld.NodeGetter, bestEf
 		output <- Result{Error: err}
 	}
 
+	if err := addLive(ctx, pn, ng, bestEffortRoots, gcs, output); err != nil {
+		errors = true
+	}
+
+	if errors {
+		return nil, ErrCannotFetchAllLinks
+	}
+
+	return gcs, nil
+}
+
+// liveSet computes the set of the nodes which are live but not reachable from
+// the recursive pins, which the Index keeps track of.
+func liveSet(ctx context.Context, pn pin.Pinner, ng dms3ld.NodeGetter, bestEffortRoots []*cid.Cid, output chan<- Result) (*cid.Set, error) {
+	live := cid.NewSet()
+	if err := addLive(ctx, pn, ng, bestEffortRoots, live, output); err != nil {
+		return nil, err
+	}
+	return live, nil
+}
+
+// addLive adds to gcs the bestEffortRoots and their descendants, the direct
+// pins, and the nodes used internally by the pinner.
+func addLive(ctx context.Context, pn pin.Pinner, ng dms3ld.NodeGetter, bestEffortRoots []*cid.Cid, gcs *cid.Set, output chan<- Result) error {
+	errors := false
+	getLinks := func(ctx context.Context, cid *cid.Cid) ([]*dms3ld.Link, error) {
+		links, err := dms3ld.GetLinks(ctx, ng, cid)
+		if err != nil {
+			errors = true
+			output <- Result{Error: &CannotFetchLinksError{cid, err}}
+		}
+		return links, nil
+	}
+
 	bestEffortGetLinks := func(ctx context.Context, cid *cid.Cid) ([]*dms3ld.Link, error) {
 		links, err := dms3ld.GetLinks(ctx, ng, cid)
 		if err != nil && err != dms3ld.ErrNotFound {
@@ -195,7 +325,7 @@ func ColoredSet(ctx context.Context, pn pin.Pinner, ng dms3ld.NodeGetter, bestEf
 		}
 		return links, nil
 	}
-	err = Descendants(ctx, bestEffortGetLinks, gcs, bestEffortRoots)
+	err := Descendants(ctx, bestEffortGetLinks, gcs, bestEffortRoots)
 	if err != nil {
 		errors = true
 		output <- Result{Error: err}
@@ -212,10 +342,10 @@ func ColoredSet(ctx context.Context, pn pin.Pinner, ng dms3ld.NodeGetter, bestEf
 	}
 
 	if errors {
-		return nil, ErrCannotFetchAllLinks
+		return ErrCannotFetchAllLinks
 	}
 
-	return gcs, nil
+	return nil
 }
 
 // ErrCannotFetchAllLinks is returned as the last Result in the GC output
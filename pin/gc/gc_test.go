@@ -0,0 +1,427 @@
+package gc
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+
+	bserv "github.com/dms3-fs/go-blockservice"
+	pin "github.com/dms3-fs/go-dms3-fs/pin"
+	dag "github.com/dms3-fs/go-merkledag"
+
+	cid "github.com/dms3-fs/go-cid"
+	dstore "github.com/dms3-fs/go-datastore"
+	dssync "github.com/dms3-fs/go-datastore/sync"
+	bstore "github.com/dms3-fs/go-fs-blockstore"
+	offline "github.com/dms3-fs/go-fs-exchange-offline"
+	dms3ld "github.com/dms3-fs/go-ld-format"
+)
+
+type testRepo struct {
+	ds     dstore.Batching
+	bs     *BarrierBlockstore
+	dserv  dms3ld.DAGService
+	idx    *Index
+	pinner pin.Pinner
+}
+
+func newTestRepo() *testRepo {
+	ds := dssync.MutexWrap(dstore.NewMapDatastore())
+	bs := NewBarrierBlockstore(bstore.NewGCBlockstore(bstore.NewBlockstore(ds), bstore.NewGCLocker()))
+	dserv := dag.NewDAGService(bserv.New(bs, offline.Exchange(bs)))
+	idx := NewIndex(ds)
+	return &testRepo{
+		ds:     ds,
+		bs:     bs,
+		dserv:  dserv,
+		idx:    idx,
+		pinner: NewIndexedPinner(context.Background(), pin.NewPinner(ds, dserv, dserv), idx, dserv),
+	}
+}
+
+func randNode() *dag.ProtoNode {
+	data := make([]byte, 32)
+	rand.Read(data)
+	return dag.NodeWithData(data)
+}
+
+// addTree adds a node linking to the given children, which are added again,
+// and to the given number of new leaves.
+func (r *testRepo) addTree(ctx context.Context, leaves int, children ...*dag.ProtoNode) (*dag.ProtoNode, error) {
+	root := randNode()
+	for i := 0; i < leaves; i++ {
+		children = append(children, randNode())
+	}
+	for _, c := range children {
+		if err := r.dserv.Add(ctx, c); err != nil {
+			return nil, err
+		}
+		if err := root.AddNodeLink("", c); err != nil {
+			return nil, err
+		}
+	}
+	return root, r.dserv.Add(ctx, root)
+}
+
+// collect runs a garbage collection and returns the removed blocks
+func (r *testRepo) collect(ctx context.Context) ([]*cid.Cid, error) {
+	var removed []*cid.Cid
+	var err error
+	for res := range GC(ctx, r.bs, r.ds, r.pinner, nil) {
+		if res.Error != nil && err == nil {
+			err = res.Error
+		}
+		if res.KeyRemoved != nil {
+			removed = append(removed, res.KeyRemoved)
+		}
+	}
+	return removed, err
+}
+
+// complete reports whether no block of the DAG of root is missing
+func complete(ctx context.Context, ng dms3ld.NodeGetter, root *cid.Cid) bool {
+	err := dag.EnumerateChildren(ctx, dag.GetLinksWithDAG(ng), root, cid.NewSet().Visit)
+	return err == nil
+}
+
+// checkComplete fails if a block of the DAG of root is missing
+func (r *testRepo) checkComplete(t *testing.T, ctx context.Context, root *cid.Cid) {
+	if !complete(ctx, r.dserv, root) {
+		t.Errorf("DAG of the pinned %s is incomplete", root)
+	}
+}
+
+func TestIndexSync(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo()
+
+	shared, err := r.addTree(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := r.addTree(ctx, 2, shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := r.addTree(ctx, 2, shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, nd := range []*dag.ProtoNode{a, b} {
+		if err := r.pinner.Pin(ctx, nd, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	idx := r.idx
+	if err := idx.Sync(ctx, r.pinner, r.dserv); err != nil {
+		t.Fatal(err)
+	}
+
+	checkRefs := func(c *cid.Cid, expected int) {
+		t.Helper()
+		n, err := idx.Refs(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != expected {
+			t.Errorf("expected %s to be reachable from %d pins, got %d", c, expected, n)
+		}
+	}
+
+	checkRefs(a.Cid(), 1)
+	checkRefs(shared.Cid(), 2)
+	for _, l := range shared.Links() {
+		checkRefs(l.Cid, 2)
+	}
+
+	if err := r.pinner.Unpin(ctx, a.Cid(), true); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Sync(ctx, r.pinner, r.dserv); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, l := range a.Links() {
+		if l.Cid.Equals(shared.Cid()) {
+			continue
+		}
+		checkRefs(l.Cid, 0)
+	}
+	checkRefs(a.Cid(), 0)
+	checkRefs(shared.Cid(), 1)
+	checkRefs(b.Cid(), 1)
+
+	// an index left incomplete is rebuilt
+	if err := r.ds.Put(indexDirtyKey, []byte{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Sync(ctx, r.pinner, r.dserv); err != nil {
+		t.Fatal(err)
+	}
+	checkRefs(shared.Cid(), 1)
+	checkRefs(b.Cid(), 1)
+	if dirty, _ := r.ds.Has(indexDirtyKey); dirty {
+		t.Error("expected the rebuilt index not to be dirty")
+	}
+}
+
+func TestIndexedPinnerFlush(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo()
+
+	a, err := r.addTree(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkReachable := func(expected bool) {
+		t.Helper()
+		reachable, err := r.idx.ReachableSet()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range append([]*cid.Cid{a.Cid()}, a.Links()[0].Cid, a.Links()[1].Cid) {
+			if reachable.Has(c) != expected {
+				t.Errorf("expected %s to be reachable: %t", c, expected)
+			}
+		}
+	}
+
+	if err := r.pinner.Pin(ctx, a, true); err != nil {
+		t.Fatal(err)
+	}
+	checkReachable(false)
+	if err := r.pinner.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	checkReachable(true)
+
+	if err := r.pinner.Unpin(ctx, a.Cid(), true); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.pinner.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	checkReachable(false)
+}
+
+func TestGC(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo()
+
+	pinned, err := r.addTree(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	direct := randNode()
+	if err := r.pinner.Pin(ctx, pinned, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.pinner.Pin(ctx, direct, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.pinner.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	garbage, err := r.addTree(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := r.collect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 4 {
+		t.Errorf("expected the 4 blocks of the unpinned tree to be removed, got %d", len(removed))
+	}
+	if has, _ := r.bs.Has(garbage.Cid()); has {
+		t.Error("expected the unpinned tree to be removed")
+	}
+	r.checkComplete(t, ctx, pinned.Cid())
+	if has, _ := r.bs.Has(direct.Cid()); !has {
+		t.Error("expected the direct pin to be kept")
+	}
+
+	// unpinned blocks are removed by the next collection
+	if err := r.pinner.Unpin(ctx, pinned.Cid(), true); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.pinner.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.collect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if has, _ := r.bs.Has(pinned.Cid()); has {
+		t.Error("expected the unpinned tree to be removed")
+	}
+	for _, l := range pinned.Links() {
+		if has, _ := r.bs.Has(l.Cid); has {
+			t.Errorf("expected the leaf %s of the unpinned tree to be removed", l.Cid)
+		}
+	}
+}
+
+// TestGCConcurrent runs collections while trees are added and pinned, reusing
+// blocks the collections may delete, pinned and unpinned. No block of a tree
+// pinned successfully may be removed.
+func TestGCConcurrent(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo()
+
+	// unpinned blocks, which the adds reuse
+	var garbage []*dag.ProtoNode
+	for i := 0; i < 200; i++ {
+		nd, err := r.addTree(ctx, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		garbage = append(garbage, nd)
+	}
+
+	// unpinned trees, which the workers pin
+	var unpinned []*dag.ProtoNode
+	for i := 0; i < 100; i++ {
+		nd, err := r.addTree(ctx, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		unpinned = append(unpinned, nd)
+	}
+
+	// lk serializes the changes of the pins with the updates of roots, the
+	// trees the workers pinned
+	var lk sync.Mutex
+	roots := cid.NewSet()
+
+	// pinTree pins a tree, the pin lock must be held
+	pinTree := func(nd *dag.ProtoNode) error {
+		lk.Lock()
+		defer lk.Unlock()
+		if err := r.pinner.Pin(ctx, nd, true); err != nil {
+			return err
+		}
+		roots.Add(nd.Cid())
+		return r.pinner.Flush()
+	}
+
+	// unpinTree unpins a tree, the pin lock must be held
+	unpinTree := func(c *cid.Cid) error {
+		lk.Lock()
+		defer lk.Unlock()
+		if err := r.pinner.Unpin(ctx, c, true); err != nil {
+			return err
+		}
+		roots.Remove(c)
+		return r.pinner.Flush()
+	}
+
+	worker := func(seed int64) error {
+		rnd := rand.New(rand.NewSource(seed))
+		for i := 0; i < 100; i++ {
+			unlocker := r.bs.PinLock()
+			var err error
+			switch rnd.Intn(3) {
+			case 0:
+				// add and pin a tree linking to blocks which may be
+				// collected, like an add
+				var nd *dag.ProtoNode
+				nd, err = r.addTree(ctx, 2, garbage[rnd.Intn(len(garbage))], garbage[rnd.Intn(len(garbage))])
+				if err == nil {
+					err = pinTree(nd)
+				}
+			case 1:
+				// pin a tree which may be collected, which fails if some of
+				// its blocks were removed already
+				nd := unpinned[rnd.Intn(len(unpinned))]
+				if pinErr := pinTree(nd); pinErr != nil && complete(ctx, r.dserv, nd.Cid()) {
+					err = pinErr
+				}
+			case 2:
+				lk.Lock()
+				keys := roots.Keys()
+				lk.Unlock()
+				if len(keys) > 0 {
+					err = unpinTree(keys[rnd.Intn(len(keys))])
+					if err == pin.ErrNotPinned {
+						err = nil
+					}
+				}
+			}
+			unlocker.Unlock()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			errs <- worker(seed)
+		}(int64(i))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	collections := 0
+loop:
+	for {
+		select {
+		case <-done:
+			break loop
+		default:
+		}
+
+		if _, err := r.collect(ctx); err != nil {
+			t.Fatalf("collection failed: %s", err)
+		}
+		collections++
+	}
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Logf("%d collections ran", collections)
+
+	// the trees still pinned are complete, before and after a last collection
+	for _, c := range roots.Keys() {
+		r.checkComplete(t, ctx, c)
+	}
+	if _, err := r.collect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range roots.Keys() {
+		r.checkComplete(t, ctx, c)
+	}
+
+	// and the last collection only left the blocks of the pins
+	colored, err := ColoredSet(ctx, r.pinner, r.dserv, nil, make(chan Result, 128))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := r.bs.AllKeysChan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := range keys {
+		if !colored.Has(k) {
+			t.Errorf("unpinned %s was not collected", k)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package gc
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	pin "github.com/dms3-fs/go-dms3-fs/pin"
+
+	cid "github.com/dms3-fs/go-cid"
+	dstore "github.com/dms3-fs/go-datastore"
+	dsq "github.com/dms3-fs/go-datastore/query"
+	dshelp "github.com/dms3-fs/go-fs-ds-help"
+	dms3ld "github.com/dms3-fs/go-ld-format"
+)
+
+var (
+	indexKey      = dstore.NewKey("/local/gc")
+	indexDirtyKey = indexKey.ChildString("dirty")
+	indexRootsKey = indexKey.ChildString("roots")
+	indexRefsKey  = indexKey.ChildString("refs")
+)
+
+// Index is the reachability set of the recursive pins, persisted in the
+// datastore of the repo. It records the recursively pinned roots it was
+// updated with, and for each block the number of these roots it is reachable
+// from. Updating it only walks the DAGs of the roots pinned or unpinned since
+// the previous update, rather than the DAGs of all the recursive pins. Only
+// one Index should use a datastore at a time, as it keeps the indexed roots
+// in memory.
+type Index struct {
+	ds dstore.Datastore
+
+	// lock serializes the updates of the index, which a collection may
+	// start while another one is sweeping or the pins are flushed
+	lock sync.Mutex
+
+	// indexed holds the roots the index was updated with once they are
+	// loaded from the datastore
+	indexed *cid.Set
+}
+
+// NewIndex returns the reachability index stored in the given datastore.
+func NewIndex(d dstore.Datastore) *Index {
+	return &Index{ds: d}
+}
+
+// Sync updates the index to the recursive pins of the given pinner. It walks
+// the DAGs of the roots pinned or unpinned since the previous update with the
+// given node getter. An index left incomplete by an interrupted update is
+// rebuilt from scratch.
+func (idx *Index) Sync(ctx context.Context, pn pin.Pinner, ng dms3ld.NodeGetter) error {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	dirty, err := idx.ds.Has(indexDirtyKey)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		log.Warning("the reachability index was not completely updated, rebuilding it")
+		if err := idx.reset(); err != nil {
+			return err
+		}
+	}
+
+	pinned := cid.NewSet()
+	for _, c := range pn.RecursiveKeys() {
+		pinned.Add(c)
+	}
+
+	err = idx.update(ctx, pinned, ng)
+	if _, ok := err.(*unpinnedWalkError); ok {
+		// the counts of the blocks below the missing ones cannot be
+		// decremented, rebuild the index rather than leaking them
+		log.Warningf("rebuilding the reachability index: %s", err)
+		if err := idx.reset(); err != nil {
+			return err
+		}
+		err = idx.update(ctx, pinned, ng)
+	}
+	return err
+}
+
+// indexedPinner is a pin.Pinner updating a reachability index with its
+// recursive pins whenever they are flushed.
+type indexedPinner struct {
+	pin.Pinner
+	ctx context.Context
+	idx *Index
+	ng  dms3ld.NodeGetter
+}
+
+// NewIndexedPinner returns a pinner which updates idx with the recursive pins
+// of pn whenever they are flushed, walking their DAGs with the given node
+// getter. GC uses idx rather than an index of its own with this pinner, and
+// only walks the pins changed since they were last flushed.
+//
+// Flush returns once the index is updated: it walks the whole DAG of every
+// recursive pin added or removed since the previous flush, and waits for a
+// collection updating the index. The walks are cancelled with ctx, which
+// leaves the index to be updated by the next flush or collection.
+func NewIndexedPinner(ctx context.Context, pn pin.Pinner, idx *Index, ng dms3ld.NodeGetter) pin.Pinner {
+	return &indexedPinner{Pinner: pn, ctx: ctx, idx: idx, ng: ng}
+}
+
+func (p *indexedPinner) Flush() error {
+	if err := p.Pinner.Flush(); err != nil {
+		return err
+	}
+
+	// the pins are saved, the next flush or collection catches up with an
+	// index which could not be updated
+	if err := p.idx.Sync(p.ctx, p.Pinner, p.ng); err != nil {
+		log.Warningf("updating the reachability index: %s", err)
+	}
+	return nil
+}
+
+// pinnerIndex returns the index updated by pn, or a new index stored in d
+func pinnerIndex(pn pin.Pinner, d dstore.Datastore) *Index {
+	if ip, ok := pn.(*indexedPinner); ok {
+		return ip.idx
+	}
+	return NewIndex(d)
+}
+
+// unpinnedWalkError is returned when the DAG of an unpinned root cannot be
+// walked, when some of its blocks were removed without garbage collection.
+type unpinnedWalkError struct {
+	root *cid.Cid
+	err  error
+}
+
+func (e *unpinnedWalkError) Error() string {
+	return fmt.Sprintf("cannot walk the unpinned %s: %s", e.root, e.err)
+}
+
+// update updates the index from the roots it was updated with to pinned
+func (idx *Index) update(ctx context.Context, pinned *cid.Set, ng dms3ld.NodeGetter) error {
+	if idx.indexed == nil {
+		indexed, err := idx.roots()
+		if err != nil {
+			return err
+		}
+		idx.indexed = indexed
+	}
+	indexed := idx.indexed
+
+	var added, removed []*cid.Cid
+	for _, c := range pinned.Keys() {
+		if !indexed.Has(c) {
+			added = append(added, c)
+		}
+	}
+	for _, c := range indexed.Keys() {
+		if !pinned.Has(c) {
+			removed = append(removed, c)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	// the walks only read the DAGs, the index is marked dirty once they are
+	// done and until the changes are applied
+	deltas := make(map[string]int)
+	for _, c := range added {
+		if err := walkRefs(ctx, ng, c, deltas, 1); err != nil {
+			return err
+		}
+	}
+	for _, c := range removed {
+		if err := walkRefs(ctx, ng, c, deltas, -1); err != nil {
+			return &unpinnedWalkError{c, err}
+		}
+	}
+
+	if err := idx.ds.Put(indexDirtyKey, []byte{}); err != nil {
+		return err
+	}
+	if err := idx.apply(deltas, added, removed); err != nil {
+		// reload the roots which were actually applied
+		idx.indexed = nil
+		return err
+	}
+	for _, c := range added {
+		indexed.Add(c)
+	}
+	for _, c := range removed {
+		indexed.Remove(c)
+	}
+	return idx.ds.Delete(indexDirtyKey)
+}
+
+// ReachableSet returns the blocks reachable from the recursive pins the index
+// was updated with, read in a single query rather than one lookup per block.
+func (idx *Index) ReachableSet() (*cid.Set, error) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+	return idx.keys(indexRefsKey)
+}
+
+// Refs returns the number of recursive pins the given block is reachable
+// from.
+func (idx *Index) Refs(c *cid.Cid) (int, error) {
+	return idx.refs(refsKey(c))
+}
+
+// walkRefs adds delta to the count of each block of the DAG of root.
+func walkRefs(ctx context.Context, ng dms3ld.NodeGetter, root *cid.Cid, deltas map[string]int, delta int) error {
+	getLinks := func(ctx context.Context, c *cid.Cid) ([]*dms3ld.Link, error) {
+		links, err := dms3ld.GetLinks(ctx, ng, c)
+		if err != nil {
+			return nil, &CannotFetchLinksError{c, err}
+		}
+		return links, nil
+	}
+
+	set := cid.NewSet()
+	if err := Descendants(ctx, getLinks, set, []*cid.Cid{root}); err != nil {
+		return err
+	}
+	return set.ForEach(func(c *cid.Cid) error {
+		deltas[c.KeyString()] += delta
+		return nil
+	})
+}
+
+// indexWriter is implemented by both datastores and their batches
+type indexWriter interface {
+	Put(key dstore.Key, value []byte) error
+	Delete(key dstore.Key) error
+}
+
+// apply writes the changes of the counts of the blocks and of the indexed
+// roots, in a single batch if the datastore supports batching.
+func (idx *Index) apply(deltas map[string]int, added, removed []*cid.Cid) error {
+	var w indexWriter = idx.ds
+	var commit func() error
+	if bds, ok := idx.ds.(dstore.Batching); ok {
+		b, err := bds.Batch()
+		if err != nil {
+			return err
+		}
+		w, commit = b, b.Commit
+	}
+
+	for k, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+
+		c, err := cid.Cast([]byte(k))
+		if err != nil {
+			return err
+		}
+		key := refsKey(c)
+
+		n, err := idx.refs(key)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case n+delta > 0:
+			buf := make([]byte, binary.MaxVarintLen64)
+			err = w.Put(key, buf[:binary.PutUvarint(buf, uint64(n+delta))])
+		case n > 0:
+			err = w.Delete(key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, c := range added {
+		if err := w.Put(rootsKey(c), []byte{}); err != nil {
+			return err
+		}
+	}
+	for _, c := range removed {
+		if err := w.Delete(rootsKey(c)); err != nil {
+			return err
+		}
+	}
+
+	if commit != nil {
+		return commit()
+	}
+	return nil
+}
+
+// refs returns the count stored under the given key, 0 if there is none
+func (idx *Index) refs(key dstore.Key) (int, error) {
+	v, err := idx.ds.Get(key)
+	switch err {
+	case nil:
+	case dstore.ErrNotFound:
+		return 0, nil
+	default:
+		return 0, err
+	}
+
+	n, read := binary.Uvarint(v)
+	if read <= 0 {
+		return 0, fmt.Errorf("invalid reference count for %s", key)
+	}
+	return int(n), nil
+}
+
+// roots returns the recursively pinned roots the index was updated with
+func (idx *Index) roots() (*cid.Set, error) {
+	return idx.keys(indexRootsKey)
+}
+
+// keys returns the cids of the keys stored below the given key
+func (idx *Index) keys(prefix dstore.Key) (*cid.Set, error) {
+	res, err := idx.ds.Query(dsq.Query{Prefix: prefix.String(), KeysOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	entries, err := res.Rest()
+	if err != nil {
+		return nil, err
+	}
+
+	set := cid.NewSet()
+	for _, e := range entries {
+		c, err := dshelp.DsKeyToCid(dstore.NewKey(dstore.RawKey(e.Key).BaseNamespace()))
+		if err != nil {
+			return nil, err
+		}
+		set.Add(c)
+	}
+	return set, nil
+}
+
+// reset removes the whole index
+func (idx *Index) reset() error {
+	idx.indexed = nil
+	res, err := idx.ds.Query(dsq.Query{Prefix: indexKey.String(), KeysOnly: true})
+	if err != nil {
+		return err
+	}
+	entries, err := res.Rest()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if err := idx.ds.Delete(dstore.RawKey(e.Key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rootsKey(c *cid.Cid) dstore.Key {
+	return indexRootsKey.Child(dshelp.CidToDsKey(c))
+}
+
+func refsKey(c *cid.Cid) dstore.Key {
+	return indexRefsKey.Child(dshelp.CidToDsKey(c))
+}
@@ -0,0 +1,290 @@
+package pin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dms3-fs/go-dms3-fs/pin/internal/pb"
+	mdag "github.com/dms3-fs/go-merkledag"
+
+	cid "github.com/dms3-fs/go-cid"
+	dms3ld "github.com/dms3-fs/go-ld-format"
+)
+
+// linkInfo is the link from the pin root to the set of the nodes holding
+// the infos of the pins.
+const linkInfo = "info"
+
+// Info describes a direct or recursive pin: the name it was given, arbitrary
+// key/value metadata, when it was created, and the references holding it,
+// each of which may expire.
+type Info struct {
+	Name    string
+	Meta    map[string]string
+	Created time.Time
+
+	// Expires is when the reference holding the pin without an owner
+	// expires, or the zero time if it does not.
+	Expires time.Time
+
+	// Owners is the sorted list of the owners of the pin. A pin with owners
+	// is only removed when its last reference is released, or when it is
+	// unpinned for all of them.
+	Owners []string
+
+	// OwnerExpires is when the reference of each owner expires. The
+	// references of the owners missing from it do not expire.
+	OwnerExpires map[string]time.Time
+
+	// Unowned records that a pin with owners is also held without an owner,
+	// like a pin without owners always is.
+	Unowned bool
+}
+
+// Expiry returns when the last reference holding the pin expires, or the
+// zero time if one of them does not expire.
+func (info Info) Expiry() time.Time {
+	var last time.Time
+	if info.unowned() {
+		if info.Expires.IsZero() {
+			return time.Time{}
+		}
+		last = info.Expires
+	}
+	for _, owner := range info.Owners {
+		expires, ok := info.OwnerExpires[owner]
+		if !ok {
+			return time.Time{}
+		}
+		if expires.After(last) {
+			last = expires
+		}
+	}
+	return last
+}
+
+// Expired reports whether all the references holding the pin expired at now.
+func (info Info) Expired(now time.Time) bool {
+	expiry := info.Expiry()
+	return !expiry.IsZero() && !expiry.After(now)
+}
+
+// expired returns the owners whose references expired at now, with the empty
+// owner standing for the reference without an owner.
+func (info Info) expired(now time.Time) []string {
+	var out []string
+	if info.unowned() && expiredAt(info.Expires, now) {
+		out = append(out, "")
+	}
+	for _, owner := range info.Owners {
+		if expiredAt(info.OwnerExpires[owner], now) {
+			out = append(out, owner)
+		}
+	}
+	return out
+}
+
+// expiredAt reports whether an expiry time is set and not after now
+func expiredAt(expires, now time.Time) bool {
+	return !expires.IsZero() && !expires.After(now)
+}
+
+// laterExpiry returns the expiry of a reference which expired at a and is
+// renewed until b: the later of the two, or the zero time if either does not
+// expire.
+func laterExpiry(a, b time.Time) time.Time {
+	if a.IsZero() || b.IsZero() {
+		return time.Time{}
+	}
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// Matches reports whether the info has the given name, if name is not empty,
+// and all the given metadata.
+func (info Info) Matches(name string, meta map[string]string) bool {
+	if name != "" && info.Name != name {
+		return false
+	}
+	for k, v := range meta {
+		if mv, ok := info.Meta[k]; !ok || mv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// hasOwner reports whether owner is one of the owners of the pin
+func (info Info) hasOwner(owner string) bool {
+	i := sort.SearchStrings(info.Owners, owner)
+	return i < len(info.Owners) && info.Owners[i] == owner
+}
+
+// unowned reports whether the pin is held without an owner
+func (info Info) unowned() bool {
+	return info.Unowned || len(info.Owners) == 0
+}
+
+// copyInfo returns a copy of info which does not share its metadata, owners
+// or expiry times
+func copyInfo(info Info) Info {
+	if info.Meta != nil {
+		meta := make(map[string]string, len(info.Meta))
+		for k, v := range info.Meta {
+			meta[k] = v
+		}
+		info.Meta = meta
+	}
+	if info.Owners != nil {
+		info.Owners = append([]string(nil), info.Owners...)
+	}
+	if info.OwnerExpires != nil {
+		expires := make(map[string]time.Time, len(info.OwnerExpires))
+		for k, v := range info.OwnerExpires {
+			expires[k] = v
+		}
+		info.OwnerExpires = expires
+	}
+	return info
+}
+
+// infoNode encodes the info of the pin of c in the data of a node
+func infoNode(c *cid.Cid, info Info) (*mdag.ProtoNode, error) {
+	m := &pb.Info{
+		Cid:     c.Bytes(),
+		Name:    info.Name,
+		Created: info.Created.UnixNano(),
+	}
+	if !info.Expires.IsZero() {
+		m.Expires = info.Expires.UnixNano()
+	}
+	m.Owners = info.Owners
+	m.Unowned = info.Unowned && len(info.Owners) > 0
+	for _, owner := range info.Owners {
+		if expires, ok := info.OwnerExpires[owner]; ok {
+			m.Expiries = append(m.Expiries, &pb.OwnerExpiry{Owner: owner, Expires: expires.UnixNano()})
+		}
+	}
+
+	keys := make([]string, 0, len(info.Meta))
+	for k := range info.Meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		m.Meta = append(m.Meta, &pb.Meta{Key: k, Value: info.Meta[k]})
+	}
+
+	data, err := m.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return mdag.NodeWithData(data), nil
+}
+
+// decodeInfoNode returns the cid and the info encoded in a node by infoNode
+func decodeInfoNode(nd dms3ld.Node) (*cid.Cid, Info, error) {
+	pbnd, ok := nd.(*mdag.ProtoNode)
+	if !ok {
+		return nil, Info{}, mdag.ErrNotProtobuf
+	}
+
+	var m pb.Info
+	if err := m.Unmarshal(pbnd.Data()); err != nil {
+		return nil, Info{}, err
+	}
+
+	c, err := cid.Cast(m.GetCid())
+	if err != nil {
+		return nil, Info{}, err
+	}
+
+	info := Info{
+		Name:    m.GetName(),
+		Created: time.Unix(0, m.GetCreated()),
+	}
+	if m.GetExpires() != 0 {
+		info.Expires = time.Unix(0, m.GetExpires())
+	}
+	if len(m.GetOwners()) > 0 {
+		info.Owners = m.GetOwners()
+		sort.Strings(info.Owners)
+		info.Unowned = m.GetUnowned()
+	}
+	if len(m.GetExpiries()) > 0 {
+		info.OwnerExpires = make(map[string]time.Time, len(m.GetExpiries()))
+		for _, e := range m.GetExpiries() {
+			info.OwnerExpires[e.GetOwner()] = time.Unix(0, e.GetExpires())
+		}
+	}
+	if len(m.GetMeta()) > 0 {
+		info.Meta = make(map[string]string, len(m.GetMeta()))
+		for _, kv := range m.GetMeta() {
+			info.Meta[kv.GetKey()] = kv.GetValue()
+		}
+	}
+	return c, info, nil
+}
+
+// storeInfos stores the info of each pin in its own node, and the set of
+// these nodes.
+func storeInfos(ctx context.Context, dag dms3ld.DAGService, infos map[string]Info, internalKeys keyObserver) (*mdag.ProtoNode, error) {
+	nodes := make([]*cid.Cid, 0, len(infos))
+	for k, info := range infos {
+		c, err := cid.Cast([]byte(k))
+		if err != nil {
+			return nil, err
+		}
+
+		nd, err := infoNode(c, info)
+		if err != nil {
+			return nil, err
+		}
+		if err := dag.Add(ctx, nd); err != nil {
+			return nil, err
+		}
+
+		internalKeys(nd.Cid())
+		nodes = append(nodes, nd.Cid())
+	}
+
+	return storeSet(ctx, dag, nodes, internalKeys)
+}
+
+// loadInfos loads the infos of the pins stored by storeInfos. Pin roots
+// written before pins had infos have none.
+func loadInfos(ctx context.Context, dag dms3ld.DAGService, root *mdag.ProtoNode, internalKeys keyObserver) (map[string]Info, error) {
+	infos := make(map[string]Info)
+
+	if _, err := root.GetNodeLink(linkInfo); err == mdag.ErrLinkNotFound {
+		return infos, nil
+	}
+
+	keys, err := loadSet(ctx, dag, root, linkInfo, internalKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := 0
+	for opt := range dag.GetMany(ctx, keys) {
+		if opt.Err != nil {
+			return nil, opt.Err
+		}
+		loaded++
+		internalKeys(opt.Node.Cid())
+
+		c, info, err := decodeInfoNode(opt.Node)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pin info %s: %v", opt.Node.Cid(), err)
+		}
+		infos[c.KeyString()] = info
+	}
+	if loaded != len(keys) {
+		return nil, fmt.Errorf("loaded %d of %d pin infos: %v", loaded, len(keys), ctx.Err())
+	}
+	return infos, nil
+}
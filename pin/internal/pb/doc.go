@@ -1,6 +1,6 @@
 package pb
 
-//go:generate protoc --gogo_out=. header.proto
+//go:generate protoc --gogo_out=. header.proto info.proto
 
 // kludge to get vendoring right in protobuf output
 //go:generate sed -i s,github.com/,github.com/dms3-fs/go-dms3-fs/Godeps/_workspace/src/github.com/,g header.pb.go
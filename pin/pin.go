@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -115,9 +116,23 @@ type Pinner interface {
 	Pin(ctx context.Context, node dms3ld.Node, recursive bool) error
 
 	// Unpin the given cid. If recursive is true, removes either a recursive or
-	// a direct pin. If recursive is false, only removes a direct pin.
+	// a direct pin. If recursive is false, only removes a direct pin. The pin
+	// is removed for all its owners.
 	Unpin(ctx context.Context, cid *cid.Cid, recursive bool) error
 
+	// AddOwner adds owner to the owners of the direct or recursive pin of the
+	// given cid, with a reference expiring at expires, or never if it is
+	// zero. The reference of an owner the pin already has is renewed: its
+	// expiry is only pushed back, or removed. It returns ErrNotPinned if the
+	// cid is not pinned directly or recursively.
+	AddOwner(c *cid.Cid, owner string, expires time.Time) error
+
+	// Release removes the reference of owner to the pin of the given cid, or
+	// the reference without an owner if owner is empty, and unpins it like
+	// Unpin once it has no reference left. It returns whether the cid was
+	// unpinned.
+	Release(ctx context.Context, c *cid.Cid, owner string, recursive bool) (bool, error)
+
 	// Update updates a recursive pin from one cid to another
 	// this is more efficient than simply pinning the new one and unpinning the
 	// old one
@@ -149,6 +164,28 @@ type Pinner interface {
 	// InternalPins returns all cids kept pinned for the internal state of the
 	// pinner
 	InternalPins() []*cid.Cid
+
+	// SetInfo attaches info to the direct or recursive pin of the given cid,
+	// replacing the info it had but its owners and their expiry times, which
+	// only AddOwner and Release change. A zero creation time is set to the
+	// current time. It returns ErrNotPinned if the cid is not pinned directly
+	// or recursively.
+	SetInfo(*cid.Cid, Info) error
+
+	// Info returns the info attached to the direct or recursive pin of the
+	// given cid, if any
+	Info(*cid.Cid) (Info, bool)
+
+	// Expired returns the references to direct and recursive pins which
+	// expired at the given time
+	Expired(now time.Time) []Reference
+}
+
+// Reference is a reference holding a pin: the reference of an owner, or the
+// reference without an owner if Owner is empty.
+type Reference struct {
+	Cid   *cid.Cid
+	Owner string
 }
 
 // Pinned represents CID which has been pinned with a pinning strategy.
@@ -191,6 +228,9 @@ type pinner struct {
 	dserv       dms3ld.DAGService
 	internal    dms3ld.DAGService // dagservice used to store internal objects
 	dstore      ds.Datastore
+
+	// infos of the direct and recursive pins, by cid key
+	infos map[string]Info
 }
 
 // NewPinner creates a new pinner using the given datastore as a backend
@@ -206,6 +246,7 @@ func NewPinner(dstore ds.Datastore, serv, internal dms3ld.DAGService) Pinner {
 		dstore:      dstore,
 		internal:    internal,
 		internalPin: cid.NewSet(),
+		infos:       make(map[string]Info),
 	}
 }
 
@@ -257,6 +298,11 @@ var ErrNotPinned = fmt.Errorf("not pinned")
 func (p *pinner) Unpin(ctx context.Context, c *cid.Cid, recursive bool) error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
+	return p.unpin(c, recursive)
+}
+
+// unpin removes the pin of the given key, the lock must be held
+func (p *pinner) unpin(c *cid.Cid, recursive bool) error {
 	reason, pinned, err := p.isPinnedWithType(c, Any)
 	if err != nil {
 		return err
@@ -268,11 +314,13 @@ func (p *pinner) Unpin(ctx context.Context, c *cid.Cid, recursive bool) error {
 	case "recursive":
 		if recursive {
 			p.recursePin.Remove(c)
+			delete(p.infos, c.KeyString())
 			return nil
 		}
 		return fmt.Errorf("%s is pinned recursively", c)
 	case "direct":
 		p.directPin.Remove(c)
+		delete(p.infos, c.KeyString())
 		return nil
 	default:
 		return fmt.Errorf("%s is pinned indirectly under %s", c, reason)
@@ -426,6 +474,9 @@ func (p *pinner) RemovePinWithMode(c *cid.Cid, mode Mode) {
 		// programmer error, panic OK
 		panic("unrecognized pin type")
 	}
+	if !p.recursePin.Has(c) && !p.directPin.Has(c) {
+		delete(p.infos, c.KeyString())
+	}
 }
 
 func cidSetWithValues(cids []*cid.Cid) *cid.Set {
@@ -482,6 +533,13 @@ func LoadPinner(d ds.Datastore, dserv, internal dms3ld.DAGService) (Pinner, erro
 		p.directPin = cidSetWithValues(directKeys)
 	}
 
+	{ // load pin infos
+		p.infos, err = loadInfos(ctx, internal, rootpb, recordInternal)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load pin infos: %v", err)
+		}
+	}
+
 	p.internalPin = internalset
 
 	// assign services
@@ -519,8 +577,16 @@ func (p *pinner) Update(ctx context.Context, from, to *cid.Cid, unpin bool) erro
 	}
 
 	p.recursePin.Add(to)
+
+	// the new pin takes over the info of the old one, unless it has its own
+	info, ok := p.infos[from.KeyString()]
+	if _, has := p.infos[to.KeyString()]; ok && !has {
+		p.infos[to.KeyString()] = copyInfo(info)
+	}
+
 	if unpin {
 		p.recursePin.Remove(from)
+		delete(p.infos, from.KeyString())
 	}
 	return nil
 }
@@ -556,6 +622,16 @@ func (p *pinner) Flush() error {
 		}
 	}
 
+	{
+		n, err := storeInfos(ctx, p.internal, p.infos, recordInternal)
+		if err != nil {
+			return err
+		}
+		if err := root.AddNodeLink(linkInfo, n); err != nil {
+			return err
+		}
+	}
+
 	// add the empty node, its referenced by the pin sets but never created
 	err := p.internal.Add(ctx, new(mdag.ProtoNode))
 	if err != nil {
@@ -587,6 +663,155 @@ func (p *pinner) InternalPins() []*cid.Cid {
 	return out
 }
 
+// SetInfo attaches info to the direct or recursive pin of the given cid
+func (p *pinner) SetInfo(c *cid.Cid, info Info) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.recursePin.Has(c) && !p.directPin.Has(c) {
+		return ErrNotPinned
+	}
+
+	if info.Created.IsZero() {
+		info.Created = time.Now()
+	}
+	old := p.infos[c.KeyString()]
+	info = copyInfo(info)
+	info.Owners = old.Owners
+	info.OwnerExpires = old.OwnerExpires
+	p.infos[c.KeyString()] = info
+	return nil
+}
+
+// AddOwner adds owner to the owners of the direct or recursive pin of the
+// given cid, or renews its reference
+func (p *pinner) AddOwner(c *cid.Cid, owner string, expires time.Time) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.recursePin.Has(c) && !p.directPin.Has(c) {
+		return ErrNotPinned
+	}
+
+	info, ok := p.infos[c.KeyString()]
+	if !ok {
+		info.Created = time.Now()
+	}
+	info = copyInfo(info)
+	if info.hasOwner(owner) {
+		if old, ok := info.OwnerExpires[owner]; ok {
+			expires = laterExpiry(old, expires)
+		} else {
+			expires = time.Time{}
+		}
+	} else {
+		info.Owners = append(info.Owners, owner)
+		sort.Strings(info.Owners)
+	}
+
+	delete(info.OwnerExpires, owner)
+	if !expires.IsZero() {
+		if info.OwnerExpires == nil {
+			info.OwnerExpires = make(map[string]time.Time)
+		}
+		info.OwnerExpires[owner] = expires
+	}
+	p.infos[c.KeyString()] = info
+	return nil
+}
+
+// Release removes the reference of owner to the pin of the given cid, and
+// unpins it once it has no reference left
+func (p *pinner) Release(ctx context.Context, c *cid.Cid, owner string, recursive bool) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.recursePin.Has(c) && !p.directPin.Has(c) {
+		return false, ErrNotPinned
+	}
+	// the mode is checked before any reference is dropped, like unpin does
+	// for the last one
+	if !recursive && p.recursePin.Has(c) {
+		return false, fmt.Errorf("%s is pinned recursively", c)
+	}
+
+	info := copyInfo(p.infos[c.KeyString()])
+	switch {
+	case owner == "" && !info.unowned():
+		return false, fmt.Errorf("%s is not pinned without an owner", c)
+	case owner != "" && !info.hasOwner(owner):
+		return false, fmt.Errorf("%s is not pinned by %s", c, owner)
+	}
+
+	var last bool
+	if owner == "" {
+		last = len(info.Owners) == 0
+		info.Unowned = false
+		info.Expires = time.Time{}
+	} else {
+		owners := make([]string, 0, len(info.Owners)-1)
+		for _, o := range info.Owners {
+			if o != owner {
+				owners = append(owners, o)
+			}
+		}
+		last = len(owners) == 0 && !info.Unowned
+		if len(owners) == 0 {
+			owners = nil
+			info.Unowned = false
+		}
+		info.Owners = owners
+		delete(info.OwnerExpires, owner)
+	}
+
+	if !last {
+		p.infos[c.KeyString()] = info
+		return false, nil
+	}
+
+	if err := p.unpin(c, recursive); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Info returns the info attached to the direct or recursive pin of the given
+// cid
+func (p *pinner) Info(c *cid.Cid) (Info, bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	info, ok := p.infos[c.KeyString()]
+	if !ok {
+		return Info{}, false
+	}
+	return copyInfo(info), true
+}
+
+// Expired returns the references to direct and recursive pins which expired
+// at the given time
+func (p *pinner) Expired(now time.Time) []Reference {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	var out []Reference
+	for k, info := range p.infos {
+		owners := info.expired(now)
+		if len(owners) == 0 {
+			continue
+		}
+		c, err := cid.Cast([]byte(k))
+		if err != nil {
+			log.Errorf("invalid pin info key: %s", err)
+			continue
+		}
+		for _, owner := range owners {
+			out = append(out, Reference{Cid: c, Owner: owner})
+		}
+	}
+	return out
+}
+
 // PinWithMode allows the user to have fine grained control over pin
 // counts
 func (p *pinner) PinWithMode(c *cid.Cid, mode Mode) {
@@ -406,3 +406,373 @@ func TestPinUpdate(t *testing.T) {
 	assertPinned(t, p, c2, "c2 should be pinned still")
 	assertPinned(t, p, c1, "c1 should be pinned now")
 }
+
+func TestPinInfo(t *testing.T) {
+	ctx := context.Background()
+
+	dstore := dssync.MutexWrap(ds.NewMapDatastore())
+	bstore := blockstore.NewBlockstore(dstore)
+	bserv := bs.New(bstore, offline.Exchange(bstore))
+
+	dserv := mdag.NewDAGService(bserv)
+	p := NewPinner(dstore, dserv, dserv)
+	n1, c1 := randNode()
+	n2, c2 := randNode()
+	n3, c3 := randNode()
+
+	if err := p.SetInfo(c1, Info{Name: "a"}); err != ErrNotPinned {
+		t.Fatalf("expected ErrNotPinned setting the info of an unpinned cid, got %v", err)
+	}
+
+	if err := p.Pin(ctx, n1, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Pin(ctx, n2, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Pin(ctx, n3, true); err != nil {
+		t.Fatal(err)
+	}
+
+	meta := map[string]string{"team": "ops", "reason": "cache"}
+	if err := p.SetInfo(c1, Info{Name: "a", Meta: meta}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetInfo(c2, Info{Name: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	meta["team"] = "dev"
+
+	if _, ok := p.Info(c3); ok {
+		t.Fatal("expected no info for c3")
+	}
+
+	if err := p.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	np, err := LoadPinner(dstore, dserv, dserv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, ok := np.Info(c1)
+	if !ok {
+		t.Fatal("expected the info of c1 to be loaded")
+	}
+	if info.Name != "a" || info.Meta["team"] != "ops" || info.Meta["reason"] != "cache" || len(info.Meta) != 2 {
+		t.Fatalf("unexpected info for c1: %v", info)
+	}
+	if info.Created.IsZero() || time.Since(info.Created) > time.Minute {
+		t.Fatalf("unexpected creation time for c1: %s", info.Created)
+	}
+	if !info.Matches("a", map[string]string{"team": "ops"}) || info.Matches("b", nil) || info.Matches("", map[string]string{"team": "dev"}) {
+		t.Fatal("info of c1 does not match as expected")
+	}
+
+	for _, c := range np.InternalPins() {
+		if c.Equals(c1) || c.Equals(c2) {
+			t.Fatal("pinned cids should not be internal pins")
+		}
+	}
+
+	// the info goes with the pin
+	if err := np.Update(ctx, c1, c3, true); err != nil {
+		t.Fatal(err)
+	}
+	if info, ok := np.Info(c3); !ok || info.Name != "a" {
+		t.Fatalf("expected c3 to take over the info of c1, got %v", info)
+	}
+	if _, ok := np.Info(c1); ok {
+		t.Fatal("expected the info of c1 to be removed with its pin")
+	}
+
+	if err := np.Unpin(ctx, c2, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := np.Info(c2); ok {
+		t.Fatal("expected the info of c2 to be removed with its pin")
+	}
+}
+
+func TestPinExpired(t *testing.T) {
+	ctx := context.Background()
+
+	dstore := dssync.MutexWrap(ds.NewMapDatastore())
+	bstore := blockstore.NewBlockstore(dstore)
+	bserv := bs.New(bstore, offline.Exchange(bstore))
+
+	dserv := mdag.NewDAGService(bserv)
+	p := NewPinner(dstore, dserv, dserv)
+	n1, c1 := randNode()
+	n2, c2 := randNode()
+	n3, c3 := randNode()
+
+	for _, n := range []*mdag.ProtoNode{n1, n2, n3} {
+		if err := p.Pin(ctx, n, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	if err := p.SetInfo(c1, Info{Expires: now.Add(-time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetInfo(c2, Info{Name: "b", Expires: now.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	np, err := LoadPinner(dstore, dserv, dserv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, ok := np.Info(c2)
+	if !ok || info.Expires.UnixNano() != now.Add(time.Hour).UnixNano() {
+		t.Fatalf("unexpected expiry time for c2: %v", info)
+	}
+
+	expired := np.Expired(now)
+	if len(expired) != 1 || !expired[0].Cid.Equals(c1) || expired[0].Owner != "" {
+		t.Fatalf("expected only c1 to be expired, got %v", expired)
+	}
+
+	expired = np.Expired(now.Add(2 * time.Hour))
+	if len(expired) != 2 {
+		t.Fatalf("expected c1 and c2 to be expired, got %v", expired)
+	}
+	for _, ref := range expired {
+		if ref.Cid.Equals(c3) {
+			t.Fatal("c3 has no expiry time and must not expire")
+		}
+	}
+
+	if err := np.Unpin(ctx, c1, true); err != nil {
+		t.Fatal(err)
+	}
+	if expired := np.Expired(now); len(expired) != 0 {
+		t.Fatalf("expected no expired pin once c1 is unpinned, got %v", expired)
+	}
+}
+
+func TestPinOwners(t *testing.T) {
+	ctx := context.Background()
+
+	dstore := dssync.MutexWrap(ds.NewMapDatastore())
+	bstore := blockstore.NewBlockstore(dstore)
+	bserv := bs.New(bstore, offline.Exchange(bstore))
+
+	dserv := mdag.NewDAGService(bserv)
+	p := NewPinner(dstore, dserv, dserv)
+	n1, c1 := randNode()
+	n2, c2 := randNode()
+
+	if err := p.AddOwner(c1, "a", time.Time{}); err != ErrNotPinned {
+		t.Fatalf("expected ErrNotPinned adding an owner to an unpinned cid, got %v", err)
+	}
+
+	if err := p.Pin(ctx, n1, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Pin(ctx, n2, false); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, owner := range []string{"b", "a", "b"} {
+		if err := p.AddOwner(c1, owner, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.AddOwner(c2, "a", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// setting the info of a pin keeps its owners
+	if err := p.SetInfo(c1, Info{Name: "shared", Owners: []string{"c"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	np, err := LoadPinner(dstore, dserv, dserv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, _ := np.Info(c1)
+	if info.Name != "shared" || len(info.Owners) != 2 || info.Owners[0] != "a" || info.Owners[1] != "b" {
+		t.Fatalf("unexpected info for c1: %v", info)
+	}
+
+	if _, err := np.Release(ctx, c1, "c", true); err == nil {
+		t.Fatal("expected an error releasing the pin of c1 for a non-owner")
+	}
+	if _, err := np.Release(ctx, c1, "a", false); err == nil {
+		t.Fatal("expected an error releasing the recursive pin of c1 as a direct pin")
+	}
+	if info, _ := np.Info(c1); len(info.Owners) != 2 {
+		t.Fatalf("a failed release must keep the owners of c1: %v", info)
+	}
+
+	unpinned, err := np.Release(ctx, c1, "a", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unpinned {
+		t.Fatal("c1 must stay pinned while b owns it")
+	}
+	assertPinned(t, np, c1, "c1 is still owned by b")
+
+	unpinned, err = np.Release(ctx, c1, "b", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !unpinned {
+		t.Fatal("expected c1 to be unpinned once its last owner released it")
+	}
+	assertUnpinned(t, np, c1, "c1 has no owner left")
+
+	if _, ok := np.Info(c1); ok {
+		t.Fatal("expected the info of c1 to be removed with its pin")
+	}
+
+	// unpinning without an owner removes the pin for all its owners
+	if err := np.Unpin(ctx, c2, false); err != nil {
+		t.Fatal(err)
+	}
+	assertUnpinned(t, np, c2, "c2 was unpinned")
+}
+
+func TestPinUnownedReference(t *testing.T) {
+	ctx := context.Background()
+
+	dstore := dssync.MutexWrap(ds.NewMapDatastore())
+	bstore := blockstore.NewBlockstore(dstore)
+	bserv := bs.New(bstore, offline.Exchange(bstore))
+
+	dserv := mdag.NewDAGService(bserv)
+	p := NewPinner(dstore, dserv, dserv)
+	n1, c1 := randNode()
+	n2, c2 := randNode()
+
+	for _, n := range []*mdag.ProtoNode{n1, n2} {
+		if err := p.Pin(ctx, n, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// c1 was pinned without an owner before a gets its own pin
+	if err := p.SetInfo(c1, Info{Unowned: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddOwner(c1, "a", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddOwner(c2, "a", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	np, err := LoadPinner(dstore, dserv, dserv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := np.Release(ctx, c2, "", true); err == nil {
+		t.Fatal("expected an error releasing c2 without an owner, it is only pinned by a")
+	}
+
+	unpinned, err := np.Release(ctx, c1, "a", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unpinned {
+		t.Fatal("c1 must stay pinned while it is pinned without an owner")
+	}
+	assertPinned(t, np, c1, "c1 is still pinned without an owner")
+
+	unpinned, err = np.Release(ctx, c1, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !unpinned {
+		t.Fatal("expected c1 to be unpinned once its last reference was released")
+	}
+	assertUnpinned(t, np, c1, "c1 has no reference left")
+}
+
+func TestPinOwnerExpired(t *testing.T) {
+	ctx := context.Background()
+
+	dstore := dssync.MutexWrap(ds.NewMapDatastore())
+	bstore := blockstore.NewBlockstore(dstore)
+	bserv := bs.New(bstore, offline.Exchange(bstore))
+
+	dserv := mdag.NewDAGService(bserv)
+	p := NewPinner(dstore, dserv, dserv)
+	n1, c1 := randNode()
+
+	if err := p.Pin(ctx, n1, true); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	for _, owner := range []struct {
+		name    string
+		expires time.Time
+	}{
+		{"a", time.Time{}},
+		{"a", now.Add(time.Hour)},
+		{"b", now.Add(-time.Second)},
+		{"b", now.Add(-time.Hour)},
+	} {
+		if err := p.AddOwner(c1, owner.name, owner.expires); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := p.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	np, err := LoadPinner(dstore, dserv, dserv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, _ := np.Info(c1)
+	if _, ok := info.OwnerExpires["a"]; ok {
+		t.Fatalf("the pin of a does not expire, got %v", info.OwnerExpires)
+	}
+	if info.OwnerExpires["b"].UnixNano() != now.Add(-time.Second).UnixNano() {
+		t.Fatalf("expected the later expiry time for b, got %v", info.OwnerExpires)
+	}
+	if !info.Expiry().IsZero() {
+		t.Fatalf("c1 does not expire for a, got %v", info.Expiry())
+	}
+
+	expired := np.Expired(now)
+	if len(expired) != 1 || !expired[0].Cid.Equals(c1) || expired[0].Owner != "b" {
+		t.Fatalf("expected only the pin of b to be expired, got %v", expired)
+	}
+
+	unpinned, err := np.Release(ctx, c1, "b", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unpinned {
+		t.Fatal("c1 must stay pinned while a owns it")
+	}
+	if expired := np.Expired(now); len(expired) != 0 {
+		t.Fatalf("expected no expired pin once b released c1, got %v", expired)
+	}
+}